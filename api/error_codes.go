@@ -0,0 +1,63 @@
+package api
+
+import (
+	"errors"
+)
+
+// ErrorCode is the machine-readable code of an error event, which tells the category of the failure of the agent.
+type ErrorCode string
+
+const (
+	PIDNotFound              ErrorCode = "pid-not-found"              // PIDNotFound indicates the target process was not found or ended.
+	PermissionDenied         ErrorCode = "permission-denied"          // PermissionDenied indicates the agent is not allowed to profile the target process, e.g. ptrace is restricted.
+	ToolCrashed              ErrorCode = "tool-crashed"               // ToolCrashed indicates the profiling tool failed.
+	NoSamples                ErrorCode = "no-samples"                 // NoSamples indicates the profiling did not get any sample.
+	OutputTooLarge           ErrorCode = "output-too-large"           // OutputTooLarge indicates the result does not fit in the storage of the agent.
+	UnsupportedRuntimeLayout ErrorCode = "unsupported-runtime-layout" // UnsupportedRuntimeLayout indicates the container of the target process cannot be found in the container runtime.
+)
+
+var (
+	// errorHints contains the remediation hint of each error code.
+	errorHints = map[ErrorCode]string{
+		PIDNotFound:              "Check that the target process is running, or give it with --pid or --pgrep",
+		PermissionDenied:         "Run the agent with --privileged or add the needed --capabilities (e.g. SYS_PTRACE, SYS_ADMIN), and check the kernel.yama.ptrace_scope and kernel.perf_event_paranoid settings of the node",
+		ToolCrashed:              "Run again with --log-level=debug and --print-agent-logs to see the output of the profiling tool",
+		NoSamples:                "The target process was likely idle, profile it under load or for a longer time with -t",
+		OutputTooLarge:           "Split the result with --output-split-size, raise the ephemeral storage of the agent, or persist the result with --output-pvc or --upload-to",
+		UnsupportedRuntimeLayout: "Check that --runtime and --runtime-path match the container runtime of the node",
+	}
+)
+
+// Hint returns the remediation hint of the error code, or an empty string if unknown.
+func (c ErrorCode) Hint() string {
+	return errorHints[c]
+}
+
+// AgentError is an error of the agent categorized by its code, along with the PID and the tool it relates to, if any.
+type AgentError struct {
+	Code ErrorCode
+	PID  string
+	Tool ProfilingTool
+	Err  error
+}
+
+func (e *AgentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *AgentError) Unwrap() error {
+	return e.Err
+}
+
+// NewErrorData returns the error event of the given error, which is categorized if it wraps an AgentError.
+func NewErrorData(err error) *ErrorData {
+	data := &ErrorData{Reason: err.Error()}
+	var agentErr *AgentError
+	if errors.As(err, &agentErr) {
+		data.Code = agentErr.Code
+		data.PID = agentErr.PID
+		data.Tool = agentErr.Tool
+		data.Hint = agentErr.Code.Hint()
+	}
+	return data
+}
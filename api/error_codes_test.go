@@ -0,0 +1,38 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewErrorData(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want *ErrorData
+	}{
+		{
+			name: "should give only the reason of an uncategorized error",
+			err:  errors.New("boom"),
+			want: &ErrorData{Reason: "boom"},
+		},
+		{
+			name: "should give the details of a wrapped agent error",
+			err:  fmt.Errorf("profiling failed: %w", &AgentError{Code: NoSamples, PID: "1234", Tool: Perf, Err: errors.New("no stacks found")}),
+			want: &ErrorData{Reason: "profiling failed: no stacks found", Code: NoSamples, PID: "1234", Tool: Perf, Hint: NoSamples.Hint()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewErrorData(tt.err))
+		})
+	}
+}
+
+func TestErrorCode_Hint(t *testing.T) {
+	assert.NotEmpty(t, PermissionDenied.Hint())
+	assert.Empty(t, ErrorCode("other").Hint())
+}
@@ -16,6 +16,8 @@ limitations under the License.
 package api
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	jsoniter "github.com/json-iterator/go"
@@ -33,21 +35,78 @@ const (
 	Notice   EventType = "notice"   // Notice indicates an event type representing a notice or a message.
 	Log      EventType = "log"      // Log indicates an event type representing a log message.
 	Error    EventType = "error"    // Error indicates an event type representing an error.
+	Hello    EventType = "hello"    // Hello indicates the first event emitted by the agent, introducing itself to the CLI.
 
 	Started   ProgressStage = "started"   // Started indicates the start of a profiling job.
 	Ended     ProgressStage = "ended"     // Ended indicates the end of a profiling job.
 	Profiling ProgressStage = "profiling" // Profiling indicates the profiling is in progress (heartbeat).
+
+	PIDRunning    PIDState = "running"    // PIDRunning indicates the PID is being profiled.
+	PIDPublishing PIDState = "publishing" // PIDPublishing indicates the result of the PID is being published.
+	PIDDone       PIDState = "done"       // PIDDone indicates the PID was profiled.
+	PIDFailed     PIDState = "failed"     // PIDFailed indicates the profiling of the PID failed.
 )
 
+// PIDState represents the state of a profiled PID within the current iteration.
+type PIDState string
+
+// ProtocolVersion is the version of the events exchanged between the agent and the CLI.
+// It must be increased whenever a change breaks the compatibility of the events.
+const ProtocolVersion = 1
+
+// AcknowledgementSuffix is the suffix of the marker file written by the CLI next to each result file it retrieved
+// from the agent, so that the agent can end without waiting for its whole grace period.
+const AcknowledgementSuffix = ".ack"
+
+// AgentPIDFile is the file where the agent records its PID, so that the CLI signals the agent alone: sharing the PID
+// namespace of the node, signaling the processes named agent would signal other agents or daemons as well.
+const AgentPIDFile = "/tmp/kubectl-prof-agent.pid"
+
+// ErrUnknownEvent is returned when parsing an event whose type is unknown, e.g. one emitted by a newer agent.
+var ErrUnknownEvent = errors.New("unknown event type")
+
 // Event represents an event emitted by the profiler.
+// Events of agents prior to the versioned protocol have no version.
 type Event struct {
-	Type EventType            `json:"type"`
-	Data *jsoniter.RawMessage `json:"data"`
+	Type    EventType            `json:"type"`
+	Version int                  `json:"version,omitempty"`
+	Data    *jsoniter.RawMessage `json:"data"`
+}
+
+// HelloData represents the event introducing the agent, emitted before any other event.
+type HelloData struct {
+	Time            time.Time  `json:"time"`
+	AgentVersion    string     `json:"agent-version"`
+	ProtocolVersion int        `json:"protocol-version"`
+	Tools           []ToolInfo `json:"tools,omitempty"`
+	Kernel          string     `json:"kernel,omitempty"`
+	Arch            string     `json:"arch,omitempty"`
+}
+
+// ToolInfo represents a profiling tool available in the agent, with its version if known.
+type ToolInfo struct {
+	Tool    ProfilingTool `json:"tool"`
+	Version string        `json:"version,omitempty"`
+}
+
+// CheckProtocolVersion checks whether the events of an agent speaking the given protocol version can be understood.
+// Agents speaking a newer protocol are refused, since their events may have changed in incompatible ways.
+func CheckProtocolVersion(version int) error {
+	if version > ProtocolVersion {
+		return fmt.Errorf("the agent speaks the protocol version %d but the CLI only understands up to %d, upgrade the CLI",
+			version, ProtocolVersion)
+	}
+	return nil
 }
 
 // ErrorData represents an error event.
+// The code, the PID, the tool and the hint are given only if the error was categorized by the agent.
 type ErrorData struct {
-	Reason string `json:"reason"`
+	Reason string        `json:"reason"`
+	Code   ErrorCode     `json:"code,omitempty"`
+	PID    string        `json:"pid,omitempty"`
+	Tool   ProfilingTool `json:"tool,omitempty"`
+	Hint   string        `json:"hint,omitempty"`
 }
 
 // ResultData represents a profiling result event.
@@ -55,6 +114,8 @@ type ResultData struct {
 	Time            time.Time   `json:"time"`
 	ResultType      OutputType  `json:"result-type"`
 	File            string      `json:"file,omitempty"`
+	URL             string      `json:"url,omitempty"`
+	Object          string      `json:"object,omitempty"`
 	FileSizeInBytes int64       `json:"file-size-in-bytes,omitempty"`
 	Checksum        string      `json:"checksum,omitempty"`
 	CompressorType  string      `json:"compressor-type,omitempty"`
@@ -64,14 +125,37 @@ type ResultData struct {
 // ChunkData represents a profiling result chunk.
 type ChunkData struct {
 	File            string `json:"file"`
+	URL             string `json:"url,omitempty"`
+	Object          string `json:"object,omitempty"`
 	FileSizeInBytes int64  `json:"file-size-in-bytes"`
 	Checksum        string `json:"checksum"`
 }
 
 // ProgressData represents a profiling progress event.
+// The iterations, the elapsed and remaining time and the PIDs are given only while profiling.
 type ProgressData struct {
-	Time  time.Time     `json:"time"`
-	Stage ProgressStage `json:"stage"`
+	Time       time.Time     `json:"time"`
+	Stage      ProgressStage `json:"stage"`
+	Iteration  int           `json:"iteration,omitempty"`
+	Iterations int           `json:"iterations,omitempty"`
+	Elapsed    time.Duration `json:"elapsed,omitempty"`
+	Remaining  time.Duration `json:"remaining,omitempty"`
+	PIDs       []PIDStatus   `json:"pids,omitempty"`
+}
+
+// PIDStatus represents the state of a profiled PID.
+type PIDStatus struct {
+	PID   string   `json:"pid"`
+	State PIDState `json:"state"`
+}
+
+// Percent returns the percentage of the profiling already elapsed.
+func (p *ProgressData) Percent() int {
+	total := p.Elapsed + p.Remaining
+	if total <= 0 {
+		return 0
+	}
+	return int(p.Elapsed * 100 / total)
 }
 
 // NoticeData represents a profiling notice event.
@@ -88,6 +172,7 @@ type LogData struct {
 }
 
 // ParseEvent parses the given event string into its corresponding data structure.
+// An event of unknown type results in an error wrapping ErrUnknownEvent.
 func ParseEvent(eventString string) (any, error) {
 	event := &Event{}
 	err := jsoniter.Unmarshal([]byte(eventString), event)
@@ -107,8 +192,10 @@ func ParseEvent(eventString string) (any, error) {
 		eventData = &NoticeData{}
 	case Log:
 		eventData = &LogData{}
+	case Hello:
+		eventData = &HelloData{}
 	default:
-		return nil, nil
+		return nil, fmt.Errorf("%w %s", ErrUnknownEvent, event.Type)
 	}
 	err = jsoniter.Unmarshal(*event.Data, eventData)
 	return eventData, err
@@ -60,13 +60,34 @@ func TestParseEvent(t *testing.T) {
 		assert.Nil(t, event)
 	})
 
+	t.Run("Parse Hello Event", func(t *testing.T) {
+		eventStr := `{"type":"hello","version":1,"data":{"agent-version":"v2.2.0","protocol-version":1,"tools":[{"tool":"perf","version":"6.1"}],"kernel":"6.1.0","arch":"amd64"}}`
+		event, err := ParseEvent(eventStr)
+		assert.NoError(t, err)
+		assert.Equal(t, &HelloData{
+			AgentVersion:    "v2.2.0",
+			ProtocolVersion: 1,
+			Tools:           []ToolInfo{{Tool: Perf, Version: "6.1"}},
+			Kernel:          "6.1.0",
+			Arch:            "amd64",
+		}, event)
+	})
+
 	t.Run("Parse Unknown Event Type", func(t *testing.T) {
 		eventStr := `{"type":"unknown","data":{"foo":"bar"}}`
 		event, err := ParseEvent(eventStr)
-		assert.NoError(t, err)
+		assert.ErrorIs(t, err, ErrUnknownEvent)
+		assert.EqualError(t, err, "unknown event type unknown")
 		assert.Nil(t, event)
 	})
 
+	t.Run("Parse Legacy Event Without Version", func(t *testing.T) {
+		eventStr := `{"type":"progress","data":{"stage":"ended"}}`
+		event, err := ParseEvent(eventStr)
+		assert.NoError(t, err)
+		assert.Equal(t, &ProgressData{Stage: Ended}, event)
+	})
+
 	t.Run("Concurrency race condition check", func(t *testing.T) {
 		const goroutines = 20
 		const iterations = 100
@@ -107,3 +128,16 @@ func TestParseEvent(t *testing.T) {
 		wg.Wait()
 	})
 }
+
+func TestCheckProtocolVersion(t *testing.T) {
+	assert.NoError(t, CheckProtocolVersion(ProtocolVersion))
+	assert.NoError(t, CheckProtocolVersion(0))
+	assert.EqualError(t, CheckProtocolVersion(ProtocolVersion+1),
+		fmt.Sprintf("the agent speaks the protocol version %d but the CLI only understands up to %d, upgrade the CLI", ProtocolVersion+1, ProtocolVersion))
+}
+
+func TestProgressData_Percent(t *testing.T) {
+	assert.Equal(t, 25, (&ProgressData{Elapsed: time.Minute, Remaining: 3 * time.Minute}).Percent())
+	assert.Equal(t, 100, (&ProgressData{Elapsed: time.Minute}).Percent())
+	assert.Equal(t, 0, (&ProgressData{}).Percent())
+}
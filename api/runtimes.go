@@ -11,6 +11,10 @@ const (
 	Crio       ContainerRuntime = "crio"       // Crio represents the CRI-O container runtime.
 	Containerd ContainerRuntime = "containerd" // Containerd represents the containerd container runtime.
 
+	// SharedProcessNamespace represents the target container seen from an ephemeral container sharing its process namespace.
+	// It is not selectable by users: it is set when the agent is launched as an ephemeral container.
+	SharedProcessNamespace ContainerRuntime = "shared-process-namespace"
+
 	FakeContainer                                      ContainerRuntime = "fake"                                      // FakeContainer represents a fake container runtime for testing purposes.
 	FakeContainerWithRootFileSystemLocationResultError ContainerRuntime = "fakeWithRootFileSystemLocationResultError" // FakeContainerWithRootFileSystemLocationResultError represents a fake container that simulates root filesystem location errors.
 	FakeContainerWithPIDResultError                    ContainerRuntime = "fakeWithPIDResultError"                    // FakeContainerWithPIDResultError represents a fake container that simulates PID retrieval errors.
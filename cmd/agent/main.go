@@ -13,15 +13,23 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/josepdcs/kubectl-prof/api"
 	"github.com/josepdcs/kubectl-prof/internal/agent/action"
 	"github.com/josepdcs/kubectl-prof/internal/agent/job"
 	"github.com/josepdcs/kubectl-prof/internal/agent/profiler"
+	"github.com/josepdcs/kubectl-prof/internal/agent/profiler/common"
+	"github.com/josepdcs/kubectl-prof/internal/agent/util/fileserver"
+	"github.com/josepdcs/kubectl-prof/internal/agent/util/publish"
 	"github.com/josepdcs/kubectl-prof/pkg/util/log"
 	"github.com/urfave/cli/v2"
 )
@@ -30,39 +38,87 @@ import (
 // and also used in case of error for remaining before definitely ending the agent
 var gracePeriod = 5 * time.Minute
 
-// p profiler to be run
-var p profiler.Profiler
+// terminationTimeout is the maximum time for the running profiling to end once the agent is terminated,
+// before cleaning up anyway
+const terminationTimeout = 5 * time.Second
 
-// profilingJob the running profiling job
-var profilingJob *job.ProfilingJob
+// errGracePeriodExpired is the cause of the ending of the agent once its grace period expires
+var errGracePeriodExpired = errors.New("grace period expired")
 
-// global err to be return
-var err error
+// running holds the profiler and the job being run, shared by the app and the handling of the signals
+type running struct {
+	mu            sync.Mutex
+	p             profiler.Profiler
+	job           *job.ProfilingJob
+	stopRequested bool
+}
 
-// done global channel which will be used as a flag for marking the profiling as finished
-var done chan bool
+// current is the running profiling
+var current running
 
-func main() {
-	done = make(chan bool, 1)
+// set sets the running profiling and returns whether it was asked to stop early before being set
+func (r *running) set(p profiler.Profiler, job *job.ProfilingJob) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.p, r.job = p, job
+	return r.stopRequested
+}
 
-	// handle for TERM signal
-	handleSignals()
+// get returns the running profiling, nil if not set yet
+func (r *running) get() (profiler.Profiler, *job.ProfilingJob) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.p, r.job
+}
 
-	// handle for done channel in order to finish app
-	handleForDone()
+// requestStop records the request for stopping early and returns the running profiling, nil if not set yet
+func (r *running) requestStop() (profiler.Profiler, *job.ProfilingJob) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stopRequested = true
+	return r.p, r.job
+}
+
+func main() {
+	// the root context of the agent is cancelled on SIGTERM, which ends the running profiling
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+	defer stop()
+
+	recordPID()
+	handleStopEarly(ctx)
+	handleAcknowledgements(ctx)
 
 	// run main app, any error is logged
-	err = runApp()
-	if err != nil {
+	run(ctx)
+	publish.EndPublishing()
+
+	waitForEnding(ctx)
+	cleanUp()
+	log.DebugLogLn("Profiling finished properly. Bye!")
+}
+
+// run runs the agent up to the profiling ends. Once the given context is cancelled, the profiling is not waited
+// for longer than terminationTimeout.
+func run(ctx context.Context) {
+	ended := make(chan error, 1)
+	go func() { ended <- runApp(ctx) }()
+
+	var err error
+	select {
+	case err = <-ended:
+	case <-ctx.Done():
+		select {
+		case err = <-ended:
+		case <-time.After(terminationTimeout):
+		}
+	}
+	if err != nil && ctx.Err() == nil {
 		log.ErrorLn(err)
 	}
-
-	// handle for app ending
-	handleForEnding()
 }
 
 // runApp runs the agent
-func runApp() error {
+func runApp(ctx context.Context) error {
 	app := &cli.App{
 		Name:        "agent",
 		UsageText:   "agent [global options]",
@@ -91,8 +147,8 @@ func runApp() error {
 			},
 			&cli.StringFlag{
 				Name:     action.TargetContainerID,
-				Usage:    "target container ID",
-				Required: true,
+				Usage:    "target container ID (not required for node-wide profiling)",
+				Required: false,
 			},
 			&cli.StringFlag{
 				Name:     action.Duration,
@@ -184,24 +240,71 @@ func runApp() error {
 				Usage:    "target pod pprof port (default: 6060)",
 				Required: false,
 			},
+			&cli.BoolFlag{
+				Name:     action.TargetNodeWide,
+				Usage:    "profile every container of the node instead of a single target container",
+				Required: false,
+			},
+			&cli.StringSliceFlag{
+				Name:     action.TargetNodeContainer,
+				Usage:    "container of the node to be profiled, given as <container-id>=<namespace>/<pod>/<container>",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     action.UploadTo,
+				Usage:    "s3://bucket/prefix location where the result files are uploaded; the object storage is configured by the AWS_* environment variables",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     action.OutputDir,
+				Usage:    "directory, usually a mounted PersistentVolumeClaim, where the result files are stored along with a manifest",
+				Required: false,
+			},
+			&cli.IntFlag{
+				Name:     action.FileServerPort,
+				Usage:    "port of the loopback HTTP server serving the result files (0 disables it)",
+				Required: false,
+			},
 		},
 		Action: func(c *cli.Context) error {
+			// the agent introduces itself before any other event, so that the CLI can check it understands its events
+			_ = log.EventLn(api.Hello, action.Hello())
+
 			period, errParse := time.ParseDuration(c.String(action.GracePeriodForEnding))
 			if errParse == nil {
 				gracePeriod = period
 			}
 
-			var err error
-			p, profilingJob, err = action.NewProfile(toArgs(c))
+			// the result files are still served by exec if the file server cannot be started
+			if port := c.Int(action.FileServerPort); port > 0 {
+				if err := fileserver.Start(port, common.TmpDir(), publish.CheckAcknowledgements); err != nil {
+					log.WarningLogLn(err.Error())
+				}
+			}
+
+			if location := c.String(action.UploadTo); location != "" {
+				uploader, err := publish.NewS3Uploader(location, c.String(action.JobId))
+				if err != nil {
+					return err
+				}
+				publish.SetUploader(uploader)
+			}
+
+			publish.SetOutputDir(c.String(action.OutputDir))
+
+			p, profilingJob, err := action.NewProfile(toArgs(c))
 			if err != nil {
 				return err
 			}
+			if current.set(p, profilingJob) {
+				stopEarly(c.Context, p, profilingJob)
+			}
 
-			return action.Run(p, profilingJob)
+			return action.Run(c.Context, p, profilingJob)
 		},
 	}
 
-	return app.Run(os.Args)
+	return app.RunContext(ctx, os.Args)
 }
 
 func toArgs(c *cli.Context) map[string]interface{} {
@@ -230,66 +333,91 @@ func toArgs(c *cli.Context) map[string]interface{} {
 		action.HeartbeatInterval:          c.String(action.HeartbeatInterval),
 		action.PprofHost:                  c.String(action.PprofHost),
 		action.PprofPort:                  c.String(action.PprofPort),
+		action.TargetNodeWide:             c.Bool(action.TargetNodeWide),
+		action.TargetNodeContainer:        c.StringSlice(action.TargetNodeContainer),
 	}
 }
 
-// handleSignals handles SIGTERM for ending the app
-func handleSignals() {
-	sigs := make(chan os.Signal, 1)
-	signal.Notify(sigs, syscall.SIGTERM)
+// handleStopEarly handles SIGUSR1 for stopping the profiling early while keeping its partial results
+func handleStopEarly(ctx context.Context) {
+	stops := make(chan os.Signal, 1)
+	signal.Notify(stops, syscall.SIGUSR1)
 
 	go func() {
-		s := <-sigs
-		log.DebugLogLn(fmt.Sprintf("Received signal: %s", s))
-		if p != nil {
-			err := p.CleanUp(profilingJob)
-			if err != nil {
-				log.ErrorLogLn(err.Error())
+		defer signal.Stop(stops)
+		for {
+			select {
+			case s := <-stops:
+				log.DebugLogLn(fmt.Sprintf("Received signal: %s", s))
+				// a request received before the profiling is set is applied once set
+				if p, profilingJob := current.requestStop(); p != nil {
+					stopEarly(ctx, p, profilingJob)
+				}
+			case <-ctx.Done():
+				return
 			}
 		}
-
-		done <- true
 	}()
 }
 
-func handleForDone() {
+// stopEarly stops the given profiling early while keeping its partial results
+func stopEarly(ctx context.Context, p profiler.Profiler, profilingJob *job.ProfilingJob) {
+	log.WarningLogLn("Stopping the profiling early, the partial results are kept")
+	if err := action.Stop(ctx, p, profilingJob); err != nil {
+		log.WarningLogLn(fmt.Sprintf("the current capture could not be finished early: %v", err))
+	}
+}
+
+// recordPID records the PID of the agent, which the CLI signals for stopping the profiling early and acknowledging
+// the retrieved result files
+func recordPID() {
+	if err := os.WriteFile(api.AgentPIDFile, []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+		log.WarningLogLn(fmt.Sprintf("could not record the PID of the agent: %v", err))
+	}
+}
+
+// handleAcknowledgements handles SIGUSR2, sent by the CLI once it wrote the acknowledgement marker of a retrieved result
+// file by exec, when the file server of the agent cannot be reached
+func handleAcknowledgements(ctx context.Context) {
+	acks := make(chan os.Signal, 1)
+	signal.Notify(acks, syscall.SIGUSR2)
+
 	go func() {
+		defer signal.Stop(acks)
 		for {
 			select {
-			case <-done:
-				log.DebugLogLn("Profiling finished properly. Bye!")
-				os.Exit(0)
-			default:
-				// nothing to do
+			case <-acks:
+				publish.CheckAcknowledgements()
+			case <-ctx.Done():
+				return
 			}
 		}
 	}()
 }
 
-// handleForEnding handles the end of the app.
-// A grace of period is defined so that the client profiler have time to retrieve the result of the profiling.
-// Passed this time, the agent will be auto-deleted.
-func handleForEnding() {
-	timer := time.NewTimer(gracePeriod)
-	fired := make(chan bool, 1)
-	go func() {
-		<-timer.C
-		fired <- true
-	}()
+// waitForEnding waits for the ending of the agent, which happens on SIGTERM, once every result file was acknowledged
+// or, at the latest, once the grace period expires. The grace period gives the CLI time to retrieve the results.
+func waitForEnding(ctx context.Context) {
+	ctx, cancel := context.WithTimeoutCause(ctx, gracePeriod, errGracePeriodExpired)
+	defer cancel()
 
-	for {
-		select {
-		case <-fired:
+	select {
+	case <-publish.Retrieved():
+		log.DebugLogLn("Every result file was retrieved")
+	case <-ctx.Done():
+		if errors.Is(context.Cause(ctx), errGracePeriodExpired) {
 			log.WarningLogLn(fmt.Sprintf("Maximum allowed time %s surpassed. Cleaning up and auto-deleting the agent...", gracePeriod.String()))
-			if p != nil {
-				err := p.CleanUp(profilingJob)
-				if err != nil {
-					log.ErrorLogLn(err.Error())
-				}
-			}
-			return
-		default:
-			// nothing to do
 		}
 	}
 }
+
+// cleanUp cleans the environment after the profiling
+func cleanUp() {
+	p, profilingJob := current.get()
+	if p == nil {
+		return
+	}
+	if err := p.CleanUp(context.Background(), profilingJob); err != nil {
+		log.ErrorLogLn(err.Error())
+	}
+}
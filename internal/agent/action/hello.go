@@ -0,0 +1,36 @@
+package action
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/josepdcs/kubectl-prof/internal/agent/profiler"
+	"github.com/josepdcs/kubectl-prof/internal/agent/version"
+)
+
+// kernelReleaseFile holds the release of the running kernel
+var kernelReleaseFile = "/proc/sys/kernel/osrelease"
+
+// Hello returns the event introducing the agent to the CLI, which must be emitted before any other event
+func Hello() *api.HelloData {
+	return &api.HelloData{
+		Time:            time.Now(),
+		AgentVersion:    version.GetCurrent(),
+		ProtocolVersion: api.ProtocolVersion,
+		Tools:           profiler.AvailableTools(),
+		Kernel:          kernelRelease(),
+		Arch:            runtime.GOARCH,
+	}
+}
+
+// kernelRelease returns the release of the running kernel, or empty string if it cannot be read
+func kernelRelease() string {
+	release, err := os.ReadFile(kernelReleaseFile)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(release))
+}
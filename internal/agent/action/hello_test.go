@@ -0,0 +1,33 @@
+package action
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHello(t *testing.T) {
+	release := filepath.Join(t.TempDir(), "osrelease")
+	require.NoError(t, os.WriteFile(release, []byte("6.1.0-18-amd64\n"), 0644))
+	kernelReleaseFile = release
+	defer func() { kernelReleaseFile = "/proc/sys/kernel/osrelease" }()
+
+	hello := Hello()
+
+	assert.Equal(t, api.ProtocolVersion, hello.ProtocolVersion)
+	assert.Equal(t, "6.1.0-18-amd64", hello.Kernel)
+	assert.Equal(t, runtime.GOARCH, hello.Arch)
+	assert.Contains(t, hello.Tools, api.ToolInfo{Tool: api.GoPprof})
+}
+
+func Test_kernelRelease_Unknown(t *testing.T) {
+	kernelReleaseFile = filepath.Join(t.TempDir(), "unknown")
+	defer func() { kernelReleaseFile = "/proc/sys/kernel/osrelease" }()
+
+	assert.Empty(t, kernelRelease())
+}
@@ -2,6 +2,8 @@ package action
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/agrison/go-commons-lang/stringUtils"
@@ -22,6 +24,9 @@ type jobValidator interface {
 	setNext(jobValidator) jobValidator
 }
 
+// nodeWideProfilingTools are the profiling tools able to profile every process of the node at once.
+var nodeWideProfilingTools = []api.ProfilingTool{api.Perf, api.Bpf, api.Btf}
+
 // baseJobValidator is the base structure for job validators that
 // implements common functionality for managing the next validator in the chain.
 type baseJobValidator struct {
@@ -84,7 +89,7 @@ func (v *containerRuntimeValidator) validate(args map[string]any, j *job.Profili
 	containerRuntime := args[TargetContainerRuntime].(string)
 	if stringUtils.IsBlank(containerRuntime) {
 		j.ContainerRuntime = defaultContainerRuntime
-	} else if !api.IsSupportedContainerRuntime(containerRuntime) {
+	} else if !api.IsSupportedContainerRuntime(containerRuntime) && containerRuntime != string(api.SharedProcessNamespace) {
 		return errors.Errorf("unsupported container runtime, choose one of %s", api.AvailableContainerRuntimes())
 	} else {
 		j.ContainerRuntime = api.ContainerRuntime(containerRuntime)
@@ -105,11 +110,18 @@ type identificationValidator struct {
 }
 
 // validate sets UID, PodUID, ContainerID, and FileName.
+// When the whole node is profiled, the containers of the node are set instead of the target container.
 func (v *identificationValidator) validate(args map[string]any, j *job.ProfilingJob) error {
 	j.UID = args[JobId].(string)
 	j.PodUID = args[TargetPodUID].(string)
 	j.ContainerID = util.NormalizeContainerID(args[TargetContainerID].(string))
 	j.FileName = args[Filename].(string)
+	if args[TargetNodeWide] != nil && args[TargetNodeWide].(bool) {
+		j.NodeWide = true
+		if err := setNodeContainers(args, j); err != nil {
+			return err
+		}
+	}
 	return v.validateNext(args, j)
 }
 
@@ -173,6 +185,9 @@ type profilingToolAndOutputValidator struct {
 func (v *profilingToolAndOutputValidator) validate(args map[string]any, j *job.ProfilingJob) error {
 	validateProfilingTool(args[ProfilingTool].(string), args[OutputType].(string), j)
 	validateOutputType(args[OutputType].(string), j)
+	if j.NodeWide && !slices.Contains(nodeWideProfilingTools, j.Tool) {
+		return errors.Errorf("node-wide profiling is not supported by %s, choose one of %s", j.Tool, nodeWideProfilingTools)
+	}
 	return v.validateNext(args, j)
 }
 
@@ -207,6 +222,27 @@ func validateJob(args map[string]any, j *job.ProfilingJob) error {
 	return validator.validate(args, j)
 }
 
+// setNodeContainers sets the containers of the node given as <container-id>=<namespace>/<pod>/<container>.
+func setNodeContainers(args map[string]any, j *job.ProfilingJob) error {
+	var nodeContainers []string
+	if args[TargetNodeContainer] != nil {
+		nodeContainers = args[TargetNodeContainer].([]string)
+	}
+	if len(nodeContainers) == 0 {
+		return errors.New("node containers are mandatory for node-wide profiling")
+	}
+
+	j.NodeContainers = make(map[string]string, len(nodeContainers))
+	for _, nodeContainer := range nodeContainers {
+		id, legend, found := strings.Cut(nodeContainer, "=")
+		if !found || stringUtils.IsBlank(id) || stringUtils.IsBlank(legend) {
+			return errors.Errorf("invalid node container %q, expected <container-id>=<namespace>/<pod>/<container>", nodeContainer)
+		}
+		j.NodeContainers[util.NormalizeContainerID(id)] = legend
+	}
+	return nil
+}
+
 // setOutputSplitChunkSize sets the output split chunk size for relevant output types.
 func setOutputSplitChunkSize(args map[string]any, j *job.ProfilingJob) {
 	if j.OutputType == api.HeapDump || j.OutputType == api.HeapSnapshot ||
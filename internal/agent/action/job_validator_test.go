@@ -317,6 +317,84 @@ func TestValidateJob(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Node-wide validation success",
+			args: map[string]any{
+				JobId:                      "job-3",
+				Duration:                   "10s",
+				Interval:                   "10s",
+				TargetContainerRuntime:     string(api.Containerd),
+				TargetContainerRuntimePath: "",
+				TargetPodUID:               "",
+				TargetContainerID:          "",
+				TargetNodeWide:             true,
+				TargetNodeContainer:        []string{"containerd://1234=ns/pod/app", "5678=ns/other/sidecar"},
+				Lang:                       string(api.Clang),
+				EventType:                  "",
+				CompressorType:             "",
+				ProfilingTool:              string(api.Perf),
+				OutputType:                 string(api.FlameGraph),
+				Filename:                   "",
+			},
+			verify: func(t *testing.T, j *job.ProfilingJob) {
+				assert.True(t, j.NodeWide)
+				assert.Equal(t, map[string]string{"1234": "ns/pod/app", "5678": "ns/other/sidecar"}, j.NodeContainers)
+				assert.Equal(t, api.Perf, j.Tool)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Node-wide without node containers",
+			args: map[string]any{
+				JobId:                      "job-4",
+				Duration:                   "",
+				Interval:                   "",
+				TargetContainerRuntime:     "",
+				TargetContainerRuntimePath: "",
+				TargetPodUID:               "",
+				TargetContainerID:          "",
+				TargetNodeWide:             true,
+				Filename:                   "",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Node-wide with invalid node container",
+			args: map[string]any{
+				JobId:                      "job-5",
+				Duration:                   "",
+				Interval:                   "",
+				TargetContainerRuntime:     "",
+				TargetContainerRuntimePath: "",
+				TargetPodUID:               "",
+				TargetContainerID:          "",
+				TargetNodeWide:             true,
+				TargetNodeContainer:        []string{"1234"},
+				Filename:                   "",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Node-wide with unsupported profiling tool",
+			args: map[string]any{
+				JobId:                      "job-6",
+				Duration:                   "",
+				Interval:                   "",
+				TargetContainerRuntime:     "",
+				TargetContainerRuntimePath: "",
+				TargetPodUID:               "",
+				TargetContainerID:          "",
+				TargetNodeWide:             true,
+				TargetNodeContainer:        []string{"1234=ns/pod/app"},
+				Lang:                       string(api.Java),
+				EventType:                  "",
+				CompressorType:             "",
+				ProfilingTool:              string(api.Jcmd),
+				OutputType:                 string(api.Jfr),
+				Filename:                   "",
+			},
+			wantErr: true,
+		},
 		{
 			name: "Invalid duration",
 			args: map[string]any{
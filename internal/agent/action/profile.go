@@ -1,11 +1,17 @@
 package action
 
 import (
+	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/josepdcs/kubectl-prof/api"
 	"github.com/josepdcs/kubectl-prof/internal/agent/job"
 	"github.com/josepdcs/kubectl-prof/internal/agent/profiler"
+	"github.com/josepdcs/kubectl-prof/internal/agent/profiler/common"
+	"github.com/josepdcs/kubectl-prof/internal/agent/util/progress"
+	"github.com/josepdcs/kubectl-prof/internal/agent/util/publish"
 	"github.com/josepdcs/kubectl-prof/pkg/util/compressor"
 	"github.com/josepdcs/kubectl-prof/pkg/util/log"
 )
@@ -35,6 +41,11 @@ const (
 	HeartbeatInterval                 = "heartbeat-interval"
 	PprofHost                         = "pprof-host"
 	PprofPort                         = "pprof-port"
+	TargetNodeWide                    = "target-node-wide"
+	TargetNodeContainer               = "target-node-container"
+	FileServerPort                    = "file-server-port"
+	UploadTo                          = "upload-to"
+	OutputDir                         = "output-dir"
 
 	defaultDuration               = 60 * time.Second
 	defaultHeartbeatInterval      = 30 * time.Second
@@ -44,6 +55,13 @@ const (
 	defaultOutputSplitInChunkSize = "50M"
 )
 
+var (
+	// stopped is closed once the profiling is asked to stop early
+	stopped = make(chan struct{})
+	// stopOnce ensures the profiling is stopped only once
+	stopOnce sync.Once
+)
+
 // NewProfile initializes and returns a [profiler.Profiler], [job.ProfilingJob], and any error encountered during setup.
 func NewProfile(args map[string]any) (profiler.Profiler, *job.ProfilingJob, error) {
 	log.SetPrintLogs(args[PrintLogs].(bool))
@@ -57,25 +75,50 @@ func NewProfile(args map[string]any) (profiler.Profiler, *job.ProfilingJob, erro
 }
 
 // Run runs the profiling job using the provided [profiler.Profiler] and [job.ProfilingJob]. It returns any error encountered during execution.
-func Run(p profiler.Profiler, job *job.ProfilingJob) error {
+// Once the given context is cancelled, the running profiling tools are terminated and the context error is returned.
+func Run(ctx context.Context, p profiler.Profiler, job *job.ProfilingJob) error {
 	_ = log.EventLn(api.Progress, &api.ProgressData{Time: time.Now(), Stage: api.Started})
 
-	err := p.SetUp(job)
+	err := p.SetUp(ctx, job)
 	if err != nil {
 		return err
 	}
+	defer context.AfterFunc(ctx, common.TerminateTools)()
 
 	// if Duration == Interval, one iteration occurs (discrete mode)
 	iterations := int64(job.Duration.Seconds() / job.Interval.Seconds())
+
+	// the progress is reported periodically while profiling
+	progress.Begin(job.Duration, int(iterations))
+	stop := make(chan struct{})
+	defer close(stop)
+	go progress.Report(job.HeartbeatInterval, stop)
+
 	var i int64
 	for i = 0; i < iterations; i++ {
 		job.Iteration = int(i) + 1
-		err, d := p.Invoke(job)
+		publish.SetIteration(job.Iteration)
+		progress.SetIteration(job.Iteration)
+		_ = log.EventLn(api.Progress, progress.Event())
+		err, d := p.Invoke(ctx, job)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		if err != nil {
 			return err
 		}
 		if iterations > 1 && d.Seconds() < job.Interval.Seconds() {
-			time.Sleep(time.Duration(job.Interval.Milliseconds()-d.Milliseconds()) * time.Millisecond)
+			select {
+			case <-time.After(time.Duration(job.Interval.Milliseconds()-d.Milliseconds()) * time.Millisecond):
+			case <-stopped:
+				// no need to wait for the next iteration
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if isStopped() {
+			log.InfoLogLn(fmt.Sprintf("Profiling stopped early at iteration %d of %d", job.Iteration, iterations))
+			break
 		}
 	}
 
@@ -84,6 +127,28 @@ func Run(p profiler.Profiler, job *job.ProfilingJob) error {
 	return nil
 }
 
+// Stop asks the running profiling to finish its current capture early: the partial results are published and
+// no further iteration is started. If the profiling tool cannot finish its capture early, only the next iterations
+// are cancelled.
+func Stop(ctx context.Context, p profiler.Profiler, job *job.ProfilingJob) error {
+	var err error
+	stopOnce.Do(func() {
+		close(stopped)
+		err = p.Stop(ctx, job)
+	})
+	return err
+}
+
+// isStopped returns whether the profiling was asked to stop early
+func isStopped() bool {
+	select {
+	case <-stopped:
+		return true
+	default:
+		return false
+	}
+}
+
 // getProfilingJob gets a new filled config.ProfilingJob according given cli.Context
 func getProfilingJob(args map[string]any) (*job.ProfilingJob, error) {
 	j := &job.ProfilingJob{}
@@ -1,13 +1,17 @@
 package action
 
 import (
+	"context"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/josepdcs/kubectl-prof/api"
 	"github.com/josepdcs/kubectl-prof/internal/agent/job"
 	"github.com/josepdcs/kubectl-prof/internal/agent/profiler"
 	"github.com/josepdcs/kubectl-prof/internal/agent/profiler/jvm"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewProfile(t *testing.T) {
@@ -359,7 +363,7 @@ func TestRun(t *testing.T) {
 
 			// When
 			p, profilingJob, err = NewProfile(tt.args)
-			err = Run(p, profilingJob)
+			err = Run(context.Background(), p, profilingJob)
 
 			// Then
 			if err != nil && !tt.wantErr {
@@ -371,3 +375,79 @@ func TestRun(t *testing.T) {
 		})
 	}
 }
+
+func TestStop(t *testing.T) {
+	defer func() {
+		stopped = make(chan struct{})
+		stopOnce = sync.Once{}
+	}()
+
+	// Given
+	p, profilingJob, err := NewProfile(map[string]any{
+		PrintLogs:                  true,
+		Duration:                   "3s",
+		Interval:                   "1s",
+		JobId:                      "JobId",
+		TargetPodUID:               "TargetPodUID",
+		TargetContainerID:          "cri-o://TargetContainerID",
+		Filename:                   "Filename",
+		Lang:                       string(api.FakeLang),
+		ProfilingTool:              string(api.Jcmd),
+		OutputType:                 string(api.Jfr),
+		EventType:                  "",
+		CompressorType:             "",
+		TargetContainerRuntime:     "crio",
+		TargetContainerRuntimePath: "/my/path",
+	})
+	require.NoError(t, err)
+
+	// When
+	stopDone := make(chan struct{})
+	time.AfterFunc(200*time.Millisecond, func() {
+		defer close(stopDone)
+		_ = Stop(context.Background(), p, profilingJob)
+		_ = Stop(context.Background(), p, profilingJob)
+	})
+	start := time.Now()
+	err = Run(context.Background(), p, profilingJob)
+	<-stopDone
+
+	// Then
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+	mock := p.(profiler.MockProfiler)
+	assert.Equal(t, 1, mock.InvokeInvokedTimes())
+	assert.Equal(t, 1, mock.StopInvokedTimes())
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	// Given
+	p, profilingJob, err := NewProfile(map[string]any{
+		PrintLogs:                  true,
+		Duration:                   "3s",
+		Interval:                   "1s",
+		JobId:                      "JobId",
+		TargetPodUID:               "TargetPodUID",
+		TargetContainerID:          "cri-o://TargetContainerID",
+		Filename:                   "Filename",
+		Lang:                       string(api.FakeLang),
+		ProfilingTool:              string(api.Jcmd),
+		OutputType:                 string(api.Jfr),
+		EventType:                  "",
+		CompressorType:             "",
+		TargetContainerRuntime:     "crio",
+		TargetContainerRuntimePath: "/my/path",
+	})
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// When
+	start := time.Now()
+	err = Run(ctx, p, profilingJob)
+
+	// Then
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, 1, p.(profiler.MockProfiler).InvokeInvokedTimes())
+}
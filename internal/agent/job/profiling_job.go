@@ -34,6 +34,12 @@ type ProfilingJob struct {
 	NodeHeapSnapshotSignal int
 	AdditionalArguments    map[string]string
 	Iteration              int
+	// NodeWide indicates that every container of the node is profiled instead of a single target container
+	NodeWide bool
+	// NodeContainers maps the ID of each container of the node to its namespace/pod/container legend
+	NodeContainers map[string]string
+	// ProcessLegends maps the profiled PIDs to the legend prefixed to their stacks, if any
+	ProcessLegends map[string]string
 }
 
 func (p *ProfilingJob) String() string {
@@ -71,13 +71,17 @@ func TestProfilingJob_ToMap(t *testing.T) {
 			"maxsize":  "1024M",
 			"settings": "custom",
 		},
-		Iteration: 0,
+		Iteration:      0,
+		NodeWide:       true,
+		NodeContainers: map[string]string{"1234": "ns/pod/container"},
 	}
 
 	out := p.ToMap()
 
 	assert.NotEmpty(t, out)
-	assert.Len(t, out, 20)
+	assert.Len(t, out, 23)
+	assert.Equal(t, true, out["NodeWide"])
+	assert.Equal(t, map[string]any{"1234": "ns/pod/container"}, out["NodeContainers"])
 	assert.Equal(t, float64(10), out["Duration"])
 	assert.Equal(t, float64(5), out["Interval"])
 	assert.Equal(t, "ID", out["UID"])
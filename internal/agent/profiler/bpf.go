@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"maps"
 	"os/exec"
+	"slices"
 	"strconv"
 	"time"
 
@@ -17,6 +19,7 @@ import (
 	"github.com/josepdcs/kubectl-prof/internal/agent/util"
 	executil "github.com/josepdcs/kubectl-prof/internal/agent/util/exec"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util/flamegraph"
+	"github.com/josepdcs/kubectl-prof/internal/agent/util/progress"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util/publish"
 	"github.com/josepdcs/kubectl-prof/pkg/util/file"
 	"github.com/josepdcs/kubectl-prof/pkg/util/log"
@@ -43,6 +46,7 @@ type BpfProfiler struct {
 type BpfManager interface {
 	invoke(*job.ProfilingJob, string) (error, time.Duration)
 	handleFlamegraph(*job.ProfilingJob, flamegraph.FrameGrapher, string, string) error
+	publishNodeResult(*job.ProfilingJob, []string) error
 }
 
 type bpfManager struct {
@@ -60,7 +64,16 @@ func NewBpfProfiler(commander executil.Commander, publisher publish.Publisher) *
 	}
 }
 
-func (b *BpfProfiler) SetUp(job *job.ProfilingJob) error {
+func (b *BpfProfiler) SetUp(_ context.Context, job *job.ProfilingJob) error {
+	if job.NodeWide {
+		targets, err := util.GetNodeTargets(job)
+		if err != nil {
+			return err
+		}
+		job.ProcessLegends = targets
+		b.targetPIDs = slices.Sorted(maps.Keys(targets))
+		return nil
+	}
 	if stringUtils.IsNotBlank(job.PID) {
 		b.targetPIDs = []string{job.PID}
 		return nil
@@ -75,20 +88,23 @@ func (b *BpfProfiler) SetUp(job *job.ProfilingJob) error {
 	return nil
 }
 
-func (b *BpfProfiler) Invoke(job *job.ProfilingJob) (error, time.Duration) {
+func (b *BpfProfiler) Invoke(ctx context.Context, job *job.ProfilingJob) (error, time.Duration) {
 	start := time.Now()
 
 	pool := pond.New(len(b.targetPIDs), 0, pond.MinWorkers(len(b.targetPIDs)))
 	defer pool.StopAndWait()
 
 	// create a task group associated to a context
-	group, _ := pool.GroupContext(context.Background())
+	group, _ := pool.GroupContext(ctx)
 
 	// submit tasks to profile
 	for _, pid := range b.targetPIDs {
 		pid := pid
 		group.Submit(func() error {
+			progress.Running(pid)
 			err, _ := b.invoke(job, pid)
+			err = common.ToolError(job.Tool, pid, err)
+			progress.Done(pid, err)
 			return err
 		})
 		// wait a bit between jobs for not overloading the system
@@ -97,6 +113,9 @@ func (b *BpfProfiler) Invoke(job *job.ProfilingJob) (error, time.Duration) {
 
 	// wait for all tasks to finish
 	err := group.Wait()
+	if err == nil && job.NodeWide {
+		err = b.publishNodeResult(job, b.targetPIDs)
+	}
 
 	return err, time.Since(start)
 }
@@ -117,10 +136,14 @@ func (b *bpfManager) invoke(job *job.ProfilingJob, pid string) (error, time.Dura
 	}
 
 	// out file names is composed by the job info and the pid
-	resultFileName := common.GetResultFile(common.TmpDir(), job.Tool, job.OutputType, pid, job.Iteration)
-	fileName := common.GetResultFile(common.TmpDir(), job.Tool, api.Raw, pid, job.Iteration)
-	// add process pid legend to each line of the output and write it to the file
-	file.Write(fileName, addProcessPIDLegend(out.String(), pid))
+	resultFileName := common.GetResultFile(common.TmpDir(), job.Tool, job.OutputType, resultFileID(pid), job.Iteration)
+	fileName := common.GetResultFile(common.TmpDir(), job.Tool, api.Raw, resultFileID(pid), job.Iteration)
+	// add process pid (or pod, if the whole node is profiled) legend to each line of the output and write it to the file
+	file.Write(fileName, addJobLegend(out.String(), job, pid))
+	if job.NodeWide {
+		// the result of the whole node is published once all its processes have been profiled
+		return nil, time.Since(start)
+	}
 
 	err = b.handleFlamegraph(job, flamegraph.Get(job), fileName, resultFileName)
 	if err != nil {
@@ -128,14 +151,19 @@ func (b *bpfManager) invoke(job *job.ProfilingJob, pid string) (error, time.Dura
 		return nil, time.Since(start)
 	}
 
+	progress.Publishing(pid)
 	return b.publisher.Do(job.Compressor, resultFileName, job.OutputType), time.Since(start)
 }
 
+func (b *bpfManager) publishNodeResult(job *job.ProfilingJob, pids []string) error {
+	return publishNodeResult(job, pids, b.handleFlamegraph, b.publisher)
+}
+
 func (b *bpfManager) handleFlamegraph(job *job.ProfilingJob, flameGrapher flamegraph.FrameGrapher, rawFileName string,
 	flameFileName string) error {
 	if job.OutputType == api.FlameGraph {
 		if file.Size(rawFileName) < common.MinimumRawSize {
-			return fmt.Errorf("unable to generate flamegraph: no stacks found (maybe due low cpu load)")
+			return common.ErrNoSamples
 		}
 		// convert a raw format to flamegraph
 		err := flameGrapher.StackSamplesToFlameGraph(rawFileName, flameFileName)
@@ -146,7 +174,11 @@ func (b *bpfManager) handleFlamegraph(job *job.ProfilingJob, flameGrapher flameg
 	return nil
 }
 
-func (b *BpfProfiler) CleanUp(*job.ProfilingJob) error {
+func (b *BpfProfiler) Stop(context.Context, *job.ProfilingJob) error {
+	return common.ErrStopUnsupported
+}
+
+func (b *BpfProfiler) CleanUp(context.Context, *job.ProfilingJob) error {
 	file.RemoveAll(common.TmpDir(), config.ProfilingPrefix)
 
 	return nil
@@ -39,3 +39,13 @@ func (m *mockBpfManager) handleFlamegraph(j *job.ProfilingJob, fg flamegraph.Fra
 	}
 	return nil
 }
+
+func (m *mockBpfManager) publishNodeResult(j *job.ProfilingJob, pids []string) error {
+	args := m.Called(j, pids)
+	if a := args.Get(0); a != nil {
+		if err, ok := a.(error); ok {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package profiler
 
 import (
+	"context"
 	"bytes"
 	"errors"
 	"os"
@@ -53,7 +54,7 @@ func TestBpfProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.BpfProfiler.SetUp(args.job)
+				return fields.BpfProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -77,7 +78,7 @@ func TestBpfProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.BpfProfiler.SetUp(args.job)
+				return fields.BpfProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -100,7 +101,7 @@ func TestBpfProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.BpfProfiler.SetUp(args.job)
+				return fields.BpfProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.NotNil(t, err)
@@ -160,13 +161,48 @@ func TestBpfProfiler_Invoke(t *testing.T) {
 			when: func(fields fields, args args) (error, time.Duration) {
 				fields.BpfProfiler.delay = 0
 				fields.BpfProfiler.targetPIDs = []string{"1000", "2000"}
-				return fields.BpfProfiler.Invoke(args.job)
+				return fields.BpfProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
 				fields.BpfProfiler.BpfManager.(*mockBpfManager).AssertNumberOfCalls(t, "invoke", 2)
 			},
 		},
+		{
+			name: "should invoke and publish the node result when the whole node is profiled",
+			given: func() (fields, args) {
+				bpfManager := newMockBpfManager()
+				bpfManager.On("invoke", mock.Anything, mock.AnythingOfType("string")).
+					Return(nil, time.Duration(0)).
+					Twice()
+				bpfManager.On("publishNodeResult", mock.Anything, []string{"100,101", "200"}).
+					Return(nil).
+					Once()
+
+				return fields{
+						BpfProfiler: &BpfProfiler{
+							BpfManager: bpfManager,
+						},
+					}, args{
+						job: &job.ProfilingJob{
+							Duration:         0,
+							ContainerRuntime: api.FakeContainer,
+							OutputType:       api.FlameGraph,
+							NodeWide:         true,
+						},
+					}
+			},
+			when: func(fields fields, args args) (error, time.Duration) {
+				fields.BpfProfiler.delay = 0
+				fields.BpfProfiler.targetPIDs = []string{"100,101", "200"}
+				return fields.BpfProfiler.Invoke(context.Background(), args.job)
+			},
+			then: func(t *testing.T, err error, fields fields) {
+				assert.Nil(t, err)
+				fields.BpfProfiler.BpfManager.(*mockBpfManager).AssertNumberOfCalls(t, "invoke", 2)
+				fields.BpfProfiler.BpfManager.(*mockBpfManager).AssertNumberOfCalls(t, "publishNodeResult", 1)
+			},
+		},
 		{
 			name: "should invoke fail when invoke fail",
 			given: func() (fields, args) {
@@ -191,7 +227,7 @@ func TestBpfProfiler_Invoke(t *testing.T) {
 			when: func(fields fields, args args) (error, time.Duration) {
 				fields.BpfProfiler.delay = 0
 				fields.BpfProfiler.targetPIDs = []string{"1000", "2000"}
-				return fields.BpfProfiler.Invoke(args.job)
+				return fields.BpfProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				require.Error(t, err)
@@ -246,7 +282,7 @@ func TestBpfProfiler_CleanUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.BpfProfiler.CleanUp(args.job)
+				return fields.BpfProfiler.CleanUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				f := filepath.Join(common.TmpDir(), config.ProfilingPrefix+"flamegraph.svg")
@@ -1,6 +1,7 @@
 package profiler
 
 import (
+	"context"
 	"bytes"
 	"errors"
 	"os"
@@ -52,7 +53,7 @@ func TestBtfProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.BtfProfiler.SetUp(args.job)
+				return fields.BtfProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -76,7 +77,7 @@ func TestBtfProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.BtfProfiler.SetUp(args.job)
+				return fields.BtfProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -99,7 +100,7 @@ func TestBtfProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.BtfProfiler.SetUp(args.job)
+				return fields.BtfProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.NotNil(t, err)
@@ -159,7 +160,7 @@ func TestBtfProfiler_Invoke(t *testing.T) {
 			when: func(fields fields, args args) (error, time.Duration) {
 				fields.BtfProfiler.delay = 0
 				fields.BtfProfiler.targetPIDs = []string{"1000", "2000"}
-				return fields.BtfProfiler.Invoke(args.job)
+				return fields.BtfProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -190,7 +191,7 @@ func TestBtfProfiler_Invoke(t *testing.T) {
 			when: func(fields fields, args args) (error, time.Duration) {
 				fields.BtfProfiler.delay = 0
 				fields.BtfProfiler.targetPIDs = []string{"1000", "2000"}
-				return fields.BtfProfiler.Invoke(args.job)
+				return fields.BtfProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				require.Error(t, err)
@@ -245,7 +246,7 @@ func TestBtfProfiler_CleanUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.BtfProfiler.CleanUp(args.job)
+				return fields.BtfProfiler.CleanUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				f := filepath.Join(common.TmpDir(), config.ProfilingPrefix+"flamegraph.svg")
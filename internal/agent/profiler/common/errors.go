@@ -0,0 +1,52 @@
+package common
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/josepdcs/kubectl-prof/api"
+)
+
+// ErrNoSamples is returned when the profiling did not get any sample, usually because the target process was idle
+var ErrNoSamples = errors.New("unable to generate flamegraph: no stacks found (maybe due low cpu load)")
+
+// ToolError categorizes the given error of the tool profiling the given PID, so that the CLI can show actionable
+// guidance. Errors already categorized keep their code.
+func ToolError(tool api.ProfilingTool, pid string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var agentErr *api.AgentError
+	if errors.As(err, &agentErr) {
+		if agentErr.PID == "" {
+			agentErr.PID = pid
+		}
+		if agentErr.Tool == "" {
+			agentErr.Tool = tool
+		}
+		return err
+	}
+	return &api.AgentError{Code: errorCode(err), PID: pid, Tool: tool, Err: err}
+}
+
+// errorCode returns the code of the given error of a profiling tool, which is considered crashed unless the cause
+// of the failure is known
+func errorCode(err error) api.ErrorCode {
+	msg := strings.ToLower(err.Error())
+	switch {
+	case errors.Is(err, ErrNoSamples):
+		return api.NoSamples
+	case errors.Is(err, os.ErrPermission), strings.Contains(msg, "permission denied"),
+		strings.Contains(msg, "operation not permitted"), strings.Contains(msg, "ptrace"):
+		return api.PermissionDenied
+	case errors.Is(err, syscall.ESRCH), strings.Contains(msg, "no such process"):
+		return api.PIDNotFound
+	case errors.Is(err, syscall.ENOSPC), errors.Is(err, syscall.EFBIG),
+		strings.Contains(msg, "no space left on device"), strings.Contains(msg, "file too large"):
+		return api.OutputTooLarge
+	default:
+		return api.ToolCrashed
+	}
+}
@@ -0,0 +1,45 @@
+package common
+
+import (
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want api.ErrorCode
+	}{
+		{name: "should categorize no samples", err: errors.Wrap(ErrNoSamples, "folding perf output failed"), want: api.NoSamples},
+		{name: "should categorize a permission denied", err: errors.Wrap(os.ErrPermission, "perf record failed"), want: api.PermissionDenied},
+		{name: "should categorize a restricted ptrace", err: errors.New("py-spy failed: Failed to ptrace attach"), want: api.PermissionDenied},
+		{name: "should categorize a process not found", err: errors.Wrap(syscall.ESRCH, "jcmd failed"), want: api.PIDNotFound},
+		{name: "should categorize an output too large", err: errors.Wrap(syscall.ENOSPC, "write failed"), want: api.OutputTooLarge},
+		{name: "should categorize any other error as a crash", err: errors.New("perf record failed: exit status 1"), want: api.ToolCrashed},
+		{name: "should keep the code of a categorized error", err: errors.Wrap(&api.AgentError{Code: api.UnsupportedRuntimeLayout, Err: errors.New("pid file not found")}, "set up failed"), want: api.UnsupportedRuntimeLayout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ToolError(api.Perf, "1234", tt.err)
+
+			require.Error(t, err)
+			assert.Equal(t, tt.err.Error(), err.Error())
+			data := api.NewErrorData(err)
+			assert.Equal(t, tt.want, data.Code)
+			assert.Equal(t, "1234", data.PID)
+			assert.Equal(t, api.Perf, data.Tool)
+			assert.NotEmpty(t, data.Hint)
+		})
+	}
+}
+
+func TestToolError_Nil(t *testing.T) {
+	assert.NoError(t, ToolError(api.Perf, "1234", nil))
+}
@@ -0,0 +1,103 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/josepdcs/kubectl-prof/pkg/util/log"
+)
+
+// ErrStopUnsupported is returned when the profiling tool cannot be asked to finish its capture early
+var ErrStopUnsupported = errors.New("the profiling tool cannot be stopped early")
+
+var (
+	// running are the commands of the profiling tools currently capturing
+	running = make(map[*exec.Cmd]struct{})
+	// runningMutex serializes the access to the running commands
+	runningMutex sync.Mutex
+	// stopping tells whether the profiling tools were asked to finish their capture early
+	stopping atomic.Bool
+)
+
+// RunTool runs the given command of a profiling tool, which can be asked to finish its capture early by
+// InterruptTools. If so, the tool ends because of the signal, which is not considered an error.
+func RunTool(cmd *exec.Cmd) error {
+	if err := StartTool(cmd); err != nil {
+		return err
+	}
+	return WaitTool(cmd)
+}
+
+// StartTool starts the given command of a profiling tool, which can be asked to finish its capture early by
+// InterruptTools up to WaitTool returns
+func StartTool(cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	runningMutex.Lock()
+	defer runningMutex.Unlock()
+	running[cmd] = struct{}{}
+	if IsStopping() {
+		// the tool was started while stopping, so it captures just for a moment
+		sendSignal(cmd, syscall.SIGINT)
+	}
+	return nil
+}
+
+// WaitTool waits for the given command of a profiling tool started by StartTool. If the tool was asked to finish
+// its capture early, it ends because of the signal, which is not considered an error.
+func WaitTool(cmd *exec.Cmd) error {
+	err := cmd.Wait()
+
+	runningMutex.Lock()
+	delete(running, cmd)
+	runningMutex.Unlock()
+
+	if err != nil && IsStopping() {
+		log.DebugLogLn(fmt.Sprintf("%s ended after being stopped early: %v", cmd.Path, err))
+		return nil
+	}
+	return err
+}
+
+// InterruptTools asks the running profiling tools, and those started from now on, to finish their capture early by
+// sending them SIGINT, as Ctrl-C does. The process group is signaled when the tool runs in its own one, so that its
+// subprocesses are interrupted as well.
+func InterruptTools() {
+	stopping.Store(true)
+
+	runningMutex.Lock()
+	defer runningMutex.Unlock()
+	for cmd := range running {
+		sendSignal(cmd, syscall.SIGINT)
+	}
+}
+
+// TerminateTools kills the running profiling tools, which is done when the agent is terminated
+func TerminateTools() {
+	runningMutex.Lock()
+	defer runningMutex.Unlock()
+	for cmd := range running {
+		sendSignal(cmd, syscall.SIGKILL)
+	}
+}
+
+// sendSignal sends the given signal to the given running command, or to its process group if it runs in its own one
+func sendSignal(cmd *exec.Cmd, sig syscall.Signal) {
+	pid := cmd.Process.Pid
+	if cmd.SysProcAttr != nil && cmd.SysProcAttr.Setpgid {
+		pid = -pid
+	}
+	if err := syscall.Kill(pid, sig); err != nil {
+		log.WarningLogLn(fmt.Sprintf("could not send %s to %s: %v", sig, cmd.Path, err))
+	}
+}
+
+// IsStopping returns whether the profiling tools were asked to finish their capture early
+func IsStopping() bool {
+	return stopping.Load()
+}
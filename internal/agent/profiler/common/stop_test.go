@@ -0,0 +1,95 @@
+package common
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunTool(t *testing.T) {
+	tests := []struct {
+		name  string
+		given func() *exec.Cmd
+		when  func(cmd *exec.Cmd) error
+		then  func(t *testing.T, err error, elapsed time.Duration)
+	}{
+		{
+			name: "should run the tool up to its end",
+			given: func() *exec.Cmd {
+				return exec.Command("true")
+			},
+			when: RunTool,
+			then: func(t *testing.T, err error, _ time.Duration) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "should return the failure of the tool",
+			given: func() *exec.Cmd {
+				return exec.Command("false")
+			},
+			when: RunTool,
+			then: func(t *testing.T, err error, _ time.Duration) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "should end the tool early when stopping",
+			given: func() *exec.Cmd {
+				return exec.Command("sleep", "10")
+			},
+			when: func(cmd *exec.Cmd) error {
+				time.AfterFunc(100*time.Millisecond, InterruptTools)
+				return RunTool(cmd)
+			},
+			then: func(t *testing.T, err error, elapsed time.Duration) {
+				assert.NoError(t, err)
+				assert.Less(t, elapsed, 5*time.Second)
+				assert.True(t, IsStopping())
+			},
+		},
+		{
+			name: "should kill the tool when terminated",
+			given: func() *exec.Cmd {
+				return exec.Command("sleep", "10")
+			},
+			when: func(cmd *exec.Cmd) error {
+				time.AfterFunc(100*time.Millisecond, TerminateTools)
+				return RunTool(cmd)
+			},
+			then: func(t *testing.T, err error, elapsed time.Duration) {
+				assert.Error(t, err)
+				assert.Less(t, elapsed, 5*time.Second)
+			},
+		},
+		{
+			name: "should end right away the tool started while stopping",
+			given: func() *exec.Cmd {
+				InterruptTools()
+				return exec.Command("sleep", "10")
+			},
+			when: RunTool,
+			then: func(t *testing.T, err error, elapsed time.Duration) {
+				assert.NoError(t, err)
+				assert.Less(t, elapsed, 5*time.Second)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer stopping.Store(false)
+
+			// Given
+			cmd := tt.given()
+
+			// When
+			start := time.Now()
+			err := tt.when(cmd)
+
+			// Then
+			tt.then(t, err, time.Since(start))
+		})
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/josepdcs/kubectl-prof/internal/agent/profiler/common"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util"
 	executil "github.com/josepdcs/kubectl-prof/internal/agent/util/exec"
+	"github.com/josepdcs/kubectl-prof/internal/agent/util/progress"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util/publish"
 	"github.com/josepdcs/kubectl-prof/pkg/util/file"
 	"github.com/josepdcs/kubectl-prof/pkg/util/log"
@@ -218,7 +219,7 @@ func NewDotnetProfiler(commander executil.Commander, publisher publish.Publisher
 	}
 }
 
-func (p *DotnetProfiler) SetUp(job *job.ProfilingJob) error {
+func (p *DotnetProfiler) SetUp(_ context.Context, job *job.ProfilingJob) error {
 	if stringUtils.IsNotBlank(job.PID) {
 		p.targetPIDs = []string{job.PID}
 	} else {
@@ -237,20 +238,23 @@ func (p *DotnetProfiler) SetUp(job *job.ProfilingJob) error {
 	return nil
 }
 
-func (p *DotnetProfiler) Invoke(job *job.ProfilingJob) (error, time.Duration) {
+func (p *DotnetProfiler) Invoke(ctx context.Context, job *job.ProfilingJob) (error, time.Duration) {
 	start := time.Now()
 
 	pool := pond.New(len(p.targetPIDs), 0, pond.MinWorkers(len(p.targetPIDs)))
 	defer pool.StopAndWait()
 
 	// create a task group associated to a context
-	group, _ := pool.GroupContext(context.Background())
+	group, _ := pool.GroupContext(ctx)
 
 	// submit tasks to profile
 	for _, pid := range p.targetPIDs {
 		pid := pid
 		group.Submit(func() error {
+			progress.Running(pid)
 			err, _ := p.invoke(job, pid)
+			err = common.ToolError(job.Tool, pid, err)
+			progress.Done(pid, err)
 			return err
 		})
 		// wait a bit between jobs for not overloading the system
@@ -307,13 +311,18 @@ func (p *dotnetManager) invoke(job *job.ProfilingJob, pid string) (error, time.D
 		resultFileName = filepath.Join(getTargetTmpDir(pid), filepath.Base(resultFileName))
 	}
 
+	progress.Publishing(pid)
 	if job.OutputType == api.Gcdump || job.OutputType == api.Dump {
 		return p.publisher.DoWithNativeGzipAndSplit(resultFileName, job.OutputSplitInChunkSize, job.OutputType), time.Since(start)
 	}
 	return p.publisher.Do(job.Compressor, resultFileName, job.OutputType), time.Since(start)
 }
 
-func (p *DotnetProfiler) CleanUp(*job.ProfilingJob) error {
+func (p *DotnetProfiler) Stop(context.Context, *job.ProfilingJob) error {
+	return common.ErrStopUnsupported
+}
+
+func (p *DotnetProfiler) CleanUp(context.Context, *job.ProfilingJob) error {
 	for _, pid := range p.targetPIDs {
 		// Remove host-PID-named sockets created by setTmpDir (dotnet-trace, dotnet-counters, dotnet-dump).
 		matches, _ := filepath.Glob(fmt.Sprintf("/tmp/dotnet-diagnostic-%s-*-socket", pid))
@@ -1,6 +1,7 @@
 package profiler
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -51,7 +52,7 @@ func TestDotnetProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.DotnetProfiler.SetUp(args.job)
+				return fields.DotnetProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -75,7 +76,7 @@ func TestDotnetProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.DotnetProfiler.SetUp(args.job)
+				return fields.DotnetProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -98,7 +99,7 @@ func TestDotnetProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.DotnetProfiler.SetUp(args.job)
+				return fields.DotnetProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.NotNil(t, err)
@@ -158,7 +159,7 @@ func TestDotnetProfiler_Invoke(t *testing.T) {
 			when: func(fields fields, args args) (error, time.Duration) {
 				fields.DotnetProfiler.delay = 0
 				fields.DotnetProfiler.targetPIDs = []string{"1000", "2000"}
-				return fields.DotnetProfiler.Invoke(args.job)
+				return fields.DotnetProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -189,7 +190,7 @@ func TestDotnetProfiler_Invoke(t *testing.T) {
 			when: func(fields fields, args args) (error, time.Duration) {
 				fields.DotnetProfiler.delay = 0
 				fields.DotnetProfiler.targetPIDs = []string{"1000", "2000"}
-				return fields.DotnetProfiler.Invoke(args.job)
+				return fields.DotnetProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				require.Error(t, err)
@@ -244,7 +245,7 @@ func TestDotnetProfiler_CleanUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.DotnetProfiler.CleanUp(args.job)
+				return fields.DotnetProfiler.CleanUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				f := filepath.Join(common.TmpDir(), config.ProfilingPrefix+"speedscope-1000-1.json")
@@ -1,6 +1,7 @@
 package profiler
 
 import (
+	"context"
 	"time"
 
 	"github.com/josepdcs/kubectl-prof/api"
@@ -11,13 +12,16 @@ import (
 )
 
 // Profiler is the interface that wraps the basic profiling operations.
+// The given context is cancelled when the agent is terminated, so that the running profiling ends.
 type Profiler interface {
 	// SetUp prepare the environment for the profiling
-	SetUp(job *job.ProfilingJob) error
+	SetUp(ctx context.Context, job *job.ProfilingJob) error
 	// Invoke starts the profiling
-	Invoke(job *job.ProfilingJob) (error, time.Duration)
+	Invoke(ctx context.Context, job *job.ProfilingJob) (error, time.Duration)
+	// Stop asks the profiling to finish its current capture early, so that the partial results are published
+	Stop(ctx context.Context, job *job.ProfilingJob) error
 	// CleanUp cleans the environment after the profiling
-	CleanUp(job *job.ProfilingJob) error
+	CleanUp(ctx context.Context, job *job.ProfilingJob) error
 }
 
 // Get returns the profiler for the given tool
@@ -19,6 +19,7 @@ import (
 	"github.com/josepdcs/kubectl-prof/internal/agent/profiler/common"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util"
 	executil "github.com/josepdcs/kubectl-prof/internal/agent/util/exec"
+	"github.com/josepdcs/kubectl-prof/internal/agent/util/progress"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util/publish"
 	"github.com/josepdcs/kubectl-prof/pkg/util/file"
 	"github.com/josepdcs/kubectl-prof/pkg/util/log"
@@ -78,6 +79,21 @@ var asyncProfilerStopCommand = func(j *asyncProfilerManager, job *job.ProfilingJ
 		pid)
 }
 
+// asyncProfilerFlushCommand stops the profiling of the given PID and writes what was collected so far to the given file
+var asyncProfilerFlushCommand = func(j *asyncProfilerManager, job *job.ProfilingJob, pid string, fileName string) *exec.Cmd {
+	output := string(job.OutputType)
+	if job.OutputType == api.Raw {
+		output = string(api.Collapsed)
+	}
+	return j.commander.Command(
+		filepath.Join(j.getTmpDir(), asprofPath),
+		"stop",
+		"--libpath", filepath.Join(j.getTmpDir(), libPath),
+		"-o", output,
+		"-f", fileName,
+		pid)
+}
+
 type AsyncProfiler struct {
 	targetPIDs []string
 	delay      time.Duration
@@ -91,6 +107,7 @@ type AsyncProfilerManager interface {
 	copyProfilerToTmpDir() error
 	invoke(*job.ProfilingJob, string) (error, time.Duration)
 	cleanUp(*job.ProfilingJob, string)
+	stop(*job.ProfilingJob, string) error
 }
 
 type asyncProfilerManager struct {
@@ -108,7 +125,7 @@ func NewAsyncProfiler(commander executil.Commander, publisher publish.Publisher)
 	}
 }
 
-func (j *AsyncProfiler) SetUp(job *job.ProfilingJob) error {
+func (j *AsyncProfiler) SetUp(_ context.Context, job *job.ProfilingJob) error {
 	targetFs, err := util.ContainerFileSystem(job.ContainerRuntime, job.ContainerID, job.ContainerRuntimePath)
 	if err != nil {
 		return err
@@ -166,20 +183,23 @@ func (j *asyncProfilerManager) copyProfilerToTmpDir() error {
 	return cmd.Run()
 }
 
-func (j *AsyncProfiler) Invoke(job *job.ProfilingJob) (error, time.Duration) {
+func (j *AsyncProfiler) Invoke(ctx context.Context, job *job.ProfilingJob) (error, time.Duration) {
 	start := time.Now()
 
 	pool := pond.New(len(j.targetPIDs), 0, pond.MinWorkers(len(j.targetPIDs)))
 	defer pool.StopAndWait()
 
 	// create a task group associated to a context
-	group, _ := pool.GroupContext(context.Background())
+	group, _ := pool.GroupContext(ctx)
 
 	// submit tasks to profile
 	for _, pid := range j.targetPIDs {
 		pid := pid
 		group.Submit(func() error {
+			progress.Running(pid)
 			err, _ := j.invoke(job, pid)
+			err = common.ToolError(job.Tool, pid, err)
+			progress.Done(pid, err)
 			return err
 		})
 		// wait a bit between jobs for not overloading the system
@@ -201,17 +221,41 @@ func (j *asyncProfilerManager) invoke(job *job.ProfilingJob, pid string) (error,
 	cmd := asyncProfilerCommand(j, job, pid, resultFileName)
 	cmd.Stdout = &out
 	cmd.Stderr = &stderr
-	err := cmd.Run()
+	err := common.RunTool(cmd)
 	if err != nil {
 		log.ErrorLogLn(out.String())
 		return errors.Wrapf(err, "could not launch profiler: %s", stderr.String()), time.Since(start)
 	}
 	log.DebugLogLn(out.String())
 
+	progress.Publishing(pid)
 	return j.publisher.Do(job.Compressor, resultFileName, job.OutputType), time.Since(start)
 }
 
-func (j *AsyncProfiler) CleanUp(job *job.ProfilingJob) error {
+// Stop writes what was collected so far for each PID to its result file and then ends the running asprof commands,
+// so that the partial results are published
+func (j *AsyncProfiler) Stop(_ context.Context, job *job.ProfilingJob) error {
+	var err error
+	for _, pid := range j.targetPIDs {
+		if e := j.stop(job, pid); e != nil && err == nil {
+			err = e
+		}
+	}
+	common.InterruptTools()
+	return err
+}
+
+func (j *asyncProfilerManager) stop(job *job.ProfilingJob, pid string) error {
+	var stderr bytes.Buffer
+	cmd := asyncProfilerFlushCommand(j, job, pid, common.GetResultFile(j.getTmpDir(), job.Tool, job.OutputType, pid, job.Iteration))
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "could not stop the profiling of PID %s: %s", pid, stderr.String())
+	}
+	return nil
+}
+
+func (j *AsyncProfiler) CleanUp(_ context.Context, job *job.ProfilingJob) error {
 	for _, pid := range j.targetPIDs {
 		j.cleanUp(job, pid)
 	}
@@ -66,3 +66,13 @@ func (m *mockAsyncProfilerManager) invoke(j *job.ProfilingJob, pid string) (erro
 func (m *mockAsyncProfilerManager) cleanUp(j *job.ProfilingJob, pid string) {
 	_ = m.Called(j, pid)
 }
+
+func (m *mockAsyncProfilerManager) stop(j *job.ProfilingJob, pid string) error {
+	args := m.Called(j, pid)
+	if a := args.Get(0); a != nil {
+		if err, ok := a.(error); ok {
+			return err
+		}
+	}
+	return nil
+}
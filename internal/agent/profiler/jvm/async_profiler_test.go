@@ -1,6 +1,7 @@
 package jvm
 
 import (
+	"context"
 	"bytes"
 	"os"
 	"os/exec"
@@ -57,7 +58,7 @@ func TestAsyncProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.AsyncProfiler.SetUp(args.job)
+				return fields.AsyncProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -89,7 +90,7 @@ func TestAsyncProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.AsyncProfiler.SetUp(args.job)
+				return fields.AsyncProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -121,7 +122,7 @@ func TestAsyncProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.AsyncProfiler.SetUp(args.job)
+				return fields.AsyncProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.NotNil(t, err)
@@ -149,7 +150,7 @@ func TestAsyncProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.AsyncProfiler.SetUp(args.job)
+				return fields.AsyncProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.NotNil(t, err)
@@ -179,7 +180,7 @@ func TestAsyncProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.AsyncProfiler.SetUp(args.job)
+				return fields.AsyncProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.NotNil(t, err)
@@ -209,7 +210,7 @@ func TestAsyncProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.AsyncProfiler.SetUp(args.job)
+				return fields.AsyncProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.NotNil(t, err)
@@ -239,7 +240,7 @@ func TestAsyncProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.AsyncProfiler.SetUp(args.job)
+				return fields.AsyncProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.NotNil(t, err)
@@ -299,7 +300,7 @@ func TestAsyncProfiler_Invoke(t *testing.T) {
 			when: func(fields fields, args args) (error, time.Duration) {
 				fields.AsyncProfiler.delay = 0
 				fields.AsyncProfiler.targetPIDs = []string{"1000", "2000"}
-				return fields.AsyncProfiler.Invoke(args.job)
+				return fields.AsyncProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -331,7 +332,7 @@ func TestAsyncProfiler_Invoke(t *testing.T) {
 			when: func(fields fields, args args) (error, time.Duration) {
 				fields.AsyncProfiler.delay = 0
 				fields.AsyncProfiler.targetPIDs = []string{"1000", "2000"}
-				return fields.AsyncProfiler.Invoke(args.job)
+				return fields.AsyncProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				require.Error(t, err)
@@ -401,7 +402,7 @@ func TestAsyncProfiler_CleanUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.AsyncProfiler.CleanUp(args.job)
+				return fields.AsyncProfiler.CleanUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				f := filepath.Join(sharedDir, config.ProfilingPrefix+"flamegraph.html")
@@ -685,3 +686,22 @@ func Test_asyncProfilerCommand_WithAdditionalArguments(t *testing.T) {
 		}
 	})
 }
+
+func Test_asyncProfilerManager_stop(t *testing.T) {
+	profilingJob := &job.ProfilingJob{
+		OutputType: api.FlameGraph,
+		Tool:       api.AsyncProfiler,
+		Iteration:  1,
+	}
+
+	commander := executil.NewMockCommander()
+	commander.On("Command").Return(exec.Command("ls", common.TmpDir()))
+	a := NewAsyncProfiler(commander, publish.NewFakePublisher())
+	assert.NoError(t, a.stop(profilingJob, "1000"))
+	commander.AssertNumberOfCalls(t, "Command", 1)
+
+	commander = executil.NewMockCommander()
+	commander.On("Command").Return(exec.Command("false"))
+	a = NewAsyncProfiler(commander, publish.NewFakePublisher())
+	assert.Error(t, a.stop(profilingJob, "1000"))
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/josepdcs/kubectl-prof/internal/agent/profiler/common"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util"
 	executil "github.com/josepdcs/kubectl-prof/internal/agent/util/exec"
+	"github.com/josepdcs/kubectl-prof/internal/agent/util/progress"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util/publish"
 	"github.com/josepdcs/kubectl-prof/pkg/util/compressor"
 	"github.com/josepdcs/kubectl-prof/pkg/util/file"
@@ -63,6 +64,12 @@ var jcmdStopCommand = func(commander executil.Commander, job *job.ProfilingJob,
 	return commander.Command(jcmd, pid, "JFR.stop", name)
 }
 
+// jcmdDumpCommand writes what the JFR recording of the given PID collected so far to the given file
+var jcmdDumpCommand = func(commander executil.Commander, job *job.ProfilingJob, pid string, fileName string) *exec.Cmd {
+	name := fmt.Sprintf(invocationName, pid, job.Iteration, string(job.OutputType))
+	return commander.Command(jcmd, pid, "JFR.dump", name, "filename="+fileName)
+}
+
 type JcmdProfiler struct {
 	targetPIDs []string
 	delay      time.Duration
@@ -78,6 +85,7 @@ type JcmdManager interface {
 	handleJcmdRecording(targetPID string, iteration int, outputType string)
 	publishResult(compressor compressor.Type, fileName string, outputType api.OutputType, outputSplitInChunkSize string) error
 	cleanUp(*job.ProfilingJob, string)
+	stop(*job.ProfilingJob, string) error
 }
 
 type jcmdManager struct {
@@ -94,7 +102,7 @@ func NewJcmdProfiler(commander executil.Commander, publisher publish.Publisher)
 		}}
 }
 
-func (j *JcmdProfiler) SetUp(job *job.ProfilingJob) error {
+func (j *JcmdProfiler) SetUp(_ context.Context, job *job.ProfilingJob) error {
 	targetFs, err := util.ContainerFileSystem(job.ContainerRuntime, job.ContainerID, job.ContainerRuntimePath)
 	if err != nil {
 		return err
@@ -144,20 +152,23 @@ func (j *jcmdManager) copyJfrSettingsToTmpDir() error {
 	return cmd.Run()
 }
 
-func (j *JcmdProfiler) Invoke(job *job.ProfilingJob) (error, time.Duration) {
+func (j *JcmdProfiler) Invoke(ctx context.Context, job *job.ProfilingJob) (error, time.Duration) {
 	start := time.Now()
 
 	pool := pond.New(len(j.targetPIDs), 0, pond.MinWorkers(len(j.targetPIDs)))
 	defer pool.StopAndWait()
 
 	// create a task group associated to a context
-	group, _ := pool.GroupContext(context.Background())
+	group, _ := pool.GroupContext(ctx)
 
 	// submit tasks to profile
 	for _, pid := range j.targetPIDs {
 		pid := pid
 		group.Submit(func() error {
+			progress.Running(pid)
 			err, _ := j.invoke(job, pid)
+			err = common.ToolError(job.Tool, pid, err)
+			progress.Done(pid, err)
 			return err
 		})
 		// wait a bit between jobs for not overloading the system
@@ -190,6 +201,7 @@ func (j *jcmdManager) invoke(job *job.ProfilingJob, pid string) (error, time.Dur
 		return err, time.Since(start)
 	}
 
+	progress.Publishing(pid)
 	return j.publishResult(job.Compressor, resultFileName, job.OutputType, job.OutputSplitInChunkSize), time.Since(start)
 }
 
@@ -263,7 +275,39 @@ func (j *jcmdManager) publishResult(c compressor.Type, fileName string, outputTy
 	return j.publisher.Do(c, fileName, outputType)
 }
 
-func (j *JcmdProfiler) CleanUp(job *job.ProfilingJob) error {
+// Stop dumps the running JFR recordings to their result files and stops them, so that the partial results are
+// published. The dumps are taken right away, so there is nothing to stop for the other output types.
+func (j *JcmdProfiler) Stop(_ context.Context, job *job.ProfilingJob) error {
+	if job.OutputType != api.Jfr {
+		return nil
+	}
+	var err error
+	for _, pid := range j.targetPIDs {
+		if e := j.stop(job, pid); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func (j *jcmdManager) stop(job *job.ProfilingJob, pid string) error {
+	var stderr bytes.Buffer
+	cmd := jcmdDumpCommand(j.commander, job, pid, common.GetResultFile(common.TmpDir(), job.Tool, job.OutputType, pid, job.Iteration))
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "could not dump the JFR recording of PID %s: %s", pid, stderr.String())
+	}
+	// once stopped, the recording is no longer running, so that the result is published
+	stderr.Reset()
+	cmd = jcmdStopCommand(j.commander, job, pid)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "could not stop the JFR recording of PID %s: %s", pid, stderr.String())
+	}
+	return nil
+}
+
+func (j *JcmdProfiler) CleanUp(_ context.Context, job *job.ProfilingJob) error {
 	if recordingPIDs != nil && job.OutputType == api.Jfr {
 		defer close(recordingPIDs)
 		for _, pid := range j.targetPIDs {
@@ -89,3 +89,13 @@ func (m *mockJcmdManager) publishResult(c compressor.Type, fileName string, outp
 func (m *mockJcmdManager) cleanUp(j *job.ProfilingJob, pid string) {
 	_ = m.Called(j, pid)
 }
+
+func (m *mockJcmdManager) stop(j *job.ProfilingJob, pid string) error {
+	args := m.Called(j, pid)
+	if a := args.Get(0); a != nil {
+		if err, ok := a.(error); ok {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package jvm
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -57,7 +58,7 @@ func TestJcmdProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.JcmdProfiler.SetUp(args.job)
+				return fields.JcmdProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -89,7 +90,7 @@ func TestJcmdProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.JcmdProfiler.SetUp(args.job)
+				return fields.JcmdProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -120,7 +121,7 @@ func TestJcmdProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.JcmdProfiler.SetUp(args.job)
+				return fields.JcmdProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.NotNil(t, err)
@@ -148,7 +149,7 @@ func TestJcmdProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.JcmdProfiler.SetUp(args.job)
+				return fields.JcmdProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.NotNil(t, err)
@@ -178,7 +179,7 @@ func TestJcmdProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.JcmdProfiler.SetUp(args.job)
+				return fields.JcmdProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.NotNil(t, err)
@@ -208,7 +209,7 @@ func TestJcmdProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.JcmdProfiler.SetUp(args.job)
+				return fields.JcmdProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.NotNil(t, err)
@@ -238,7 +239,7 @@ func TestJcmdProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.JcmdProfiler.SetUp(args.job)
+				return fields.JcmdProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.NotNil(t, err)
@@ -299,7 +300,7 @@ func TestJcmdProfiler_Invoke(t *testing.T) {
 			when: func(fields fields, args args) (error, time.Duration) {
 				fields.JcmdProfiler.delay = 0
 				fields.JcmdProfiler.targetPIDs = []string{"1000", "2000"}
-				return fields.JcmdProfiler.Invoke(args.job)
+				return fields.JcmdProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -329,7 +330,7 @@ func TestJcmdProfiler_Invoke(t *testing.T) {
 			when: func(fields fields, args args) (error, time.Duration) {
 				fields.JcmdProfiler.delay = 0
 				fields.JcmdProfiler.targetPIDs = []string{"1000", "2000"}
-				return fields.JcmdProfiler.Invoke(args.job)
+				return fields.JcmdProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				require.Error(t, err)
@@ -395,7 +396,7 @@ func TestJcmdProfiler_CleanUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.JcmdProfiler.CleanUp(args.job)
+				return fields.JcmdProfiler.CleanUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				f := filepath.Join(common.TmpDir(), config.ProfilingPrefix+"jfr.jfr")
@@ -435,7 +436,7 @@ func TestJcmdProfiler_CleanUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.JcmdProfiler.CleanUp(args.job)
+				return fields.JcmdProfiler.CleanUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				f := filepath.Join(common.TmpDir(), config.ProfilingPrefix+"heapdump.hprof")
@@ -579,3 +580,45 @@ func Test_jcmdManager_cleanUp(t *testing.T) {
 	}, "1000")
 	assert.True(t, commander.(*executil.Fake).On("Command").InvokedTimes() == 1)
 }
+
+func TestJcmdProfiler_Stop(t *testing.T) {
+	tests := []struct {
+		name       string
+		outputType api.OutputType
+		stopErr    error
+		wantCalls  int
+		wantErr    bool
+	}{
+		{
+			name:       "should stop the JFR recording of every PID",
+			outputType: api.Jfr,
+			wantCalls:  2,
+		},
+		{
+			name:       "should return the failure stopping a JFR recording",
+			outputType: api.Jfr,
+			stopErr:    errors.New("fake stop error"),
+			wantCalls:  2,
+			wantErr:    true,
+		},
+		{
+			name:       "should not stop anything for a dump",
+			outputType: api.ThreadDump,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			jcmdManager := newMockJcmdManager()
+			jcmdManager.On("stop", mock.Anything, mock.AnythingOfType("string")).Return(tt.stopErr)
+			p := &JcmdProfiler{targetPIDs: []string{"1000", "2000"}, JcmdManager: jcmdManager}
+
+			// When
+			err := p.Stop(context.Background(), &job.ProfilingJob{OutputType: tt.outputType, Tool: api.Jcmd})
+
+			// Then
+			assert.Equal(t, tt.wantErr, err != nil)
+			jcmdManager.AssertNumberOfCalls(t, "stop", tt.wantCalls)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package profiler
+
+import (
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/josepdcs/kubectl-prof/internal/agent/job"
+	"github.com/josepdcs/kubectl-prof/internal/agent/profiler/common"
+	"github.com/josepdcs/kubectl-prof/internal/agent/util/flamegraph"
+	"github.com/josepdcs/kubectl-prof/internal/agent/util/publish"
+	"github.com/josepdcs/kubectl-prof/pkg/util/file"
+	"github.com/pkg/errors"
+)
+
+// nodeResultID is the identifier of the result files when the whole node is profiled
+const nodeResultID = "node"
+
+// flamegraphHandler converts the given raw file to the given flamegraph file when required by the job
+type flamegraphHandler func(*job.ProfilingJob, flamegraph.FrameGrapher, string, string) error
+
+// publishNodeResult merges the raw outputs of the profiled PIDs of the node into a single result and publishes it.
+func publishNodeResult(job *job.ProfilingJob, pids []string, handleFlamegraph flamegraphHandler, publisher publish.Publisher) error {
+	rawFileName := common.GetResultFile(common.TmpDir(), job.Tool, api.Raw, nodeResultID, job.Iteration)
+	resultFileName := common.GetResultFile(common.TmpDir(), job.Tool, job.OutputType, nodeResultID, job.Iteration)
+
+	file.Write(rawFileName, "")
+	for _, pid := range pids {
+		file.Append(rawFileName, file.Read(common.GetResultFile(common.TmpDir(), job.Tool, api.Raw, resultFileID(pid), job.Iteration)))
+	}
+
+	err := handleFlamegraph(job, flamegraph.Get(job), rawFileName, resultFileName)
+	if err != nil {
+		return errors.Wrap(err, "could not generate flamegraph (node)")
+	}
+
+	return publisher.Do(job.Compressor, resultFileName, job.OutputType)
+}
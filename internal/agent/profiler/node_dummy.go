@@ -1,6 +1,7 @@
 package profiler
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"strconv"
@@ -11,6 +12,7 @@ import (
 	"github.com/josepdcs/kubectl-prof/internal/agent/job"
 	"github.com/josepdcs/kubectl-prof/internal/agent/profiler/common"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util"
+	"github.com/josepdcs/kubectl-prof/internal/agent/util/progress"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util/publish"
 	"github.com/josepdcs/kubectl-prof/pkg/util/file"
 	"github.com/josepdcs/kubectl-prof/pkg/util/log"
@@ -44,7 +46,7 @@ func NewNodeDummyProfiler(publisher publish.Publisher) *NodeDummyProfiler {
 	}
 }
 
-func (n *NodeDummyProfiler) SetUp(job *job.ProfilingJob) error {
+func (n *NodeDummyProfiler) SetUp(_ context.Context, job *job.ProfilingJob) error {
 	targetFs, err := util.ContainerFileSystem(job.ContainerRuntime, job.ContainerID, job.ContainerRuntimePath)
 	if err != nil {
 		return err
@@ -63,7 +65,7 @@ func (n *NodeDummyProfiler) SetUp(job *job.ProfilingJob) error {
 	return nil
 }
 
-func (n *NodeDummyProfiler) Invoke(job *job.ProfilingJob) (error, time.Duration) {
+func (n *NodeDummyProfiler) Invoke(_ context.Context, job *job.ProfilingJob) (error, time.Duration) {
 	start := time.Now()
 
 	rootPID, err := util.GetRootPID(job)
@@ -71,7 +73,12 @@ func (n *NodeDummyProfiler) Invoke(job *job.ProfilingJob) (error, time.Duration)
 		return err, time.Since(start)
 	}
 
-	return n.invoke(job, util.GetFirstCandidatePID(rootPID), n.cwd)
+	pid := util.GetFirstCandidatePID(rootPID)
+	progress.Running(pid)
+	err, d := n.invoke(job, pid, n.cwd)
+	err = common.ToolError(job.Tool, pid, err)
+	progress.Done(pid, err)
+	return err, d
 }
 
 var kill = func(pid, sig int) error {
@@ -122,10 +129,15 @@ func (n *nodeDummyManager) invoke(job *job.ProfilingJob, pid, cwd string) (error
 		log.WarningLogLn(fmt.Sprintf("The file could not be removed: %s", fileName))
 	}
 
+	progress.Publishing(pid)
 	return n.publisher.DoWithNativeGzipAndSplit(resultFileName, job.OutputSplitInChunkSize, job.OutputType), time.Since(start)
 }
 
-func (n *NodeDummyProfiler) CleanUp(*job.ProfilingJob) error {
+func (n *NodeDummyProfiler) Stop(context.Context, *job.ProfilingJob) error {
+	return common.ErrStopUnsupported
+}
+
+func (n *NodeDummyProfiler) CleanUp(context.Context, *job.ProfilingJob) error {
 	file.RemoveAll(common.TmpDir(), config.ProfilingPrefix)
 
 	return nil
@@ -1,6 +1,7 @@
 package profiler
 
 import (
+	"context"
 	"bytes"
 	"os"
 	"path/filepath"
@@ -50,7 +51,7 @@ func TestNodeDummyProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.NodeDummyProfiler.SetUp(args.job)
+				return fields.NodeDummyProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -73,7 +74,7 @@ func TestNodeDummyProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.NodeDummyProfiler.SetUp(args.job)
+				return fields.NodeDummyProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.NotNil(t, err)
@@ -96,7 +97,7 @@ func TestNodeDummyProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.NodeDummyProfiler.SetUp(args.job)
+				return fields.NodeDummyProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.NotNil(t, err)
@@ -153,7 +154,7 @@ func TestNodeDummyProfiler_Invoke(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) (error, time.Duration) {
-				return fields.NodeDummyProfiler.Invoke(args.job)
+				return fields.NodeDummyProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -178,7 +179,7 @@ func TestNodeDummyProfiler_Invoke(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) (error, time.Duration) {
-				return fields.NodeDummyProfiler.Invoke(args.job)
+				return fields.NodeDummyProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				require.Error(t, err)
@@ -207,7 +208,7 @@ func TestNodeDummyProfiler_Invoke(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) (error, time.Duration) {
-				return fields.NodeDummyProfiler.Invoke(args.job)
+				return fields.NodeDummyProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				require.Error(t, err)
@@ -262,7 +263,7 @@ func TestNodeDummyProfiler_CleanUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.NodeDummyProfiler.CleanUp(args.job)
+				return fields.NodeDummyProfiler.CleanUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				f := filepath.Join(common.TmpDir(), config.ProfilingPrefix+"flamegraph.svg")
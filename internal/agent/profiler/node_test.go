@@ -0,0 +1,76 @@
+package profiler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/josepdcs/kubectl-prof/internal/agent/config"
+	"github.com/josepdcs/kubectl-prof/internal/agent/job"
+	"github.com/josepdcs/kubectl-prof/internal/agent/profiler/common"
+	"github.com/josepdcs/kubectl-prof/internal/agent/util/flamegraph"
+	"github.com/josepdcs/kubectl-prof/internal/agent/util/publish"
+	"github.com/josepdcs/kubectl-prof/pkg/util/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_publishNodeResult(t *testing.T) {
+	tests := []struct {
+		name             string
+		handleFlamegraph flamegraphHandler
+		publisherErr     error
+		then             func(t *testing.T, err error, publisher publish.FakePublisher)
+	}{
+		{
+			name: "should merge the raw outputs and publish the node result",
+			handleFlamegraph: func(*job.ProfilingJob, flamegraph.FrameGrapher, string, string) error {
+				return nil
+			},
+			then: func(t *testing.T, err error, publisher publish.FakePublisher) {
+				require.NoError(t, err)
+				assert.Equal(t, "ns/a/app;main;run 1\nns/b/app;main;loop 2\n",
+					file.Read(common.GetResultFile(common.TmpDir(), api.Bpf, api.Raw, nodeResultID, 0)))
+				assert.Equal(t, 1, publisher.On("Do").InvokedTimes())
+			},
+		},
+		{
+			name: "should fail without publishing when the flamegraph cannot be generated",
+			handleFlamegraph: func(*job.ProfilingJob, flamegraph.FrameGrapher, string, string) error {
+				return errors.New("no stacks found")
+			},
+			then: func(t *testing.T, err error, publisher publish.FakePublisher) {
+				require.EqualError(t, err, "could not generate flamegraph (node): no stacks found")
+				assert.Equal(t, 0, publisher.On("Do").InvokedTimes())
+			},
+		},
+		{
+			name: "should fail when publisher fails",
+			handleFlamegraph: func(*job.ProfilingJob, flamegraph.FrameGrapher, string, string) error {
+				return nil
+			},
+			publisherErr: errors.New("fake publisher with error"),
+			then: func(t *testing.T, err error, publisher publish.FakePublisher) {
+				require.Error(t, err)
+				assert.EqualError(t, err, "fake publisher with error")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			j := &job.ProfilingJob{Tool: api.Bpf, OutputType: api.FlameGraph}
+			file.Write(common.GetResultFile(common.TmpDir(), api.Bpf, api.Raw, "100", 0), "ns/a/app;main;run 1\n")
+			file.Write(common.GetResultFile(common.TmpDir(), api.Bpf, api.Raw, "200", 0), "ns/b/app;main;loop 2\n")
+			publisher := publish.NewFakePublisher()
+			publisher.On("Do").Return(tt.publisherErr)
+
+			// When
+			err := publishNodeResult(j, []string{"100,101", "200"}, tt.handleFlamegraph, publisher)
+
+			// Then
+			tt.then(t, err, publisher)
+			file.RemoveAll(common.TmpDir(), config.ProfilingPrefix)
+		})
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"strconv"
 	"time"
 
@@ -17,6 +19,7 @@ import (
 	"github.com/josepdcs/kubectl-prof/internal/agent/util"
 	executil "github.com/josepdcs/kubectl-prof/internal/agent/util/exec"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util/flamegraph"
+	"github.com/josepdcs/kubectl-prof/internal/agent/util/progress"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util/publish"
 	"github.com/josepdcs/kubectl-prof/pkg/util/file"
 	"github.com/josepdcs/kubectl-prof/pkg/util/log"
@@ -43,6 +46,7 @@ type PerfManager interface {
 	runPerfScript(job *job.ProfilingJob, pid string) error
 	foldPerfOutput(job *job.ProfilingJob, pid string) (error, string)
 	handleFlamegraph(*job.ProfilingJob, flamegraph.FrameGrapher, string, string) error
+	publishNodeResult(*job.ProfilingJob, []string) error
 }
 
 type perfManager struct {
@@ -60,7 +64,16 @@ func NewPerfProfiler(commander executil.Commander, publisher publish.Publisher)
 	}
 }
 
-func (p *PerfProfiler) SetUp(job *job.ProfilingJob) error {
+func (p *PerfProfiler) SetUp(_ context.Context, job *job.ProfilingJob) error {
+	if job.NodeWide {
+		targets, err := util.GetNodeTargets(job)
+		if err != nil {
+			return err
+		}
+		job.ProcessLegends = targets
+		p.targetPIDs = slices.Sorted(maps.Keys(targets))
+		return nil
+	}
 	if stringUtils.IsNotBlank(job.PID) {
 		p.targetPIDs = []string{job.PID}
 		return nil
@@ -75,20 +88,23 @@ func (p *PerfProfiler) SetUp(job *job.ProfilingJob) error {
 	return nil
 }
 
-func (p *PerfProfiler) Invoke(job *job.ProfilingJob) (error, time.Duration) {
+func (p *PerfProfiler) Invoke(ctx context.Context, job *job.ProfilingJob) (error, time.Duration) {
 	start := time.Now()
 
 	pool := pond.New(len(p.targetPIDs), 0, pond.MinWorkers(len(p.targetPIDs)))
 	defer pool.StopAndWait()
 
 	// create a task group associated to a context
-	group, _ := pool.GroupContext(context.Background())
+	group, _ := pool.GroupContext(ctx)
 
 	// submit tasks to profile
 	for _, pid := range p.targetPIDs {
 		pid := pid
 		group.Submit(func() error {
+			progress.Running(pid)
 			err, _ := p.invoke(job, pid)
+			err = common.ToolError(job.Tool, pid, err)
+			progress.Done(pid, err)
 			return err
 		})
 		// wait a bit between jobs for not overloading the system
@@ -97,6 +113,9 @@ func (p *PerfProfiler) Invoke(job *job.ProfilingJob) (error, time.Duration) {
 
 	// wait for all tasks to finish
 	err := group.Wait()
+	if err == nil && job.NodeWide {
+		err = p.publishNodeResult(job, p.targetPIDs)
+	}
 
 	return err, time.Since(start)
 }
@@ -118,8 +137,13 @@ func (m *perfManager) invoke(job *job.ProfilingJob, pid string) (error, time.Dur
 		return errors.Wrap(err, "folding perf output failed"), time.Since(start)
 	}
 
+	if job.NodeWide {
+		// the result of the whole node is published once all its processes have been profiled
+		return nil, time.Since(start)
+	}
+
 	// out file names is composed by the job info and the pid
-	resultFileName := common.GetResultFile(common.TmpDir(), job.Tool, job.OutputType, pid, job.Iteration)
+	resultFileName := common.GetResultFile(common.TmpDir(), job.Tool, job.OutputType, resultFileID(pid), job.Iteration)
 
 	err = m.handleFlamegraph(job, flamegraph.Get(job), fileName, resultFileName)
 	if err != nil {
@@ -127,6 +151,7 @@ func (m *perfManager) invoke(job *job.ProfilingJob, pid string) (error, time.Dur
 		return nil, time.Since(start)
 	}
 
+	progress.Publishing(pid)
 	return m.publisher.Do(job.Compressor, resultFileName, job.OutputType), time.Since(start)
 }
 
@@ -134,10 +159,10 @@ func (m *perfManager) runPerfRecord(job *job.ProfilingJob, pid string) error {
 	interval := strconv.Itoa(int(job.Interval.Seconds()))
 	var stderr bytes.Buffer
 	// perf record -F 997 --call-graph dwarf,64000 -g -o perf.data -p 1683198
-	cmd := m.commander.Command(perfLocation, "record", "--call-graph", "dwarf,64000", "-p", pid, "-o", fmt.Sprintf(perfRecordOutputFileName, pid, job.Iteration), "-g", "--", "sleep", interval)
+	cmd := m.commander.Command(perfLocation, "record", "--call-graph", "dwarf,64000", "-p", pid, "-o", fmt.Sprintf(perfRecordOutputFileName, resultFileID(pid), job.Iteration), "-g", "--", "sleep", interval)
 	cmd.Stderr = &stderr
 
-	err := cmd.Run()
+	err := common.RunTool(cmd)
 	if err != nil {
 		log.ErrorLogLn(stderr.String())
 	}
@@ -145,7 +170,7 @@ func (m *perfManager) runPerfRecord(job *job.ProfilingJob, pid string) error {
 }
 
 func (m *perfManager) runPerfScript(job *job.ProfilingJob, pid string) error {
-	f, err := os.Create(fmt.Sprintf(perfScriptOutputFileName, pid, job.Iteration))
+	f, err := os.Create(fmt.Sprintf(perfScriptOutputFileName, resultFileID(pid), job.Iteration))
 	if err != nil {
 		return err
 	}
@@ -158,7 +183,7 @@ func (m *perfManager) runPerfScript(job *job.ProfilingJob, pid string) error {
 	}(f)
 
 	var stderr bytes.Buffer
-	cmd := m.commander.Command(perfLocation, "script", "-i", fmt.Sprintf(perfRecordOutputFileName, pid, job.Iteration))
+	cmd := m.commander.Command(perfLocation, "script", "-i", fmt.Sprintf(perfRecordOutputFileName, resultFileID(pid), job.Iteration))
 	cmd.Stdout = f
 	cmd.Stderr = &stderr
 
@@ -173,7 +198,7 @@ func (m *perfManager) foldPerfOutput(job *job.ProfilingJob, pid string) (error,
 	var out bytes.Buffer
 	var stderr bytes.Buffer
 
-	cmd := m.commander.Command(flameGraphStackCollapseLocation, fmt.Sprintf(perfScriptOutputFileName, pid, job.Iteration))
+	cmd := m.commander.Command(flameGraphStackCollapseLocation, fmt.Sprintf(perfScriptOutputFileName, resultFileID(pid), job.Iteration))
 	cmd.Stdout = &out
 	cmd.Stderr = &stderr
 
@@ -184,18 +209,22 @@ func (m *perfManager) foldPerfOutput(job *job.ProfilingJob, pid string) (error,
 	}
 
 	// out file name is composed by the job info and the pid
-	fileName := common.GetResultFile(common.TmpDir(), job.Tool, api.Raw, pid, job.Iteration)
-	// add process pid legend to each line of the output and write it to the file
-	file.Write(fileName, addProcessPIDLegend(out.String(), pid))
+	fileName := common.GetResultFile(common.TmpDir(), job.Tool, api.Raw, resultFileID(pid), job.Iteration)
+	// add process pid (or pod, if the whole node is profiled) legend to each line of the output and write it to the file
+	file.Write(fileName, addJobLegend(out.String(), job, pid))
 
 	return err, fileName
 }
 
+func (m *perfManager) publishNodeResult(job *job.ProfilingJob, pids []string) error {
+	return publishNodeResult(job, pids, m.handleFlamegraph, m.publisher)
+}
+
 func (m *perfManager) handleFlamegraph(job *job.ProfilingJob, flameGrapher flamegraph.FrameGrapher, rawFileName string,
 	flameFileName string) error {
 	if job.OutputType == api.FlameGraph {
 		if file.Size(rawFileName) < common.MinimumRawSize {
-			return fmt.Errorf("unable to generate flamegraph: no stacks found (maybe due low cpu load)")
+			return common.ErrNoSamples
 		}
 		// convert raw format to flamegraph
 		err := flameGrapher.StackSamplesToFlameGraph(rawFileName, flameFileName)
@@ -206,7 +235,13 @@ func (m *perfManager) handleFlamegraph(job *job.ProfilingJob, flameGrapher flame
 	return nil
 }
 
-func (p *PerfProfiler) CleanUp(job *job.ProfilingJob) error {
+// Stop interrupts the running perf record, which ends its capture early, so that the partial results are published
+func (p *PerfProfiler) Stop(context.Context, *job.ProfilingJob) error {
+	common.InterruptTools()
+	return nil
+}
+
+func (p *PerfProfiler) CleanUp(_ context.Context, job *job.ProfilingJob) error {
 	file.RemoveAll(common.TmpDir(), "perf")
 	file.RemoveAll(common.TmpDir(), config.ProfilingPrefix+string(job.OutputType))
 
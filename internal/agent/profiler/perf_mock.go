@@ -72,3 +72,13 @@ func (m *mockPerfManager) handleFlamegraph(j *job.ProfilingJob, fg flamegraph.Fr
 	}
 	return nil
 }
+
+func (m *mockPerfManager) publishNodeResult(j *job.ProfilingJob, pids []string) error {
+	args := m.Called(j, pids)
+	if a := args.Get(0); a != nil {
+		if err, ok := a.(error); ok {
+			return err
+		}
+	}
+	return nil
+}
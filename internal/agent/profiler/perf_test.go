@@ -1,6 +1,7 @@
 package profiler
 
 import (
+	"context"
 	"bytes"
 	"os"
 	"os/exec"
@@ -53,7 +54,7 @@ func TestPerfProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.PerfProfiler.SetUp(args.job)
+				return fields.PerfProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -77,7 +78,7 @@ func TestPerfProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.PerfProfiler.SetUp(args.job)
+				return fields.PerfProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -100,7 +101,7 @@ func TestPerfProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.PerfProfiler.SetUp(args.job)
+				return fields.PerfProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.NotNil(t, err)
@@ -159,7 +160,7 @@ func TestPerfProfiler_Invoke(t *testing.T) {
 			when: func(fields fields, args args) (error, time.Duration) {
 				fields.PerfProfiler.delay = 0
 				fields.PerfProfiler.targetPIDs = []string{"1000", "2000"}
-				return fields.PerfProfiler.Invoke(args.job)
+				return fields.PerfProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -190,7 +191,7 @@ func TestPerfProfiler_Invoke(t *testing.T) {
 			when: func(fields fields, args args) (error, time.Duration) {
 				fields.PerfProfiler.delay = 0
 				fields.PerfProfiler.targetPIDs = []string{"1000", "2000"}
-				return fields.PerfProfiler.Invoke(args.job)
+				return fields.PerfProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				require.Error(t, err)
@@ -245,7 +246,7 @@ func TestPerfProfiler_CleanUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.PerfProfiler.CleanUp(args.job)
+				return fields.PerfProfiler.CleanUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				f := filepath.Join(common.TmpDir(), config.ProfilingPrefix+"flamegraph.svg")
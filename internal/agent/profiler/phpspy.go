@@ -17,6 +17,7 @@ import (
 	"github.com/josepdcs/kubectl-prof/internal/agent/util"
 	executil "github.com/josepdcs/kubectl-prof/internal/agent/util/exec"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util/flamegraph"
+	"github.com/josepdcs/kubectl-prof/internal/agent/util/progress"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util/publish"
 	"github.com/josepdcs/kubectl-prof/pkg/util/file"
 	"github.com/josepdcs/kubectl-prof/pkg/util/log"
@@ -69,7 +70,7 @@ func NewPhpspyProfiler(commander executil.Commander, publisher publish.Publisher
 	}
 }
 
-func (p *PhpspyProfiler) SetUp(job *job.ProfilingJob) error {
+func (p *PhpspyProfiler) SetUp(_ context.Context, job *job.ProfilingJob) error {
 	if stringUtils.IsNotBlank(job.PID) {
 		p.targetPIDs = []string{job.PID}
 		return nil
@@ -84,20 +85,23 @@ func (p *PhpspyProfiler) SetUp(job *job.ProfilingJob) error {
 	return nil
 }
 
-func (p *PhpspyProfiler) Invoke(job *job.ProfilingJob) (error, time.Duration) {
+func (p *PhpspyProfiler) Invoke(ctx context.Context, job *job.ProfilingJob) (error, time.Duration) {
 	start := time.Now()
 
 	pool := pond.New(len(p.targetPIDs), 0, pond.MinWorkers(len(p.targetPIDs)))
 	defer pool.StopAndWait()
 
 	// create a task group associated to a context
-	group, _ := pool.GroupContext(context.Background())
+	group, _ := pool.GroupContext(ctx)
 
 	// submit tasks to profile
 	for _, pid := range p.targetPIDs {
 		pid := pid
 		group.Submit(func() error {
+			progress.Running(pid)
 			err, _ := p.invoke(job, pid)
+			err = common.ToolError(job.Tool, pid, err)
+			progress.Done(pid, err)
 			return err
 		})
 		// wait a bit between jobs for not overloading the system
@@ -137,6 +141,7 @@ func (p *phpspyManager) invoke(job *job.ProfilingJob, pid string) (error, time.D
 		return nil, time.Since(start)
 	}
 
+	progress.Publishing(pid)
 	return p.publisher.Do(job.Compressor, resultFileName, job.OutputType), time.Since(start)
 }
 
@@ -165,7 +170,7 @@ func (p *phpspyManager) handleFlamegraph(job *job.ProfilingJob, flameGrapher fla
 	rawFileName string, flameFileName string) error {
 	if job.OutputType == api.FlameGraph {
 		if file.IsEmpty(rawFileName) {
-			return errors.New("unable to generate flamegraph: no stacks found (maybe due low cpu load)")
+			return common.ErrNoSamples
 		}
 		// convert raw format to flamegraph
 		err := flameGrapher.StackSamplesToFlameGraph(rawFileName, flameFileName)
@@ -176,7 +181,11 @@ func (p *phpspyManager) handleFlamegraph(job *job.ProfilingJob, flameGrapher fla
 	return nil
 }
 
-func (p *PhpspyProfiler) CleanUp(*job.ProfilingJob) error {
+func (p *PhpspyProfiler) Stop(context.Context, *job.ProfilingJob) error {
+	return common.ErrStopUnsupported
+}
+
+func (p *PhpspyProfiler) CleanUp(context.Context, *job.ProfilingJob) error {
 	file.RemoveAll(common.TmpDir(), "phpspy")
 	file.RemoveAll(common.TmpDir(), config.ProfilingPrefix)
 
@@ -1,6 +1,7 @@
 package profiler
 
 import (
+	"context"
 	"bytes"
 	"fmt"
 	"os"
@@ -54,7 +55,7 @@ func TestPhpspyProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.PhpspyProfiler.SetUp(args.job)
+				return fields.PhpspyProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -78,7 +79,7 @@ func TestPhpspyProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.PhpspyProfiler.SetUp(args.job)
+				return fields.PhpspyProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -101,7 +102,7 @@ func TestPhpspyProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.PhpspyProfiler.SetUp(args.job)
+				return fields.PhpspyProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.NotNil(t, err)
@@ -161,7 +162,7 @@ func TestPhpspyProfiler_Invoke(t *testing.T) {
 			when: func(fields fields, args args) (error, time.Duration) {
 				fields.PhpspyProfiler.delay = 0
 				fields.PhpspyProfiler.targetPIDs = []string{"1000", "2000"}
-				return fields.PhpspyProfiler.Invoke(args.job)
+				return fields.PhpspyProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -192,7 +193,7 @@ func TestPhpspyProfiler_Invoke(t *testing.T) {
 			when: func(fields fields, args args) (error, time.Duration) {
 				fields.PhpspyProfiler.delay = 0
 				fields.PhpspyProfiler.targetPIDs = []string{"1000", "2000"}
-				return fields.PhpspyProfiler.Invoke(args.job)
+				return fields.PhpspyProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				require.Error(t, err)
@@ -247,7 +248,7 @@ func TestPhpspyProfiler_CleanUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.PhpspyProfiler.CleanUp(args.job)
+				return fields.PhpspyProfiler.CleanUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				f := filepath.Join(common.TmpDir(), config.ProfilingPrefix+"flamegraph.svg")
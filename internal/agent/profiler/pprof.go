@@ -1,6 +1,7 @@
 package profiler
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -62,7 +63,7 @@ func NewPprofProfiler(publisher publish.Publisher) *PprofProfiler {
 	}
 }
 
-func (p *PprofProfiler) SetUp(job *job.ProfilingJob) error {
+func (p *PprofProfiler) SetUp(_ context.Context, job *job.ProfilingJob) error {
 	host := getPprofHost(job)
 	if host == "" {
 		return errors.New("pprof host is required: set the pprof-host additional argument with the target pod IP")
@@ -71,8 +72,9 @@ func (p *PprofProfiler) SetUp(job *job.ProfilingJob) error {
 	return nil
 }
 
-func (p *PprofProfiler) Invoke(job *job.ProfilingJob) (error, time.Duration) {
-	return p.invoke(job)
+func (p *PprofProfiler) Invoke(_ context.Context, job *job.ProfilingJob) (error, time.Duration) {
+	err, d := p.invoke(job)
+	return common.ToolError(job.Tool, "", err), d
 }
 
 func (m *pprofManager) invoke(job *job.ProfilingJob) (error, time.Duration) {
@@ -129,7 +131,11 @@ func (m *pprofManager) invoke(job *job.ProfilingJob) (error, time.Duration) {
 	return m.publisher.Do(job.Compressor, resultFileName, job.OutputType), time.Since(start)
 }
 
-func (p *PprofProfiler) CleanUp(*job.ProfilingJob) error {
+func (p *PprofProfiler) Stop(context.Context, *job.ProfilingJob) error {
+	return common.ErrStopUnsupported
+}
+
+func (p *PprofProfiler) CleanUp(context.Context, *job.ProfilingJob) error {
 	file.RemoveAll(common.TmpDir(), config.ProfilingPrefix)
 	return nil
 }
@@ -1,6 +1,7 @@
 package profiler
 
 import (
+	"context"
 	"bytes"
 	"errors"
 	"io"
@@ -61,7 +62,7 @@ func TestPprofProfiler_SetUp(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			profiler := NewPprofProfiler(nil)
-			err := profiler.SetUp(tt.job)
+			err := profiler.SetUp(context.Background(), tt.job)
 			if tt.wantErr {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tt.errMsg)
@@ -101,7 +102,7 @@ func TestPprofProfiler_Invoke(t *testing.T) {
 				return fields{PprofProfiler: &PprofProfiler{PprofManager: mgr}}, args{job: j}
 			},
 			when: func(fields fields, args args) (error, time.Duration) {
-				return fields.PprofProfiler.Invoke(args.job)
+				return fields.PprofProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, d time.Duration) {
 				assert.NoError(t, err)
@@ -123,7 +124,7 @@ func TestPprofProfiler_Invoke(t *testing.T) {
 				return fields{PprofProfiler: &PprofProfiler{PprofManager: mgr}}, args{job: j}
 			},
 			when: func(fields fields, args args) (error, time.Duration) {
-				return fields.PprofProfiler.Invoke(args.job)
+				return fields.PprofProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, d time.Duration) {
 				assert.Error(t, err)
@@ -281,7 +282,7 @@ func TestPprofManager_invoke(t *testing.T) {
 
 func TestPprofProfiler_CleanUp(t *testing.T) {
 	p := NewPprofProfiler(nil)
-	err := p.CleanUp(&job.ProfilingJob{})
+	err := p.CleanUp(context.Background(), &job.ProfilingJob{})
 	assert.NoError(t, err)
 }
 
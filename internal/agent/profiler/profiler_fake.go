@@ -1,6 +1,7 @@
 package profiler
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -12,12 +13,14 @@ type MockProfiler interface {
 	Profiler
 	SetUpInvokedTimes() int
 	InvokeInvokedTimes() int
+	StopInvokedTimes() int
 	CleanUpInvokedTimes() int
 }
 
 type DefaultMockProfiler struct {
 	setUpInvokedTimes   int
 	invokeInvokedTimes  int
+	stopInvokedTimes    int
 	cleanUpInvokedTimes int
 }
 
@@ -25,7 +28,7 @@ func NewMockProfiler() *DefaultMockProfiler {
 	return &DefaultMockProfiler{}
 }
 
-func (m *DefaultMockProfiler) SetUp(job *job.ProfilingJob) error {
+func (m *DefaultMockProfiler) SetUp(_ context.Context, job *job.ProfilingJob) error {
 	m.setUpInvokedTimes++
 	if job.ContainerID == "WithSetupError" {
 		return errors.New("fake SetUp with error")
@@ -34,7 +37,7 @@ func (m *DefaultMockProfiler) SetUp(job *job.ProfilingJob) error {
 	return nil
 }
 
-func (m *DefaultMockProfiler) Invoke(job *job.ProfilingJob) (error, time.Duration) {
+func (m *DefaultMockProfiler) Invoke(_ context.Context, job *job.ProfilingJob) (error, time.Duration) {
 	start := time.Now()
 	m.invokeInvokedTimes++
 	if job.ContainerID == "WithInvokeError" {
@@ -44,7 +47,13 @@ func (m *DefaultMockProfiler) Invoke(job *job.ProfilingJob) (error, time.Duratio
 	return nil, time.Since(start)
 }
 
-func (m *DefaultMockProfiler) CleanUp(*job.ProfilingJob) error {
+func (m *DefaultMockProfiler) Stop(context.Context, *job.ProfilingJob) error {
+	m.stopInvokedTimes++
+	fmt.Println("fake Stop")
+	return nil
+}
+
+func (m *DefaultMockProfiler) CleanUp(context.Context, *job.ProfilingJob) error {
 	m.cleanUpInvokedTimes++
 	fmt.Println("fake CleanUp")
 	return nil
@@ -58,6 +67,10 @@ func (m *DefaultMockProfiler) InvokeInvokedTimes() int {
 	return m.invokeInvokedTimes
 }
 
+func (m *DefaultMockProfiler) StopInvokedTimes() int {
+	return m.stopInvokedTimes
+}
+
 func (m *DefaultMockProfiler) CleanUpInvokedTimes() int {
 	return m.cleanUpInvokedTimes
 }
@@ -17,6 +17,7 @@ import (
 	"github.com/josepdcs/kubectl-prof/internal/agent/util"
 	executil "github.com/josepdcs/kubectl-prof/internal/agent/util/exec"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util/flamegraph"
+	"github.com/josepdcs/kubectl-prof/internal/agent/util/progress"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util/publish"
 	"github.com/josepdcs/kubectl-prof/pkg/util/file"
 	"github.com/josepdcs/kubectl-prof/pkg/util/log"
@@ -75,7 +76,7 @@ func NewPythonProfiler(commander executil.Commander, publisher publish.Publisher
 	}
 }
 
-func (p *PythonProfiler) SetUp(job *job.ProfilingJob) error {
+func (p *PythonProfiler) SetUp(_ context.Context, job *job.ProfilingJob) error {
 	if stringUtils.IsNotBlank(job.PID) {
 		p.targetPIDs = []string{job.PID}
 		return nil
@@ -90,20 +91,23 @@ func (p *PythonProfiler) SetUp(job *job.ProfilingJob) error {
 	return nil
 }
 
-func (p *PythonProfiler) Invoke(job *job.ProfilingJob) (error, time.Duration) {
+func (p *PythonProfiler) Invoke(ctx context.Context, job *job.ProfilingJob) (error, time.Duration) {
 	start := time.Now()
 
 	pool := pond.New(len(p.targetPIDs), 0, pond.MinWorkers(len(p.targetPIDs)))
 	defer pool.StopAndWait()
 
 	// create a task group associated to a context
-	group, _ := pool.GroupContext(context.Background())
+	group, _ := pool.GroupContext(ctx)
 
 	// submit tasks to profile
 	for _, pid := range p.targetPIDs {
 		pid := pid
 		group.Submit(func() error {
+			progress.Running(pid)
 			err, _ := p.invoke(job, pid)
+			err = common.ToolError(job.Tool, pid, err)
+			progress.Done(pid, err)
 			return err
 		})
 		// wait a bit between jobs for not overloading the system
@@ -129,7 +133,7 @@ func (p *pythonManager) invoke(job *job.ProfilingJob, pid string) (error, time.D
 	cmd := pythonCommand(p.commander, job, pid, fileName)
 	cmd.Stdout = &out
 	cmd.Stderr = &stderr
-	err := cmd.Run()
+	err := common.RunTool(cmd)
 	if err != nil {
 		log.ErrorLogLn(out.String())
 		return errors.Wrapf(err, "could not launch profiler: %s", stderr.String()), time.Since(start)
@@ -147,6 +151,7 @@ func (p *pythonManager) invoke(job *job.ProfilingJob, pid string) (error, time.D
 		}
 	}
 
+	progress.Publishing(pid)
 	return p.publisher.Do(job.Compressor, resultFileName, job.OutputType), time.Since(start)
 }
 
@@ -154,7 +159,7 @@ func (p *pythonManager) handleFlamegraph(job *job.ProfilingJob, flameGrapher fla
 	rawFileName string, flameFileName string) error {
 	if job.OutputType == api.FlameGraph {
 		if file.IsEmpty(rawFileName) {
-			return errors.New("unable to generate flamegraph: no stacks found (maybe due low cpu load)")
+			return common.ErrNoSamples
 		}
 		// convert raw format to flamegraph
 		err := flameGrapher.StackSamplesToFlameGraph(rawFileName, flameFileName)
@@ -165,7 +170,13 @@ func (p *pythonManager) handleFlamegraph(job *job.ProfilingJob, flameGrapher fla
 	return nil
 }
 
-func (p *PythonProfiler) CleanUp(*job.ProfilingJob) error {
+// Stop interrupts the running py-spy, which ends its capture early, so that the partial results are published
+func (p *PythonProfiler) Stop(context.Context, *job.ProfilingJob) error {
+	common.InterruptTools()
+	return nil
+}
+
+func (p *PythonProfiler) CleanUp(context.Context, *job.ProfilingJob) error {
 	file.RemoveAll(common.TmpDir(), config.ProfilingPrefix)
 
 	return nil
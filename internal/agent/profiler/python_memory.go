@@ -21,6 +21,7 @@ import (
 	"github.com/josepdcs/kubectl-prof/internal/agent/profiler/common"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util"
 	executil "github.com/josepdcs/kubectl-prof/internal/agent/util/exec"
+	"github.com/josepdcs/kubectl-prof/internal/agent/util/progress"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util/publish"
 	"github.com/josepdcs/kubectl-prof/pkg/util/file"
 	"github.com/josepdcs/kubectl-prof/pkg/util/log"
@@ -246,7 +247,7 @@ func NewMemrayProfiler(commander executil.Commander, publisher publish.Publisher
 	}
 }
 
-func (p *MemrayProfiler) SetUp(job *job.ProfilingJob) error {
+func (p *MemrayProfiler) SetUp(_ context.Context, job *job.ProfilingJob) error {
 	if stringUtils.IsNotBlank(job.PID) {
 		p.targetPIDs = []string{job.PID}
 		return nil
@@ -261,20 +262,23 @@ func (p *MemrayProfiler) SetUp(job *job.ProfilingJob) error {
 	return nil
 }
 
-func (p *MemrayProfiler) Invoke(job *job.ProfilingJob) (error, time.Duration) {
+func (p *MemrayProfiler) Invoke(ctx context.Context, job *job.ProfilingJob) (error, time.Duration) {
 	start := time.Now()
 
 	pool := pond.New(len(p.targetPIDs), 0, pond.MinWorkers(len(p.targetPIDs)))
 	defer pool.StopAndWait()
 
 	// create a task group associated to a context
-	group, _ := pool.GroupContext(context.Background())
+	group, _ := pool.GroupContext(ctx)
 
 	// submit tasks to profile
 	for _, pid := range p.targetPIDs {
 		pid := pid
 		group.Submit(func() error {
+			progress.Running(pid)
 			err, _ := p.invoke(job, pid)
+			err = common.ToolError(job.Tool, pid, err)
+			progress.Done(pid, err)
 			return err
 		})
 		// wait a bit between jobs for not overloading the system
@@ -389,6 +393,7 @@ func (p *memrayManager) invoke(job *job.ProfilingJob, pid string) (error, time.D
 	}
 	_ = file.Remove(rawFileInTarget)
 
+	progress.Publishing(pid)
 	return p.publisher.Do(job.Compressor, resultFileName, job.OutputType), time.Since(start)
 }
 
@@ -452,7 +457,11 @@ var handleReport = func(commander executil.Commander, job *job.ProfilingJob, pid
 	}
 }
 
-func (p *MemrayProfiler) CleanUp(*job.ProfilingJob) error {
+func (p *MemrayProfiler) Stop(context.Context, *job.ProfilingJob) error {
+	return common.ErrStopUnsupported
+}
+
+func (p *MemrayProfiler) CleanUp(context.Context, *job.ProfilingJob) error {
 	file.RemoveAll(common.TmpDir(), config.ProfilingPrefix)
 
 	return nil
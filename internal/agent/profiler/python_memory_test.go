@@ -1,6 +1,7 @@
 package profiler
 
 import (
+	"context"
 	"bytes"
 	"fmt"
 	"os"
@@ -146,7 +147,7 @@ func TestMemrayProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.MemrayProfiler.SetUp(args.job)
+				return fields.MemrayProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -170,7 +171,7 @@ func TestMemrayProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.MemrayProfiler.SetUp(args.job)
+				return fields.MemrayProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -193,7 +194,7 @@ func TestMemrayProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.MemrayProfiler.SetUp(args.job)
+				return fields.MemrayProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.NotNil(t, err)
@@ -253,7 +254,7 @@ func TestMemrayProfiler_Invoke(t *testing.T) {
 			when: func(fields fields, args args) (error, time.Duration) {
 				fields.MemrayProfiler.delay = 0
 				fields.MemrayProfiler.targetPIDs = []string{"1000", "2000"}
-				return fields.MemrayProfiler.Invoke(args.job)
+				return fields.MemrayProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -284,7 +285,7 @@ func TestMemrayProfiler_Invoke(t *testing.T) {
 			when: func(fields fields, args args) (error, time.Duration) {
 				fields.MemrayProfiler.delay = 0
 				fields.MemrayProfiler.targetPIDs = []string{"1000"}
-				return fields.MemrayProfiler.Invoke(args.job)
+				return fields.MemrayProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				require.Error(t, err)
@@ -340,7 +341,7 @@ func TestMemrayProfiler_CleanUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.MemrayProfiler.CleanUp(args.job)
+				return fields.MemrayProfiler.CleanUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				f := filepath.Join(common.TmpDir(), config.ProfilingPrefix+"flamegraph.html")
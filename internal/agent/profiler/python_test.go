@@ -1,6 +1,7 @@
 package profiler
 
 import (
+	"context"
 	"bytes"
 	"os"
 	"os/exec"
@@ -53,7 +54,7 @@ func TestPythonProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.PythonProfiler.SetUp(args.job)
+				return fields.PythonProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -77,7 +78,7 @@ func TestPythonProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.PythonProfiler.SetUp(args.job)
+				return fields.PythonProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -100,7 +101,7 @@ func TestPythonProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.PythonProfiler.SetUp(args.job)
+				return fields.PythonProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.NotNil(t, err)
@@ -160,7 +161,7 @@ func TestPythonProfiler_Invoke(t *testing.T) {
 			when: func(fields fields, args args) (error, time.Duration) {
 				fields.PythonProfiler.delay = 0
 				fields.PythonProfiler.targetPIDs = []string{"1000", "2000"}
-				return fields.PythonProfiler.Invoke(args.job)
+				return fields.PythonProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -191,7 +192,7 @@ func TestPythonProfiler_Invoke(t *testing.T) {
 			when: func(fields fields, args args) (error, time.Duration) {
 				fields.PythonProfiler.delay = 0
 				fields.PythonProfiler.targetPIDs = []string{"1000", "2000"}
-				return fields.PythonProfiler.Invoke(args.job)
+				return fields.PythonProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				require.Error(t, err)
@@ -246,7 +247,7 @@ func TestPythonProfiler_CleanUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.PythonProfiler.CleanUp(args.job)
+				return fields.PythonProfiler.CleanUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				f := filepath.Join(common.TmpDir(), config.ProfilingPrefix+"flamegraph.svg")
@@ -15,6 +15,7 @@ import (
 	"github.com/josepdcs/kubectl-prof/internal/agent/profiler/common"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util"
 	executil "github.com/josepdcs/kubectl-prof/internal/agent/util/exec"
+	"github.com/josepdcs/kubectl-prof/internal/agent/util/progress"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util/publish"
 	"github.com/josepdcs/kubectl-prof/pkg/util/file"
 	"github.com/josepdcs/kubectl-prof/pkg/util/log"
@@ -61,7 +62,7 @@ func NewRubyProfiler(commander executil.Commander, publisher publish.Publisher)
 	}
 }
 
-func (r *RubyProfiler) SetUp(job *job.ProfilingJob) error {
+func (r *RubyProfiler) SetUp(_ context.Context, job *job.ProfilingJob) error {
 	if stringUtils.IsNotBlank(job.PID) {
 		r.targetPIDs = []string{job.PID}
 		return nil
@@ -76,7 +77,7 @@ func (r *RubyProfiler) SetUp(job *job.ProfilingJob) error {
 	return nil
 }
 
-func (r *RubyProfiler) Invoke(job *job.ProfilingJob) (error, time.Duration) {
+func (r *RubyProfiler) Invoke(ctx context.Context, job *job.ProfilingJob) (error, time.Duration) {
 	start := time.Now()
 
 	// create a pool of workers
@@ -84,13 +85,16 @@ func (r *RubyProfiler) Invoke(job *job.ProfilingJob) (error, time.Duration) {
 	defer pool.StopAndWait()
 
 	// create a task group associated to a context
-	group, _ := pool.GroupContext(context.Background())
+	group, _ := pool.GroupContext(ctx)
 
 	// submit tasks to profile
 	for _, pid := range r.targetPIDs {
 		pid := pid
 		group.Submit(func() error {
+			progress.Running(pid)
 			err, _ := r.invoke(job, pid)
+			err = common.ToolError(job.Tool, pid, err)
+			progress.Done(pid, err)
 			return err
 		})
 		// wait a bit between jobs for not overloading the system
@@ -113,16 +117,23 @@ func (p *rubyManager) invoke(job *job.ProfilingJob, pid string) (error, time.Dur
 	cmd := rubyCommand(p.commander, job, pid, fileName)
 	cmd.Stdout = &out
 	cmd.Stderr = &stderr
-	err := cmd.Run()
+	err := common.RunTool(cmd)
 	if err != nil {
 		log.ErrorLogLn(out.String())
 		return errors.Wrapf(err, "could not launch profiler: %s", stderr.String()), time.Since(start)
 	}
 
+	progress.Publishing(pid)
 	return p.publisher.Do(job.Compressor, fileName, job.OutputType), time.Since(start)
 }
 
-func (r *RubyProfiler) CleanUp(job *job.ProfilingJob) error {
+// Stop interrupts the running rbspy, which ends its capture early, so that the partial results are published
+func (r *RubyProfiler) Stop(context.Context, *job.ProfilingJob) error {
+	common.InterruptTools()
+	return nil
+}
+
+func (r *RubyProfiler) CleanUp(_ context.Context, job *job.ProfilingJob) error {
 	file.RemoveAll(common.TmpDir(), config.ProfilingPrefix)
 
 	return nil
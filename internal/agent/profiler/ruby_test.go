@@ -1,6 +1,7 @@
 package profiler
 
 import (
+	"context"
 	"bytes"
 	"os"
 	"os/exec"
@@ -52,7 +53,7 @@ func TestRubyProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.RubyProfiler.SetUp(args.job)
+				return fields.RubyProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -76,7 +77,7 @@ func TestRubyProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.RubyProfiler.SetUp(args.job)
+				return fields.RubyProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -99,7 +100,7 @@ func TestRubyProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.RubyProfiler.SetUp(args.job)
+				return fields.RubyProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.NotNil(t, err)
@@ -159,7 +160,7 @@ func TestRubyProfiler_Invoke(t *testing.T) {
 			when: func(fields fields, args args) (error, time.Duration) {
 				fields.RubyProfiler.delay = 0
 				fields.RubyProfiler.targetPIDs = []string{"1000", "2000"}
-				return fields.RubyProfiler.Invoke(args.job)
+				return fields.RubyProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -191,7 +192,7 @@ func TestRubyProfiler_Invoke(t *testing.T) {
 			when: func(fields fields, args args) (error, time.Duration) {
 				fields.RubyProfiler.delay = 0
 				fields.RubyProfiler.targetPIDs = []string{"1000", "2000"}
-				return fields.RubyProfiler.Invoke(args.job)
+				return fields.RubyProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				require.Error(t, err)
@@ -246,7 +247,7 @@ func TestRubyProfiler_CleanUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.RubyProfiler.CleanUp(args.job)
+				return fields.RubyProfiler.CleanUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				f := filepath.Join(common.TmpDir(), config.ProfilingPrefix+"flamegraph.svg")
@@ -17,6 +17,7 @@ import (
 	"github.com/josepdcs/kubectl-prof/internal/agent/profiler/common"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util"
 	executil "github.com/josepdcs/kubectl-prof/internal/agent/util/exec"
+	"github.com/josepdcs/kubectl-prof/internal/agent/util/progress"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util/publish"
 	"github.com/josepdcs/kubectl-prof/pkg/util/file"
 	"github.com/josepdcs/kubectl-prof/pkg/util/log"
@@ -57,7 +58,7 @@ func NewRustProfiler(commander executil.Commander, publisher publish.Publisher)
 	}
 }
 
-func (r *RustProfiler) SetUp(job *job.ProfilingJob) error {
+func (r *RustProfiler) SetUp(_ context.Context, job *job.ProfilingJob) error {
 	if stringUtils.IsNotBlank(job.PID) {
 		r.targetPIDs = []string{job.PID}
 		return nil
@@ -72,7 +73,7 @@ func (r *RustProfiler) SetUp(job *job.ProfilingJob) error {
 	return nil
 }
 
-func (r *RustProfiler) Invoke(job *job.ProfilingJob) (error, time.Duration) {
+func (r *RustProfiler) Invoke(ctx context.Context, job *job.ProfilingJob) (error, time.Duration) {
 	start := time.Now()
 
 	// create a pool of workers
@@ -80,13 +81,16 @@ func (r *RustProfiler) Invoke(job *job.ProfilingJob) (error, time.Duration) {
 	defer pool.StopAndWait()
 
 	// create a task group associated to a context
-	group, _ := pool.GroupContext(context.Background())
+	group, _ := pool.GroupContext(ctx)
 
 	// submit tasks to profile
 	for _, pid := range r.targetPIDs {
 		pid := pid
 		group.Submit(func() error {
+			progress.Running(pid)
 			err, _ := r.invoke(job, pid)
+			err = common.ToolError(job.Tool, pid, err)
+			progress.Done(pid, err)
 			return err
 		})
 		// wait a bit between jobs for not overloading the system
@@ -125,8 +129,8 @@ func (p *rustManager) invoke(job *job.ProfilingJob, pid string) (error, time.Dur
 	// Ensure no stale perf.data exists
 	file.RemoveAll(common.TmpDir(), "perf.data")
 
-	// Start the profiler process
-	if err := cmd.Start(); err != nil {
+	// Start the profiler process, which may be asked to end early by stopping the profiling
+	if err := common.StartTool(cmd); err != nil {
 		return fmt.Errorf("could not start profiler: %w", err), time.Since(start)
 	}
 
@@ -137,7 +141,7 @@ func (p *rustManager) invoke(job *job.ProfilingJob, pid string) (error, time.Dur
 	defer timer.Stop()
 
 	// Wait for the process to finish
-	err := cmd.Wait()
+	err := common.WaitTool(cmd)
 	if err != nil && !p.isExpectedTermination(err) {
 		// Log both stdout and stderr for debugging
 		if out.Len() > 0 {
@@ -154,6 +158,7 @@ func (p *rustManager) invoke(job *job.ProfilingJob, pid string) (error, time.Dur
 		return fmt.Errorf("output file not found: %s", fileName), time.Since(start)
 	}
 
+	progress.Publishing(pid)
 	return p.publisher.Do(job.Compressor, fileName, job.OutputType), time.Since(start)
 }
 
@@ -196,7 +201,13 @@ func (p *rustManager) isExpectedTermination(err error) bool {
 	return false
 }
 
-func (r *RustProfiler) CleanUp(_ *job.ProfilingJob) error {
+// Stop interrupts the running cargo-flamegraph, which ends its capture early, so that the partial results are published
+func (r *RustProfiler) Stop(context.Context, *job.ProfilingJob) error {
+	common.InterruptTools()
+	return nil
+}
+
+func (r *RustProfiler) CleanUp(context.Context, *job.ProfilingJob) error {
 	file.RemoveAll(common.TmpDir(), config.ProfilingPrefix)
 
 	return nil
@@ -1,6 +1,7 @@
 package profiler
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -51,7 +52,7 @@ func TestRustProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.RustProfiler.SetUp(args.job)
+				return fields.RustProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -75,7 +76,7 @@ func TestRustProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.RustProfiler.SetUp(args.job)
+				return fields.RustProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -98,7 +99,7 @@ func TestRustProfiler_SetUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.RustProfiler.SetUp(args.job)
+				return fields.RustProfiler.SetUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.NotNil(t, err)
@@ -158,7 +159,7 @@ func TestRustProfiler_Invoke(t *testing.T) {
 			when: func(fields fields, args args) (error, time.Duration) {
 				fields.RustProfiler.delay = 0
 				fields.RustProfiler.targetPIDs = []string{"1000", "2000"}
-				return fields.RustProfiler.Invoke(args.job)
+				return fields.RustProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				assert.Nil(t, err)
@@ -189,7 +190,7 @@ func TestRustProfiler_Invoke(t *testing.T) {
 			when: func(fields fields, args args) (error, time.Duration) {
 				fields.RustProfiler.delay = 0
 				fields.RustProfiler.targetPIDs = []string{"1000", "2000"}
-				return fields.RustProfiler.Invoke(args.job)
+				return fields.RustProfiler.Invoke(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error, fields fields) {
 				require.Error(t, err)
@@ -245,7 +246,7 @@ func TestRustProfiler_CleanUp(t *testing.T) {
 					}
 			},
 			when: func(fields fields, args args) error {
-				return fields.RustProfiler.CleanUp(args.job)
+				return fields.RustProfiler.CleanUp(context.Background(), args.job)
 			},
 			then: func(t *testing.T, err error) {
 				f := filepath.Join(common.TmpDir(), config.ProfilingPrefix+"flamegraph.svg")
@@ -0,0 +1,75 @@
+package profiler
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/api"
+)
+
+// toolVersionTimeout is the maximum time to wait for a profiling tool to print its version
+const toolVersionTimeout = 5 * time.Second
+
+// toolBinary is the binary of a profiling tool in the agent image, with the arguments printing its version.
+// Tools without such arguments are only checked for presence.
+type toolBinary struct {
+	path        string
+	versionArgs []string
+}
+
+// toolBinaries are the binaries of the profiling tools shipped by the agent images; every image only ships some of them
+var toolBinaries = map[api.ProfilingTool]toolBinary{
+	api.AsyncProfiler:  {path: "/app/async-profiler/build/bin/asprof", versionArgs: []string{"--version"}},
+	api.Jcmd:           {path: "/opt/jdk/bin/jcmd"},
+	api.Pyspy:          {path: pySpyLocation, versionArgs: []string{"--version"}},
+	api.Memray:         {path: memrayLocation, versionArgs: []string{"--version"}},
+	api.Bpf:            {path: profilerLocation},
+	api.Btf:            {path: btfProfilerLocation},
+	api.Perf:           {path: perfLocation, versionArgs: []string{"--version"}},
+	api.Rbspy:          {path: rbSpyLocation, versionArgs: []string{"--version"}},
+	api.CargoFlame:     {path: cargoFlameLocation, versionArgs: []string{"--version"}},
+	api.Phpspy:         {path: phpSpyLocation, versionArgs: []string{"-V"}},
+	api.DotnetTrace:    {path: dotnetAppDir + "/dotnet-trace", versionArgs: []string{"--version"}},
+	api.DotnetGcdump:   {path: dotnetAppDir + "/dotnet-gcdump", versionArgs: []string{"--version"}},
+	api.DotnetCounters: {path: dotnetAppDir + "/dotnet-counters", versionArgs: []string{"--version"}},
+	api.DotnetDump:     {path: dotnetAppDir + "/dotnet-dump", versionArgs: []string{"--version"}},
+}
+
+// builtInTools are the profiling tools implemented by the agent itself, so they are always available
+var builtInTools = []api.ProfilingTool{api.NodeDummy, api.GoPprof}
+
+// AvailableTools returns the profiling tools available in the agent, sorted by name, with their versions when known
+func AvailableTools() []api.ToolInfo {
+	tools := make([]api.ToolInfo, 0, len(builtInTools)+len(toolBinaries))
+	for _, tool := range builtInTools {
+		tools = append(tools, api.ToolInfo{Tool: tool})
+	}
+	for tool, binary := range toolBinaries {
+		if info, err := os.Stat(binary.path); err != nil || info.IsDir() {
+			continue
+		}
+		tools = append(tools, api.ToolInfo{Tool: tool, Version: toolVersion(binary)})
+	}
+	slices.SortFunc(tools, func(a, b api.ToolInfo) int { return strings.Compare(string(a.Tool), string(b.Tool)) })
+	return tools
+}
+
+// toolVersion returns the first line printed by the given binary when asked for its version, or empty string
+// if it cannot be obtained
+func toolVersion(binary toolBinary) string {
+	if len(binary.versionArgs) == 0 {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), toolVersionTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, binary.path, binary.versionArgs...).CombinedOutput()
+	if err != nil {
+		return ""
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(line)
+}
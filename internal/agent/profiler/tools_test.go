@@ -0,0 +1,36 @@
+package profiler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAvailableTools(t *testing.T) {
+	dir := t.TempDir()
+	perf := filepath.Join(dir, "perf")
+	require.NoError(t, os.WriteFile(perf, []byte("#!/bin/sh\necho 'perf version 6.1.76'\necho other\n"), 0755))
+	bpf := filepath.Join(dir, "profile")
+	require.NoError(t, os.WriteFile(bpf, []byte("#!/bin/sh\n"), 0755))
+
+	original := toolBinaries
+	toolBinaries = map[api.ProfilingTool]toolBinary{
+		api.Perf:  {path: perf, versionArgs: []string{"--version"}},
+		api.Bpf:   {path: bpf},
+		api.Pyspy: {path: filepath.Join(dir, "py-spy"), versionArgs: []string{"--version"}},
+	}
+	defer func() { toolBinaries = original }()
+
+	tools := AvailableTools()
+
+	assert.Equal(t, []api.ToolInfo{
+		{Tool: api.Bpf},
+		{Tool: api.NodeDummy},
+		{Tool: api.Perf, Version: "perf version 6.1.76"},
+		{Tool: api.GoPprof},
+	}, tools)
+}
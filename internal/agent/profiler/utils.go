@@ -5,20 +5,27 @@ import (
 	"strings"
 
 	"github.com/agrison/go-commons-lang/stringUtils"
+	"github.com/josepdcs/kubectl-prof/internal/agent/job"
 )
 
 // addProcessPIDLegend adds the process PID to each line of the input string and returns the result.
 // If the input string is empty, it returns the input string.
 // If an error occurs while scanning the input string, it returns the input string.
 func addProcessPIDLegend(input string, pid string) string {
+	return addLegend(input, "process: "+pid)
+}
+
+// addLegend adds the given legend to each line of the input string and returns the result.
+// If the input string is empty, it returns the input string.
+// If an error occurs while scanning the input string, it returns the input string.
+func addLegend(input string, legend string) string {
 	if stringUtils.IsBlank(input) {
 		return input
 	}
 	var sb strings.Builder
 	scanner := bufio.NewScanner(strings.NewReader(input))
 	for scanner.Scan() {
-		sb.WriteString("process: ")
-		sb.WriteString(pid)
+		sb.WriteString(legend)
 		sb.WriteString(";")
 		sb.WriteString(scanner.Text())
 		sb.WriteString("\n")
@@ -29,3 +36,19 @@ func addProcessPIDLegend(input string, pid string) string {
 	}
 	return sb.String()
 }
+
+// addJobLegend adds to each line of the input string the legend of the given PID:
+// the namespace/pod/container owning it when the whole node is profiled, or the process PID otherwise.
+func addJobLegend(input string, job *job.ProfilingJob, pid string) string {
+	if legend, ok := job.ProcessLegends[pid]; ok {
+		return addLegend(input, legend)
+	}
+	return addProcessPIDLegend(input, pid)
+}
+
+// resultFileID returns the identifier used for naming the files of the given PID.
+// When a group of comma-separated PIDs is profiled together, the files are named after the first one.
+func resultFileID(pid string) string {
+	id, _, _ := strings.Cut(pid, ",")
+	return id
+}
@@ -3,6 +3,7 @@ package profiler
 import (
 	"testing"
 
+	"github.com/josepdcs/kubectl-prof/internal/agent/job"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -40,3 +41,35 @@ func Test_addProcessPIDLegend(t *testing.T) {
 		})
 	}
 }
+
+func Test_addJobLegend(t *testing.T) {
+	tests := []struct {
+		name string
+		job  *job.ProfilingJob
+		pid  string
+		want string
+	}{
+		{
+			name: "should add pod legend when the whole node is profiled",
+			job:  &job.ProfilingJob{ProcessLegends: map[string]string{"100,101": "ns/pod/container"}},
+			pid:  "100,101",
+			want: "ns/pod/container;test\n",
+		},
+		{
+			name: "should add process pid legend otherwise",
+			job:  &job.ProfilingJob{},
+			pid:  "123",
+			want: "process: 123;test\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, addJobLegend("test", tt.job, tt.pid))
+		})
+	}
+}
+
+func Test_resultFileID(t *testing.T) {
+	assert.Equal(t, "123", resultFileID("123"))
+	assert.Equal(t, "100", resultFileID("100,101,102"))
+}
@@ -23,3 +23,7 @@ func ContainerdTestDataDir() string {
 func ResultTestDataDir() string {
 	return RootDir() + "/testdata/result"
 }
+
+func ProcTestDataDir() string {
+	return RootDir() + "/testdata/proc"
+}
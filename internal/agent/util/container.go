@@ -14,6 +14,7 @@ import (
 	"github.com/josepdcs/kubectl-prof/internal/agent/util/runtime/containerd"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util/runtime/crio"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util/runtime/fake"
+	"github.com/josepdcs/kubectl-prof/internal/agent/util/runtime/sharedpid"
 	"github.com/josepdcs/kubectl-prof/pkg/util/log"
 	"github.com/pkg/errors"
 )
@@ -45,6 +46,8 @@ var runtime = func(r api.ContainerRuntime) (Container, error) {
 		return crio.NewCrio(), nil
 	case api.Containerd:
 		return containerd.NewContainerd(), nil
+	case api.SharedProcessNamespace:
+		return sharedpid.NewSharedPID(), nil
 	case api.FakeContainer:
 		return fake.NewRuntimeFake(), nil
 	case api.FakeContainerWithRootFileSystemLocationResultError:
@@ -79,11 +82,11 @@ func GetRootPID(job *job.ProfilingJob) (string, error) {
 	}
 	c, err := runtime(job.ContainerRuntime)
 	if err != nil {
-		return "", err
+		return "", runtimeLayoutError(err)
 	}
 	pid, err := c.PID(job.ContainerID, job.ContainerRuntimePath)
 	if err != nil {
-		return "", err
+		return "", runtimeLayoutError(err)
 	}
 	return pid, nil
 }
@@ -139,23 +142,26 @@ func GetCandidatePIDs(job *job.ProfilingJob) ([]string, error) {
 	}
 	c, err := runtime(job.ContainerRuntime)
 	if err != nil {
-		return nil, err
+		return nil, runtimeLayoutError(err)
 	}
 	pid, err := c.PID(job.ContainerID, job.ContainerRuntimePath)
 	if err != nil {
-		return nil, err
+		return nil, runtimeLayoutError(err)
 	}
 
 	// When applications launch subprocesses, their PIDs need to be identified for profiling.
 	var pidsToProfile []string
 	fillWithChildrenPIDs(pid, &pidsToProfile)
 	if len(pidsToProfile) == 0 {
-		return nil, errors.Errorf("no PIDs found for container ID: %s", job.ContainerID)
+		return nil, &api.AgentError{Code: api.PIDNotFound, PID: pid, Err: errors.Errorf("no PIDs found for container ID: %s", job.ContainerID)}
 	}
 	err = filterPIDsToProfile(&pidsToProfile, job.Pgrep)
 	if err != nil {
 		return nil, err
 	}
+	if len(pidsToProfile) == 0 {
+		return nil, &api.AgentError{Code: api.PIDNotFound, Err: errors.Errorf("no PIDs matching %s found for container ID: %s", job.Pgrep, job.ContainerID)}
+	}
 
 	// If more than one PID is detected, a notice is shown
 	if len(pidsToProfile) > 1 {
@@ -172,6 +178,11 @@ func GetCandidatePIDs(job *job.ProfilingJob) ([]string, error) {
 	return pidsToProfile, nil
 }
 
+// runtimeLayoutError categorizes the given error finding the container in the container runtime
+func runtimeLayoutError(err error) error {
+	return &api.AgentError{Code: api.UnsupportedRuntimeLayout, Err: err}
+}
+
 // fillWithChildrenPIDs fills the given slice with the PIDs of the children processes of the given PID
 func fillWithChildrenPIDs(pid string, pidsToProfile *[]string) {
 	child := childPIDGetterInstance.get(pid)
@@ -13,6 +13,7 @@ import (
 	"github.com/josepdcs/kubectl-prof/internal/agent/util/runtime/containerd"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util/runtime/crio"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util/runtime/fake"
+	"github.com/josepdcs/kubectl-prof/internal/agent/util/runtime/sharedpid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -127,6 +128,11 @@ func TestRuntime(t *testing.T) {
 			runtime:  api.Containerd,
 			expected: containerd.NewContainerd(),
 		},
+		{
+			name:     "shared process namespace",
+			runtime:  api.SharedProcessNamespace,
+			expected: sharedpid.NewSharedPID(),
+		},
 		{
 			name:     "fake container runtime",
 			runtime:  api.FakeContainer,
@@ -177,6 +183,7 @@ func TestGetCandidatePIDs(t *testing.T) {
 		mockFunc        func()
 		expected        []string
 		containedErrMsg string
+		errCode         api.ErrorCode
 	}{
 		{
 			name: "empty container runtime",
@@ -206,6 +213,7 @@ func TestGetCandidatePIDs(t *testing.T) {
 			mockFunc:        func() {},
 			expected:        nil,
 			containedErrMsg: "unsupported container runtime: other",
+			errCode:         api.UnsupportedRuntimeLayout,
 		},
 		{
 			name: "crio container runtime",
@@ -301,6 +309,28 @@ func TestGetCandidatePIDs(t *testing.T) {
 			},
 			containedErrMsg: "ps command failed with error:",
 		},
+		{
+			name: "no child process matching pgrep",
+			job: job.ProfilingJob{
+				ContainerRuntime: api.Containerd,
+				ContainerID:      "12334_CONTAINERD",
+				Pgrep:            "java",
+			},
+			mockFunc: func() {
+				runtime = func(runtime api.ContainerRuntime) (Container, error) {
+					return fake.NewRuntimeFake(), nil
+				}
+				childPIDGetterInstance = &childPIDGetterMock{
+					interation: 0,
+					results:    []string{"PID_12334_CONTAINERD"},
+				}
+				mc := executil.NewMockCommander()
+				mc.On("Command").Return(exec.Command("echo", "python")).Once()
+				commander = mc
+			},
+			containedErrMsg: "no PIDs matching java found for container ID: 12334_CONTAINERD",
+			errCode:         api.PIDNotFound,
+		},
 	}
 
 	// preserve the original function
@@ -313,6 +343,9 @@ func TestGetCandidatePIDs(t *testing.T) {
 			if err != nil {
 				assert.Contains(t, err.Error(), tt.containedErrMsg)
 				assert.Nil(t, pids)
+				if tt.errCode != "" {
+					assert.Equal(t, tt.errCode, api.NewErrorData(err).Code)
+				}
 			} else {
 				assert.Equal(t, tt.expected, pids)
 			}
@@ -0,0 +1,169 @@
+// Package fileserver serves the result files of the agent over HTTP, so that the CLI can download and acknowledge
+// them through a port-forward instead of executing commands in the agent container.
+package fileserver
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/pkg/errors"
+)
+
+const (
+	// PathPrefix is the prefix of the URL path of the served files
+	PathPrefix = "/files/"
+	// ChecksumHeader is the header holding the MD5 checksum of the whole served file
+	ChecksumHeader = "X-Checksum-Md5"
+)
+
+// root is the directory served once the server has been started
+var root string
+
+// Start starts serving the files of the given directory on the loopback interface at the given port, calling the
+// given function once a file is acknowledged. The listener is open when Start returns, so the published URLs can be
+// reached right away.
+func Start(port int, dir string, acknowledged func()) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return errors.Wrapf(err, "could not start the file server at port %d", port)
+	}
+
+	root = dir
+	server := &http.Server{
+		Handler:           NewHandler(dir, acknowledged),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() { _ = server.Serve(listener) }()
+
+	return nil
+}
+
+// URLPath returns the URL path where the given file is served.
+// Empty string is returned if the server has not been started or the file is not under the served directory.
+func URLPath(file string) string {
+	if root == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(root, file)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	return PathPrefix + filepath.ToSlash(rel)
+}
+
+// handler serves the regular files of a directory with Range support, along with their checksum.
+// A POST on the URL of a file acknowledges it as retrieved, even if it was already removed such as a split file.
+type handler struct {
+	dir          string
+	acknowledged func()
+	mutex        sync.Mutex
+	checksums    map[string]checksum
+}
+
+// checksum is the cached checksum of a file, valid while the file is not modified
+type checksum struct {
+	modTime time.Time
+	size    int64
+	value   string
+}
+
+// NewHandler returns a http.Handler serving the regular files of the given directory and calling the given function,
+// if any, once a file is acknowledged
+func NewHandler(dir string, acknowledged func()) http.Handler {
+	return &handler{
+		dir:          dir,
+		acknowledged: acknowledged,
+		checksums:    map[string]checksum{},
+	}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead && r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, PathPrefix) {
+		http.NotFound(w, r)
+		return
+	}
+
+	// path.Clean on a rooted path removes any ".." element
+	name := filepath.Join(h.dir, filepath.FromSlash(path.Clean("/"+strings.TrimPrefix(r.URL.Path, PathPrefix))))
+	if r.Method == http.MethodPost {
+		h.acknowledge(w, r, name)
+		return
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		http.NotFound(w, r)
+		return
+	}
+
+	sum, err := h.checksum(name, f, info)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(ChecksumHeader, sum)
+	w.Header().Set("Content-Type", "application/octet-stream")
+
+	// ServeContent handles the Range requests
+	http.ServeContent(w, r, "", info.ModTime(), f)
+}
+
+// acknowledge writes the acknowledgement marker of the given file, as the CLI does by exec, and notifies it
+func (h *handler) acknowledge(w http.ResponseWriter, r *http.Request, name string) {
+	if name == h.dir {
+		http.NotFound(w, r)
+		return
+	}
+	if err := os.WriteFile(name+api.AcknowledgementSuffix, nil, 0644); err != nil {
+		http.Error(w, fmt.Sprintf("could not acknowledge %s: %v", name, err), http.StatusInternalServerError)
+		return
+	}
+	if h.acknowledged != nil {
+		h.acknowledged()
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checksum returns the MD5 checksum of the given file, computing it only if the file was modified since the last time
+func (h *handler) checksum(name string, f *os.File, info os.FileInfo) (string, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if c, ok := h.checksums[name]; ok && c.modTime.Equal(info.ModTime()) && c.size == info.Size() {
+		return c.value, nil
+	}
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", errors.Wrapf(err, "could not compute the checksum of %s", name)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", errors.Wrapf(err, "could not read %s", name)
+	}
+
+	value := hex.EncodeToString(hash.Sum(nil))
+	h.checksums[name] = checksum{modTime: info.ModTime(), size: info.Size(), value: value}
+	return value, nil
+}
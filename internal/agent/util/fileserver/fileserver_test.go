@@ -0,0 +1,129 @@
+package fileserver
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "flamegraph.svg.gz"), []byte("the content"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+	server := httptest.NewServer(NewHandler(dir, nil))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		rangeValue string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "should serve the file",
+			method:     http.MethodGet,
+			path:       "/files/flamegraph.svg.gz",
+			wantStatus: http.StatusOK,
+			wantBody:   "the content",
+		},
+		{
+			name:       "should serve the range of the file",
+			method:     http.MethodGet,
+			path:       "/files/flamegraph.svg.gz",
+			rangeValue: "bytes=4-",
+			wantStatus: http.StatusPartialContent,
+			wantBody:   "content",
+		},
+		{
+			name:       "should not serve files outside the directory",
+			method:     http.MethodGet,
+			path:       "/files/../../etc/passwd",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "should not serve directories",
+			method:     http.MethodGet,
+			path:       "/files/sub",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "should not serve other paths",
+			method:     http.MethodGet,
+			path:       "/flamegraph.svg.gz",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "should not allow other methods",
+			method:     http.MethodDelete,
+			path:       "/files/flamegraph.svg.gz",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL+tt.path, nil)
+			require.NoError(t, err)
+			if tt.rangeValue != "" {
+				req.Header.Set("Range", tt.rangeValue)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			if tt.wantBody != "" {
+				body, _ := io.ReadAll(resp.Body)
+				assert.Equal(t, tt.wantBody, string(body))
+				// md5 of "the content"
+				assert.Equal(t, "da619dfbf5572fc749b1496b0fffd76a", resp.Header.Get(ChecksumHeader))
+			}
+		})
+	}
+}
+
+func TestHandler_Acknowledge(t *testing.T) {
+	dir := t.TempDir()
+	var acknowledged int
+	server := httptest.NewServer(NewHandler(dir, func() { acknowledged++ }))
+	defer server.Close()
+
+	// the file of a split result is removed once split
+	resp, err := http.Post(server.URL+"/files/flamegraph.svg.gz", "", nil)
+
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.FileExists(t, filepath.Join(dir, "flamegraph.svg.gz"+api.AcknowledgementSuffix))
+	assert.Equal(t, 1, acknowledged)
+
+	resp, err = http.Post(server.URL+"/files/", "", nil)
+
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, 1, acknowledged)
+}
+
+func TestURLPath(t *testing.T) {
+	root = "/tmp"
+	defer func() { root = "" }()
+
+	assert.Equal(t, "/files/flamegraph.svg.gz", URLPath("/tmp/flamegraph.svg.gz"))
+	assert.Equal(t, "/files/sub/heapdump.hprof.gz.00", URLPath("/tmp/sub/heapdump.hprof.gz.00"))
+	assert.Empty(t, URLPath("/proc/1/root/tmp/flamegraph.svg.gz"))
+	assert.Empty(t, URLPath("/tmp"))
+}
+
+func TestURLPath_NotStarted(t *testing.T) {
+	assert.Empty(t, URLPath("/tmp/flamegraph.svg.gz"))
+}
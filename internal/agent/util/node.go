@@ -0,0 +1,79 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/agrison/go-commons-lang/stringUtils"
+	"github.com/josepdcs/kubectl-prof/internal/agent/job"
+	"github.com/josepdcs/kubectl-prof/pkg/util/log"
+	"github.com/pkg/errors"
+)
+
+// procRoot is the location of the proc filesystem of the node (the agent runs with hostPID)
+var procRoot = "/proc"
+
+// containerIDInCgroup matches the container ID (64 hex characters) inside a cgroup path,
+// e.g.: /kubepods.slice/.../cri-containerd-<id>.scope or /kubepods/besteffort/pod<uid>/<id>
+var containerIDInCgroup = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// GetNodeTargets returns the processes of every known container running on the node, grouped by container.
+// Each returned key is the comma-separated list of PIDs running inside the cgroup of a container,
+// and its value is the namespace/pod/container legend of that container, taken from job.NodeContainers.
+func GetNodeTargets(job *job.ProfilingJob) (map[string]string, error) {
+	if len(job.NodeContainers) == 0 {
+		return nil, errors.New("node containers are mandatory for node-wide profiling")
+	}
+
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read %s", procRoot)
+	}
+
+	pidsByLegend := make(map[string][]string)
+	for _, entry := range entries {
+		if !entry.IsDir() || !stringUtils.IsNumeric(entry.Name()) {
+			continue
+		}
+		legend, ok := PodLegendOfPID(entry.Name(), job.NodeContainers)
+		if !ok {
+			continue
+		}
+		pidsByLegend[legend] = append(pidsByLegend[legend], entry.Name())
+	}
+
+	if len(pidsByLegend) == 0 {
+		return nil, errors.New("no processes found for the containers of the node")
+	}
+
+	targets := make(map[string]string, len(pidsByLegend))
+	for legend, pids := range pidsByLegend {
+		slices.Sort(pids)
+		targets[strings.Join(pids, ",")] = legend
+	}
+	log.DebugLogLn(fmt.Sprintf("The node processes to be profiled: %v", targets))
+
+	return targets, nil
+}
+
+// PodLegendOfPID returns the namespace/pod/container legend of the container owning the given PID.
+// The container is identified from the cgroup of the process, and looked up in the given containers
+// (container ID -> namespace/pod/container). It returns false if the process does not belong to any of them.
+func PodLegendOfPID(pid string, containers map[string]string) (string, bool) {
+	content, err := os.ReadFile(filepath.Join(procRoot, pid, "cgroup"))
+	if err != nil {
+		return "", false
+	}
+
+	for _, id := range containerIDInCgroup.FindAllString(string(content), -1) {
+		if legend, ok := containers[id]; ok {
+			return legend, true
+		}
+	}
+
+	return "", false
+}
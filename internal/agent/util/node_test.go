@@ -0,0 +1,95 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/josepdcs/kubectl-prof/internal/agent/job"
+	"github.com/josepdcs/kubectl-prof/internal/agent/testdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	systemdContainerID  = "4a1f2e3d4c5b6a7980a1b2c3d4e5f60718293a4b5c6d7e8f9001122334455667"
+	cgroupfsContainerID = "9f8e7d6c5b4a39281706f5e4d3c2b1a0fedcba98765432100123456789abcdef"
+)
+
+func TestGetNodeTargets(t *testing.T) {
+	original := procRoot
+	procRoot = testdata.ProcTestDataDir()
+	defer func() { procRoot = original }()
+
+	tests := []struct {
+		name            string
+		job             *job.ProfilingJob
+		expected        map[string]string
+		containedErrMsg string
+	}{
+		{
+			name: "should group the processes by container",
+			job: &job.ProfilingJob{
+				NodeContainers: map[string]string{
+					systemdContainerID:  "default/checkout-abc/app",
+					cgroupfsContainerID: "kube-system/coredns-xyz/coredns",
+				},
+			},
+			expected: map[string]string{
+				"100,101": "default/checkout-abc/app",
+				"200":     "kube-system/coredns-xyz/coredns",
+			},
+		},
+		{
+			name: "should ignore processes of unknown containers",
+			job: &job.ProfilingJob{
+				NodeContainers: map[string]string{
+					cgroupfsContainerID: "kube-system/coredns-xyz/coredns",
+				},
+			},
+			expected: map[string]string{
+				"200": "kube-system/coredns-xyz/coredns",
+			},
+		},
+		{
+			name: "should fail when no processes found",
+			job: &job.ProfilingJob{
+				NodeContainers: map[string]string{"unknown": "default/pod/container"},
+			},
+			containedErrMsg: "no processes found for the containers of the node",
+		},
+		{
+			name:            "should fail when no node containers",
+			job:             &job.ProfilingJob{},
+			containedErrMsg: "node containers are mandatory for node-wide profiling",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, err := GetNodeTargets(tt.job)
+			if tt.containedErrMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.containedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, targets)
+		})
+	}
+}
+
+func TestPodLegendOfPID(t *testing.T) {
+	original := procRoot
+	procRoot = testdata.ProcTestDataDir()
+	defer func() { procRoot = original }()
+
+	containers := map[string]string{cgroupfsContainerID: "kube-system/coredns-xyz/coredns"}
+
+	legend, ok := PodLegendOfPID("200", containers)
+	assert.True(t, ok)
+	assert.Equal(t, "kube-system/coredns-xyz/coredns", legend)
+
+	_, ok = PodLegendOfPID("300", containers)
+	assert.False(t, ok)
+
+	_, ok = PodLegendOfPID("999", containers)
+	assert.False(t, ok)
+}
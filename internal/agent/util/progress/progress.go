@@ -0,0 +1,109 @@
+// Package progress tracks the progress of the profiling, which is reported periodically to the CLI.
+package progress
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/josepdcs/kubectl-prof/pkg/util/log"
+)
+
+var (
+	mutex sync.Mutex
+	// start is when the profiling started
+	start time.Time
+	// duration is the total duration of the profiling
+	duration time.Duration
+	// iteration is the current iteration of the profiling, out of iterations
+	iteration, iterations int
+	// pids holds the state of the PIDs profiled within the current iteration
+	pids = map[string]api.PIDState{}
+)
+
+// Begin starts tracking a profiling lasting the given duration along the given number of iterations
+func Begin(d time.Duration, n int) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	start = time.Now()
+	duration = d
+	iteration = 0
+	iterations = n
+	pids = map[string]api.PIDState{}
+}
+
+// SetIteration sets the current iteration, whose PIDs are not profiled yet
+func SetIteration(i int) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	iteration = i
+	pids = map[string]api.PIDState{}
+}
+
+// Running marks the given PID as being profiled
+func Running(pid string) {
+	setState(pid, api.PIDRunning)
+}
+
+// Publishing marks the result of the given PID as being published
+func Publishing(pid string) {
+	setState(pid, api.PIDPublishing)
+}
+
+// Done marks the given PID as profiled, or as failed if an error is given
+func Done(pid string, err error) {
+	if err != nil {
+		setState(pid, api.PIDFailed)
+		return
+	}
+	setState(pid, api.PIDDone)
+}
+
+func setState(pid string, state api.PIDState) {
+	if pid == "" {
+		return
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	pids[pid] = state
+}
+
+// Event returns the progress event of the profiling at this moment
+func Event() *api.ProgressData {
+	mutex.Lock()
+	defer mutex.Unlock()
+	now := time.Now()
+	elapsed := now.Sub(start).Round(time.Second)
+	data := &api.ProgressData{
+		Time:       now,
+		Stage:      api.Profiling,
+		Iteration:  iteration,
+		Iterations: iterations,
+		Elapsed:    elapsed,
+		Remaining:  max(duration-elapsed, 0),
+	}
+	for pid, state := range pids {
+		data.PIDs = append(data.PIDs, api.PIDStatus{PID: pid, State: state})
+	}
+	slices.SortFunc(data.PIDs, func(a, b api.PIDStatus) int { return cmp.Compare(a.PID, b.PID) })
+	return data
+}
+
+// Report emits a progress event every given interval until the given channel is closed
+func Report(interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = log.EventLn(api.Progress, Event())
+		case <-stop:
+			return
+		}
+	}
+}
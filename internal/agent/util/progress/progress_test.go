@@ -0,0 +1,54 @@
+package progress
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvent(t *testing.T) {
+	// Given
+	Begin(time.Minute, 3)
+	SetIteration(2)
+	Running("20")
+	Publishing("10")
+	Done("30", nil)
+	Done("40", errors.New("boom"))
+	Running("")
+
+	// When
+	data := Event()
+
+	// Then
+	assert.Equal(t, api.Profiling, data.Stage)
+	assert.Equal(t, 2, data.Iteration)
+	assert.Equal(t, 3, data.Iterations)
+	assert.Equal(t, time.Minute, data.Elapsed+data.Remaining)
+	assert.Equal(t, []api.PIDStatus{
+		{PID: "10", State: api.PIDPublishing},
+		{PID: "20", State: api.PIDRunning},
+		{PID: "30", State: api.PIDDone},
+		{PID: "40", State: api.PIDFailed},
+	}, data.PIDs)
+}
+
+func TestSetIteration(t *testing.T) {
+	Begin(time.Minute, 2)
+	Running("10")
+
+	SetIteration(2)
+
+	assert.Empty(t, Event().PIDs)
+}
+
+func TestReport(t *testing.T) {
+	stop := make(chan struct{})
+	close(stop)
+
+	// returns once stopped, or right away without interval
+	Report(time.Millisecond, stop)
+	Report(0, nil)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/agrison/go-commons-lang/stringUtils"
 	"github.com/josepdcs/kubectl-prof/api"
 	"github.com/josepdcs/kubectl-prof/internal/agent/util/exec"
+	"github.com/josepdcs/kubectl-prof/internal/agent/util/fileserver"
 	"github.com/josepdcs/kubectl-prof/pkg/util/compressor"
 	fileutils "github.com/josepdcs/kubectl-prof/pkg/util/file"
 	"github.com/josepdcs/kubectl-prof/pkg/util/log"
@@ -55,17 +56,20 @@ func (p publisher) Do(compressorType compressor.Type, filePath string, eventType
 		return errors.Wrapf(err, "could not compress file %s", resultFile)
 	}
 
-	return log.EventLn(
-		api.Result,
-		api.ResultData{
-			Time:            time.Now(),
-			ResultType:      eventType,
-			File:            resultFile,
-			FileSizeInBytes: fileutils.Size(resultFile),
-			Checksum:        fileutils.Checksum(resultFile),
-			CompressorType:  string(compressorType),
-		},
-	)
+	data := api.ResultData{
+		Time:            time.Now(),
+		ResultType:      eventType,
+		File:            resultFile,
+		URL:             fileserver.URLPath(resultFile),
+		FileSizeInBytes: fileutils.Size(resultFile),
+		Checksum:        fileutils.Checksum(resultFile),
+		CompressorType:  string(compressorType),
+	}
+	store(&data)
+	upload(&data)
+	track(&data)
+
+	return log.EventLn(api.Result, data)
 }
 
 // DoWithNativeGzipAndSplit compress the file with gzip and split the result file in chunks
@@ -111,22 +115,26 @@ func (p publisher) DoWithNativeGzipAndSplit(file, chunkSize string, eventType ap
 			chunkFilesData,
 			api.ChunkData{
 				File:            chunkFile,
+				URL:             fileserver.URLPath(chunkFile),
 				FileSizeInBytes: fileutils.Size(chunkFile),
 				Checksum:        fileutils.Checksum(chunkFile),
 			})
 	}
 
-	return log.EventLn(
-		api.Result,
-		api.ResultData{
-			Time:            time.Now(),
-			ResultType:      eventType,
-			File:            file + ".gz",
-			FileSizeInBytes: fileSizeInBytes,
-			CompressorType:  compressor.Gzip,
-			Chunks:          chunkFilesData,
-		},
-	)
+	data := api.ResultData{
+		Time:            time.Now(),
+		ResultType:      eventType,
+		File:            file + ".gz",
+		URL:             fileserver.URLPath(file + ".gz"),
+		FileSizeInBytes: fileSizeInBytes,
+		CompressorType:  compressor.Gzip,
+		Chunks:          chunkFilesData,
+	}
+	store(&data)
+	upload(&data)
+	track(&data)
+
+	return log.EventLn(api.Result, data)
 }
 
 // Do compress the file and publishes the result
@@ -0,0 +1,84 @@
+package publish
+
+import (
+	"sync"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	fileutils "github.com/josepdcs/kubectl-prof/pkg/util/file"
+)
+
+var (
+	// retrievalMutex serializes the tracking of the retrieval of the result files
+	retrievalMutex sync.Mutex
+	// pending are the result files published but not yet acknowledged as retrieved by the CLI
+	pending = map[string]struct{}{}
+	// published is the number of result files the CLI has to retrieve from the agent
+	published int
+	// ended tells whether no more result file will be published
+	ended bool
+	// retrieved is closed once every published result file was retrieved
+	retrieved = make(chan struct{})
+)
+
+// track records the given result as to be retrieved from the agent, unless it was uploaded to the object storage,
+// from where it is retrieved without the agent
+func track(data *api.ResultData) {
+	if isUploaded(data) {
+		return
+	}
+
+	retrievalMutex.Lock()
+	defer retrievalMutex.Unlock()
+	pending[data.File] = struct{}{}
+	published++
+}
+
+func isUploaded(data *api.ResultData) bool {
+	if len(data.Chunks) == 0 {
+		return data.Object != ""
+	}
+	for _, chunk := range data.Chunks {
+		if chunk.Object == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckAcknowledgements records as retrieved the pending result files, including all their chunks, whose
+// acknowledgement marker was written by the CLI
+func CheckAcknowledgements() {
+	retrievalMutex.Lock()
+	defer retrievalMutex.Unlock()
+	for file := range pending {
+		if fileutils.Exists(file + api.AcknowledgementSuffix) {
+			delete(pending, file)
+		}
+	}
+	closeIfRetrieved()
+}
+
+// EndPublishing records that no more result file will be published
+func EndPublishing() {
+	retrievalMutex.Lock()
+	defer retrievalMutex.Unlock()
+	ended = true
+	closeIfRetrieved()
+}
+
+// Retrieved returns a channel closed once the publishing ended and every published result file was acknowledged.
+// It is never closed if no result file was published.
+func Retrieved() <-chan struct{} {
+	return retrieved
+}
+
+func closeIfRetrieved() {
+	if !ended || published == 0 || len(pending) > 0 {
+		return
+	}
+	select {
+	case <-retrieved:
+	default:
+		close(retrieved)
+	}
+}
@@ -0,0 +1,115 @@
+package publish
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resetRetrieval resets the tracking of the retrieval of the result files
+func resetRetrieval() {
+	pending = map[string]struct{}{}
+	published = 0
+	ended = false
+	retrieved = make(chan struct{})
+}
+
+// isRetrieved returns whether every published result file is considered retrieved
+func isRetrieved() bool {
+	select {
+	case <-Retrieved():
+		return true
+	default:
+		return false
+	}
+}
+
+func TestRetrieved(t *testing.T) {
+	// acknowledge writes the acknowledgement marker of the given result file as the CLI does
+	acknowledge := func(t *testing.T, file string) {
+		require.NoError(t, os.WriteFile(file+api.AcknowledgementSuffix, nil, 0644))
+	}
+
+	tests := []struct {
+		name string
+		when func(t *testing.T, dir string)
+		want bool
+	}{
+		{
+			name: "should be retrieved once every result file was acknowledged and the publishing ended",
+			when: func(t *testing.T, dir string) {
+				track(&api.ResultData{File: filepath.Join(dir, "flamegraph.svg.gz")})
+				track(&api.ResultData{File: filepath.Join(dir, "heapdump.gz"), Chunks: []api.ChunkData{
+					{File: filepath.Join(dir, "heapdump.gz.00")}, {File: filepath.Join(dir, "heapdump.gz.01")},
+				}})
+				acknowledge(t, filepath.Join(dir, "flamegraph.svg.gz"))
+				acknowledge(t, filepath.Join(dir, "heapdump.gz"))
+				CheckAcknowledgements()
+				EndPublishing()
+			},
+			want: true,
+		},
+		{
+			name: "should not be retrieved while the publishing goes on",
+			when: func(t *testing.T, dir string) {
+				track(&api.ResultData{File: filepath.Join(dir, "flamegraph.svg.gz")})
+				acknowledge(t, filepath.Join(dir, "flamegraph.svg.gz"))
+				CheckAcknowledgements()
+			},
+		},
+		{
+			name: "should not be retrieved while any result file is not acknowledged",
+			when: func(t *testing.T, dir string) {
+				track(&api.ResultData{File: filepath.Join(dir, "flamegraph.svg.gz")})
+				track(&api.ResultData{File: filepath.Join(dir, "flamegraph-2.svg.gz")})
+				acknowledge(t, filepath.Join(dir, "flamegraph.svg.gz"))
+				CheckAcknowledgements()
+				EndPublishing()
+			},
+		},
+		{
+			name: "should not be retrieved until the acknowledgements are checked",
+			when: func(t *testing.T, dir string) {
+				track(&api.ResultData{File: filepath.Join(dir, "flamegraph.svg.gz")})
+				acknowledge(t, filepath.Join(dir, "flamegraph.svg.gz"))
+				EndPublishing()
+			},
+		},
+		{
+			name: "should not wait for the uploaded result files",
+			when: func(t *testing.T, dir string) {
+				track(&api.ResultData{File: filepath.Join(dir, "flamegraph.svg.gz")})
+				track(&api.ResultData{File: filepath.Join(dir, "flamegraph-2.svg.gz"), Object: "s3://bucket/flamegraph-2.svg.gz"})
+				track(&api.ResultData{File: filepath.Join(dir, "heapdump.gz"), Chunks: []api.ChunkData{
+					{Object: "s3://bucket/heapdump.gz.00"}, {Object: "s3://bucket/heapdump.gz.01"},
+				}})
+				acknowledge(t, filepath.Join(dir, "flamegraph.svg.gz"))
+				CheckAcknowledgements()
+				EndPublishing()
+			},
+			want: true,
+		},
+		{
+			name: "should never be retrieved when no result file was published",
+			when: func(t *testing.T, dir string) {
+				EndPublishing()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetRetrieval()
+			defer resetRetrieval()
+
+			// When
+			tt.when(t, t.TempDir())
+
+			// Then
+			assert.Equal(t, tt.want, isRetrieved())
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package publish
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	fileutils "github.com/josepdcs/kubectl-prof/pkg/util/file"
+	"github.com/josepdcs/kubectl-prof/pkg/util/log"
+	"github.com/pkg/errors"
+)
+
+// ManifestFile is the name of the manifest describing the result files stored in the output directory
+const ManifestFile = "manifest.json"
+
+// Manifest describes the result files stored in the output directory of a profiling session
+type Manifest struct {
+	Results []ManifestEntry `json:"results"`
+}
+
+// ManifestEntry describes a result file stored in the output directory
+type ManifestEntry struct {
+	Time            time.Time      `json:"time"`
+	Iteration       int            `json:"iteration"`
+	ResultType      api.OutputType `json:"result-type"`
+	File            string         `json:"file"` // relative to the output directory
+	FileSizeInBytes int64          `json:"file-size-in-bytes"`
+	Checksum        string         `json:"checksum"`
+	CompressorType  string         `json:"compressor-type,omitempty"`
+}
+
+var (
+	// outputDir is the directory, usually a mounted PersistentVolumeClaim, where the result files are stored;
+	// empty keeps them only in the agent container
+	outputDir string
+	// iteration is the current iteration of the profiling, which names the directory of its result files
+	iteration int
+	// storeMutex serializes the updates of the manifest
+	storeMutex sync.Mutex
+)
+
+// SetOutputDir sets the directory where the result files are stored when publishing the results
+func SetOutputDir(dir string) {
+	outputDir = dir
+}
+
+// SetIteration sets the current iteration of the profiling
+func SetIteration(i int) {
+	iteration = i
+}
+
+// store copies the result file, merging its chunks if any, to the output directory under a folder named by the
+// current iteration, and records it in the manifest. If the copy fails, the result is still published.
+func store(data *api.ResultData) {
+	if outputDir == "" {
+		return
+	}
+
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+
+	relPath := filepath.Join(strconv.Itoa(iteration), filepath.Base(data.File))
+	dst := filepath.Join(outputDir, relPath)
+	if err := storeFile(data, dst); err != nil {
+		log.WarningLogLn(fmt.Sprintf("%v", errors.Wrapf(err, "could not store the result file in %s", outputDir)))
+		return
+	}
+
+	err := appendToManifest(ManifestEntry{
+		Time:            data.Time,
+		Iteration:       iteration,
+		ResultType:      data.ResultType,
+		File:            relPath,
+		FileSizeInBytes: fileutils.Size(dst),
+		Checksum:        fileutils.Checksum(dst),
+		CompressorType:  data.CompressorType,
+	})
+	if err != nil {
+		log.WarningLogLn(fmt.Sprintf("%v", errors.Wrap(err, "could not update the manifest of the stored result files")))
+		return
+	}
+	log.InfoLogLn(fmt.Sprintf("Result file stored in %s", dst))
+}
+
+// storeFile writes the result file, or its chunks in order, to the given destination
+func storeFile(data *api.ResultData, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if len(data.Chunks) == 0 {
+		_, err := fileutils.Copy(data.File, dst)
+		return err
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = out.Close() }()
+	for _, chunk := range data.Chunks {
+		if err := appendFile(out, chunk.File); err != nil {
+			return err
+		}
+	}
+	return out.Sync()
+}
+
+// appendFile appends the content of the given file to the writer
+func appendFile(w io.Writer, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// appendToManifest adds the given entry to the manifest of the output directory, which is replaced atomically
+// so that it can be read at any time
+func appendToManifest(entry ManifestEntry) error {
+	manifestFile := filepath.Join(outputDir, ManifestFile)
+	var manifest Manifest
+	if content, err := os.ReadFile(manifestFile); err == nil {
+		if err := json.Unmarshal(content, &manifest); err != nil {
+			return errors.Wrapf(err, "invalid manifest %s", manifestFile)
+		}
+	}
+	manifest.Results = append(manifest.Results, entry)
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := manifestFile + ".tmp"
+	if err := os.WriteFile(tmp, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, manifestFile)
+}
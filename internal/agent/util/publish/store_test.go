@@ -0,0 +1,65 @@
+package publish
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_store(t *testing.T) {
+	tmp := t.TempDir()
+	file := filepath.Join(tmp, "flamegraph.svg.gz")
+	require.NoError(t, os.WriteFile(file, []byte("result"), 0644))
+	require.NoError(t, os.WriteFile(file+".00", []byte("res"), 0644))
+	require.NoError(t, os.WriteFile(file+".01", []byte("ult"), 0644))
+	now := time.Date(2023, 2, 28, 11, 44, 12, 0, time.UTC)
+
+	// Given
+	dir := filepath.Join(t.TempDir(), "session", "pod")
+	SetOutputDir(dir)
+	defer SetOutputDir("")
+	defer SetIteration(0)
+
+	// When
+	SetIteration(1)
+	store(&api.ResultData{Time: now, ResultType: api.FlameGraph, File: file, CompressorType: "gzip"})
+	SetIteration(2)
+	store(&api.ResultData{Time: now, ResultType: api.HeapDump, File: file, Chunks: []api.ChunkData{{File: file + ".00"}, {File: file + ".01"}}})
+
+	// Then
+	content, err := os.ReadFile(filepath.Join(dir, "1", "flamegraph.svg.gz"))
+	require.NoError(t, err)
+	assert.Equal(t, "result", string(content))
+	content, err = os.ReadFile(filepath.Join(dir, "2", "flamegraph.svg.gz"))
+	require.NoError(t, err)
+	assert.Equal(t, "result", string(content))
+
+	content, err = os.ReadFile(filepath.Join(dir, ManifestFile))
+	require.NoError(t, err)
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(content, &manifest))
+	assert.Equal(t, Manifest{Results: []ManifestEntry{
+		{
+			Time: now, Iteration: 1, ResultType: api.FlameGraph, File: filepath.Join("1", "flamegraph.svg.gz"),
+			FileSizeInBytes: 6, Checksum: "b4a88417b3d0170d754c647c30b7216a", CompressorType: "gzip",
+		},
+		{
+			Time: now, Iteration: 2, ResultType: api.HeapDump, File: filepath.Join("2", "flamegraph.svg.gz"),
+			FileSizeInBytes: 6, Checksum: "b4a88417b3d0170d754c647c30b7216a",
+		},
+	}}, manifest)
+}
+
+func Test_store_WithoutOutputDir(t *testing.T) {
+	data := api.ResultData{File: "/tmp/unknown.svg.gz"}
+
+	store(&data)
+
+	assert.Equal(t, api.ResultData{File: "/tmp/unknown.svg.gz"}, data)
+}
@@ -0,0 +1,101 @@
+package publish
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/josepdcs/kubectl-prof/pkg/util/log"
+	"github.com/josepdcs/kubectl-prof/pkg/util/s3"
+	"github.com/pkg/errors"
+)
+
+// Uploader uploads the result files to an object storage, so that they are not downloaded from the agent container
+type Uploader interface {
+	// Upload uploads the given file and returns the location of the uploaded object
+	Upload(file string) (string, error)
+}
+
+// uploader is the Uploader used when publishing the results; nil keeps the result files in the agent container
+var uploader Uploader
+
+// SetUploader sets the Uploader used when publishing the results
+func SetUploader(u Uploader) {
+	uploader = u
+}
+
+type s3Uploader struct {
+	client   *s3.Client
+	location s3.Location
+}
+
+// NewS3Uploader returns an Uploader to the given s3://bucket/prefix location, under a folder named by the job ID.
+// The configuration of the object storage is read from the AWS_* environment variables.
+func NewS3Uploader(location string, jobID string) (Uploader, error) {
+	l, err := s3.ParseLocation(location)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s3.NewClient(s3.ConfigFromEnv())
+	if err != nil {
+		return nil, err
+	}
+	return &s3Uploader{
+		client:   client,
+		location: l.Join(jobID),
+	}, nil
+}
+
+// Upload uploads the given file and returns its s3://bucket/key location
+func (u *s3Uploader) Upload(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	location := u.location.Join(filepath.Base(file))
+	if err := u.client.Put(location, f, info.Size()); err != nil {
+		return "", err
+	}
+	return location.String(), nil
+}
+
+// upload uploads the result file, or its chunks, replacing their paths in the agent container by the locations
+// of the uploaded objects. If the upload fails, the result is still published with the paths in the agent container.
+func upload(data *api.ResultData) {
+	if uploader == nil {
+		return
+	}
+
+	if len(data.Chunks) == 0 {
+		object, err := uploader.Upload(data.File)
+		if err != nil {
+			log.WarningLogLn(fmt.Sprintf("%v; the result file is kept in the agent container", errors.Wrap(err, "could not upload the result file")))
+			return
+		}
+		data.File, data.URL, data.Object = "", "", object
+		return
+	}
+
+	objects := make([]string, len(data.Chunks))
+	for i, chunk := range data.Chunks {
+		object, err := uploader.Upload(chunk.File)
+		if err != nil {
+			log.WarningLogLn(fmt.Sprintf("%v; the result file is kept in the agent container", errors.Wrap(err, "could not upload the result file chunk")))
+			return
+		}
+		objects[i] = object
+	}
+	for i := range data.Chunks {
+		data.Chunks[i].File, data.Chunks[i].URL, data.Chunks[i].Object = "", "", objects[i]
+	}
+	// the whole file is not uploaded, only its chunks, but its location names the result
+	data.Object = objects[0][:len(objects[0])-len(filepath.Ext(objects[0]))]
+	data.File, data.URL = "", ""
+}
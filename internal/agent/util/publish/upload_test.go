@@ -0,0 +1,103 @@
+package publish
+
+import (
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/josepdcs/kubectl-prof/pkg/util/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingUploader fails every upload
+type failingUploader struct{}
+
+func (failingUploader) Upload(string) (string, error) {
+	return "", errors.New("access denied")
+}
+
+func newS3Uploader(t *testing.T) *s3.FakeServer {
+	fake := s3.NewFakeServer()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	t.Setenv(s3.EnvEndpoint, server.URL)
+	t.Setenv(s3.EnvAccessKeyID, "key")
+	t.Setenv(s3.EnvSecretAccessKey, "secret")
+
+	u, err := NewS3Uploader("s3://bucket/profiling", "ID")
+	require.NoError(t, err)
+	SetUploader(u)
+	t.Cleanup(func() { SetUploader(nil) })
+	return fake
+}
+
+func Test_upload(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "flamegraph.svg.gz")
+	require.NoError(t, os.WriteFile(file, []byte("result"), 0644))
+	require.NoError(t, os.WriteFile(file+".00", []byte("res"), 0644))
+	require.NoError(t, os.WriteFile(file+".01", []byte("ult"), 0644))
+
+	t.Run("should upload the result file", func(t *testing.T) {
+		fake := newS3Uploader(t)
+		data := api.ResultData{File: file, URL: "/files/flamegraph.svg.gz", Checksum: "checksum"}
+
+		upload(&data)
+
+		assert.Equal(t, api.ResultData{Object: "s3://bucket/profiling/ID/flamegraph.svg.gz", Checksum: "checksum"}, data)
+		content, ok := fake.Object(s3.Location{Bucket: "bucket", Key: "profiling/ID/flamegraph.svg.gz"})
+		assert.True(t, ok)
+		assert.Equal(t, "result", string(content))
+	})
+
+	t.Run("should upload the chunks of the result file", func(t *testing.T) {
+		fake := newS3Uploader(t)
+		data := api.ResultData{File: file, Chunks: []api.ChunkData{{File: file + ".00"}, {File: file + ".01"}}}
+
+		upload(&data)
+
+		assert.Equal(t, api.ResultData{
+			Object: "s3://bucket/profiling/ID/flamegraph.svg.gz",
+			Chunks: []api.ChunkData{
+				{Object: "s3://bucket/profiling/ID/flamegraph.svg.gz.00"},
+				{Object: "s3://bucket/profiling/ID/flamegraph.svg.gz.01"},
+			},
+		}, data)
+		content, ok := fake.Object(s3.Location{Bucket: "bucket", Key: "profiling/ID/flamegraph.svg.gz.01"})
+		assert.True(t, ok)
+		assert.Equal(t, "ult", string(content))
+	})
+
+	t.Run("should keep the result file in the agent container when the upload fails", func(t *testing.T) {
+		SetUploader(failingUploader{})
+		defer SetUploader(nil)
+		data := api.ResultData{File: file, Chunks: []api.ChunkData{{File: file + ".00"}}}
+
+		upload(&data)
+
+		assert.Equal(t, api.ResultData{File: file, Chunks: []api.ChunkData{{File: file + ".00"}}}, data)
+	})
+
+	t.Run("should not upload without uploader", func(t *testing.T) {
+		data := api.ResultData{File: file}
+
+		upload(&data)
+
+		assert.Equal(t, api.ResultData{File: file}, data)
+	})
+}
+
+func TestNewS3Uploader(t *testing.T) {
+	t.Setenv(s3.EnvAccessKeyID, "")
+	t.Setenv(s3.EnvSecretAccessKey, "")
+
+	_, err := NewS3Uploader("bucket/profiling", "ID")
+	require.EqualError(t, err, "invalid location bucket/profiling, it must be given as s3://bucket/prefix")
+
+	_, err = NewS3Uploader("s3://bucket/profiling", "ID")
+	require.EqualError(t, err, "the credentials of the object storage are missing")
+}
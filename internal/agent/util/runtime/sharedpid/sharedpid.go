@@ -0,0 +1,79 @@
+package sharedpid
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// procRoot is the location of the proc filesystem
+var procRoot = "/proc"
+
+// SharedPID resolves the target container from an ephemeral container sharing its process namespace,
+// so the container runtime path is not needed
+type SharedPID struct {
+}
+
+func NewSharedPID() *SharedPID {
+	return &SharedPID{}
+}
+
+// RootFileSystemLocation returns the root filesystem location of the container
+func (s *SharedPID) RootFileSystemLocation(containerID string, _ string) (string, error) {
+	pid, err := rootPID(containerID)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(procRoot, pid, "root"), nil
+}
+
+// PID returns the PID of the container
+func (s *SharedPID) PID(containerID string, _ string) (string, error) {
+	return rootPID(containerID)
+}
+
+// CWD returns the current working directory of the root process of the container
+func (s *SharedPID) CWD(containerID string, _ string) (string, error) {
+	pid, err := rootPID(containerID)
+	if err != nil {
+		return "", err
+	}
+	cwd, err := os.Readlink(filepath.Join(procRoot, pid, "cwd"))
+	if err != nil {
+		return "", errors.Wrap(err, "could not read the current working directory of the target container")
+	}
+	return cwd, nil
+}
+
+// rootPID returns the PID of the root process of the given container, that is the lowest one among the processes
+// whose cgroup belongs to the container. PID 1 is not assumed to be the one of the container, since it is the pause
+// process when the pod shares its process namespace between its containers.
+func rootPID(containerID string) (string, error) {
+	if containerID == "" {
+		return "", errors.New("container ID is mandatory")
+	}
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not read %s", procRoot)
+	}
+
+	root := 0
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() || (root != 0 && pid > root) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(procRoot, entry.Name(), "cgroup"))
+		if err != nil || !strings.Contains(string(content), containerID) {
+			continue
+		}
+		root = pid
+	}
+	if root == 0 {
+		return "", errors.Errorf("root process of the target container %s not found, is the process namespace shared?", containerID)
+	}
+	return strconv.Itoa(root), nil
+}
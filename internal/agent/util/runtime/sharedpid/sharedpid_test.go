@@ -0,0 +1,110 @@
+package sharedpid
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const containerID = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// withProcRoot fakes the proc filesystem with the pause process as PID 1, along with the given processes of the
+// target container, whose current working directory is /app
+func withProcRoot(t *testing.T, pids ...string) {
+	original := procRoot
+	procRoot = t.TempDir()
+	t.Cleanup(func() { procRoot = original })
+
+	process := func(pid, cgroup string) {
+		require.NoError(t, os.Mkdir(filepath.Join(procRoot, pid), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(procRoot, pid, "cgroup"), []byte(cgroup), 0644))
+		require.NoError(t, os.Symlink("/app", filepath.Join(procRoot, pid, "cwd")))
+	}
+	process("1", "0::/kubepods.slice/cri-containerd-fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210.scope\n")
+	for _, pid := range pids {
+		process(pid, "0::/kubepods.slice/cri-containerd-"+containerID+".scope\n")
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(procRoot, "self"), 0755))
+}
+
+func TestRootFileSystemLocation(t *testing.T) {
+	withProcRoot(t, "7")
+
+	location, err := NewSharedPID().RootFileSystemLocation(containerID, "")
+
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(procRoot, "7", "root"), location)
+}
+
+func TestPID(t *testing.T) {
+	tests := []struct {
+		name            string
+		pids            []string
+		containerID     string
+		expected        string
+		containedErrMsg string
+	}{
+		{
+			name:        "expect the lowest PID of the target container rather than the pause process",
+			pids:        []string{"12", "7", "30"},
+			containerID: containerID,
+			expected:    "7",
+		},
+		{
+			name:            "root process not found",
+			containerID:     containerID,
+			containedErrMsg: "root process of the target container " + containerID + " not found",
+		},
+		{
+			name:            "container ID not given",
+			pids:            []string{"7"},
+			containedErrMsg: "container ID is mandatory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withProcRoot(t, tt.pids...)
+
+			pid, err := NewSharedPID().PID(tt.containerID, "")
+
+			if err != nil {
+				assert.Contains(t, err.Error(), tt.containedErrMsg)
+			}
+			assert.Equal(t, tt.expected, pid)
+		})
+	}
+}
+
+func TestCWD(t *testing.T) {
+	tests := []struct {
+		name            string
+		pids            []string
+		expected        string
+		containedErrMsg string
+	}{
+		{
+			name:     "expect current working directory",
+			pids:     []string{"7"},
+			expected: "/app",
+		},
+		{
+			name:            "root process not found",
+			containedErrMsg: "root process of the target container",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withProcRoot(t, tt.pids...)
+
+			cwd, err := NewSharedPID().CWD(containerID, "")
+
+			if err != nil {
+				assert.Contains(t, err.Error(), tt.containedErrMsg)
+			}
+			assert.Equal(t, tt.expected, cwd)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package version
+
+import "runtime/debug"
+
+// populated at build time
+var (
+	semver string
+)
+
+// GetCurrent returns the version of the agent. If it is not set at build time, the version of the module
+// the agent was built from is returned.
+func GetCurrent() string {
+	if semver != "" {
+		return semver
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Main.Version
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/josepdcs/kubectl-prof/internal/cli"
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes"
+	"github.com/josepdcs/kubectl-prof/internal/cli/profiler"
+	apiprof "github.com/josepdcs/kubectl-prof/internal/cli/profiler/api"
+	"github.com/josepdcs/kubectl-prof/internal/cli/session"
+	"github.com/josepdcs/kubectl-prof/pkg/util/compressor"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+const attachExamples = `
+	# Reattach to the profiling session 6f1c9a2e-... and download the result files not downloaded yet
+	%[1]s prof attach 6f1c9a2e-4b1d-4a57-9a3e-2d0c7f5e8b11
+
+	# Reattach to a profiling session whose job was launched in the profiling namespace
+	%[1]s prof attach 6f1c9a2e-4b1d-4a57-9a3e-2d0c7f5e8b11 -n profiling --local-path=/tmp/results
+
+	# Reattach to a profiling session launched as an ephemeral container into a pod of the namespace my-namespace
+	%[1]s prof attach 6f1c9a2e-4b1d-4a57-9a3e-2d0c7f5e8b11 -n my-namespace
+`
+
+// NewAttach returns a new cobra.Command for the "attach" subcommand.
+// This command reattaches to a running profiling session, e.g. after losing the connection, and downloads its results.
+func NewAttach(streams genericiooptions.IOStreams) *cobra.Command {
+	var (
+		target        config.TargetConfig
+		outputFormat  string
+		recordSession string
+	)
+
+	options := NewProfileOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "attach SESSION_ID",
+		DisableFlagsInUseLine: true,
+		Short:                 "Reattach to a running profiling session and download the result files not downloaded yet",
+		Long: `Reattach to a running profiling session given by its id (see "prof list").
+
+The agent is looked up as the ephemeral container kubectl-prof-<id> of a pod of the namespace,
+and then as the container of a profiling job.
+
+The log stream of the agent is replayed from the start and the result files already present
+in the local path are skipped. The agent keeps the result files until its grace period ends.
+`,
+		Example: fmt.Sprintf(attachExamples, "kubectl"),
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := setOutputFormat(outputFormat, streams); err != nil {
+				_, _ = fmt.Fprintln(streams.ErrOut, err)
+				os.Exit(int(cli.ExitInvalidArguments))
+			}
+
+			connectionInfo, err := kubernetes.Connect(options.configFlags)
+			if err != nil {
+				exit(streams, errors.Wrap(err, "failed connecting to kubernetes cluster"))
+			}
+
+			target.Id = args[0]
+			target.Compressor = compressor.Type(defaultCompressor)
+			target.PoolSizeRetrieveChunks = defaultPoolSizeRetrieveChunks
+			target.RetrieveFileRetries = defaultRetrieveFileRetries
+			cfg, err := config.NewProfilerConfig(&target, config.WithJob(&config.JobConfig{Namespace: connectionInfo.Namespace}))
+			if err != nil {
+				exit(streams, cli.NewError(cli.ExitInvalidArguments, err))
+			}
+
+			var recorder *session.Recorder
+			if recordSession != "" {
+				recorder, err = session.NewRecorder(recordSession, cfg)
+				if err != nil {
+					exit(streams, err)
+				}
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			err = profiler.New(
+				apiprof.NewPodApi(connectionInfo),
+				apiprof.NewProfilingJobApi(connectionInfo),
+				apiprof.NewProfilingContainerApi(connectionInfo),
+				apiprof.NewProfilingEphemeralContainerApi(connectionInfo),
+			).WithRecorder(recorder).Attach(ctx, cfg)
+			if recorder != nil {
+				_ = recorder.Close()
+			}
+			if err != nil {
+				printer := cli.NewPrinter(false)
+				printer.Print("Attaching failed ... ")
+				printer.PrintError()
+				printer.Print("😥 " + err.Error())
+			}
+			if code := cli.Finish(err); code != int(cli.ExitOK) {
+				os.Exit(code)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&outputFormat, "output-format", string(cli.TextOutput), fmt.Sprintf("Format of what the CLI reports. Choose one of: %v", cli.AvailableOutputFormats()))
+	cmd.Flags().StringVar(&target.LocalPath, "local-path", "", "Local directory where result files are saved. Defaults to the current working directory")
+	cmd.Flags().BoolVar(&target.PrintAgentLogs, "print-agent-logs", false, "Stream agent container logs to the local standard output")
+	cmd.Flags().StringVar(&recordSession, "record-session", "", "Record the session into this file, see the replay subcommand")
+	cmd.Flags().BoolVar(&target.UploadFetch, "upload-fetch", true, "Download the result files uploaded by the agent from the object storage. If false, only their locations are printed")
+	options.configFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
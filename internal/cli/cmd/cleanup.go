@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes"
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes/job"
+	apiprof "github.com/josepdcs/kubectl-prof/internal/cli/profiler/api"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+const cleanupExamples = `
+	# Delete the finished and orphaned profiling jobs of the current namespace
+	%[1]s prof cleanup
+
+	# Delete the finished and orphaned profiling jobs of all namespaces created more than one hour ago
+	%[1]s prof cleanup -A --older-than 1h
+
+	# Delete every profiling job of all namespaces, even the running ones
+	%[1]s prof cleanup -A --all
+`
+
+// cleanupFlags represents the options for choosing the profiling jobs to be deleted.
+type cleanupFlags struct {
+	allNamespaces bool
+	all           bool
+	olderThan     time.Duration
+}
+
+// NewCleanup returns a new cobra.Command for the "cleanup" subcommand.
+// This command deletes the finished and orphaned profiling jobs launched by kubectl-prof.
+func NewCleanup(streams genericiooptions.IOStreams) *cobra.Command {
+	var flags cleanupFlags
+
+	options := NewProfileOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "cleanup [-A] [--all] [--older-than duration]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Delete the finished and orphaned profiling jobs launched by kubectl-prof",
+		Example:               fmt.Sprintf(cleanupExamples, "kubectl"),
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			connectionInfo, err := kubernetes.Connect(options.configFlags)
+			if err != nil {
+				_, _ = fmt.Fprintf(streams.ErrOut, "Failed connecting to kubernetes cluster: %v\n", err)
+				os.Exit(1)
+			}
+
+			namespace := connectionInfo.Namespace
+			if flags.allNamespaces {
+				namespace = ""
+			}
+
+			err = cleanupProfilingJobs(cmd.Context(), streams.Out, apiprof.NewProfilingJobApi(connectionInfo),
+				apiprof.NewPodApi(connectionInfo), namespace, flags, time.Now())
+			if err != nil {
+				_, _ = fmt.Fprintln(streams.ErrOut, err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&flags.allNamespaces, "all-namespaces", "A", false, "Clean up the profiling jobs of all namespaces")
+	cmd.Flags().BoolVar(&flags.all, "all", false, "Delete every profiling job, including the running ones whose target pod still exists")
+	cmd.Flags().DurationVar(&flags.olderThan, "older-than", 0, "Only delete the profiling jobs created before this duration (e.g. 30m, 2h)")
+	options.configFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+// cleanupProfilingJobs deletes the profiling jobs of the given namespace (all namespaces if empty) chosen by the flags.
+// Unless --all is given, only the finished jobs and the orphaned ones (i.e. whose target pod no longer exists) are deleted.
+func cleanupProfilingJobs(ctx context.Context, out io.Writer, jobApi apiprof.ProfilingJobApi, podApi apiprof.PodApi,
+	namespace string, flags cleanupFlags, now time.Time) error {
+	jobs, err := jobApi.GetProfilingJobs(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	var deleted int
+	for i := range jobs {
+		info := job.InfoOf(&jobs[i])
+		if flags.olderThan > 0 && info.Age(now) < flags.olderThan {
+			continue
+		}
+
+		reason, ok, err := cleanupReason(ctx, podApi, info, flags)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		if err := jobApi.DeleteProfilingJob(&jobs[i], ctx); err != nil {
+			return err
+		}
+		deleted++
+		_, _ = fmt.Fprintf(out, "Profiling job %s/%s deleted (%s)\n", info.Namespace, info.Name, reason)
+	}
+
+	if deleted == 0 {
+		_, _ = fmt.Fprintln(out, "No profiling jobs to clean up")
+	}
+
+	return nil
+}
+
+// cleanupReason returns why the profiling job has to be deleted, or false if it has to be kept.
+func cleanupReason(ctx context.Context, podApi apiprof.PodApi, info job.Info, flags cleanupFlags) (string, bool, error) {
+	if info.IsFinished() {
+		return string(info.Phase), true, nil
+	}
+
+	namespace, podName := info.TargetPod()
+	if podName != "" {
+		_, err := podApi.GetPod(ctx, podName, namespace)
+		if apierrors.IsNotFound(err) {
+			return "orphaned, target pod not found", true, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+	}
+
+	if flags.all {
+		return string(info.Phase), true, nil
+	}
+
+	return "", false, nil
+}
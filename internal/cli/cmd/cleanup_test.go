@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/internal/cli/profiler/api"
+	"github.com/josepdcs/kubectl-prof/internal/cli/profiler/api/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func Test_cleanupProfilingJobs(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	running := newProfilingJob("1", "profiling", "pod/my-pod", now.Add(-5*time.Minute), batchv1.JobStatus{Active: 1})
+	oldRunning := newProfilingJob("2", "profiling", "pod/my-pod", now.Add(-3*time.Hour), batchv1.JobStatus{Active: 1})
+	failed := newProfilingJob("3", "profiling", "pod/my-pod", now.Add(-2*time.Hour), batchv1.JobStatus{Failed: 1})
+	nodeWide := newProfilingJob("4", "other", "node/worker-1", now.Add(-10*time.Minute), batchv1.JobStatus{Active: 1})
+
+	tests := []struct {
+		name        string
+		jobApi      fake.ProfilingJobApi
+		podApi      api.PodApi
+		flags       cleanupFlags
+		namespace   string
+		wantDeleted int
+		wantErr     string
+	}{
+		{
+			name:        "should delete only the finished profiling jobs when the target pods exist",
+			jobApi:      fake.NewProfilingJobApi().WithProfilingJobs(running, oldRunning, failed, nodeWide),
+			podApi:      fake.NewPodApi(),
+			wantDeleted: 1,
+		},
+		{
+			name:        "should delete the orphaned profiling jobs",
+			jobApi:      fake.NewProfilingJobApi().WithProfilingJobs(running, oldRunning, failed, nodeWide),
+			podApi:      fake.NewPodApi().WithReturnsNotFound(),
+			wantDeleted: 3,
+		},
+		{
+			name:        "should delete every profiling job when all is given",
+			jobApi:      fake.NewProfilingJobApi().WithProfilingJobs(running, oldRunning, failed, nodeWide),
+			podApi:      fake.NewPodApi(),
+			flags:       cleanupFlags{all: true},
+			wantDeleted: 4,
+		},
+		{
+			name:        "should delete every profiling job of a namespace when all is given",
+			jobApi:      fake.NewProfilingJobApi().WithProfilingJobs(running, oldRunning, failed, nodeWide),
+			podApi:      fake.NewPodApi(),
+			flags:       cleanupFlags{all: true},
+			namespace:   "profiling",
+			wantDeleted: 3,
+		},
+		{
+			name:        "should delete only the profiling jobs older than the given duration",
+			jobApi:      fake.NewProfilingJobApi().WithProfilingJobs(running, oldRunning, failed, nodeWide),
+			podApi:      fake.NewPodApi(),
+			flags:       cleanupFlags{all: true, olderThan: time.Hour},
+			wantDeleted: 2,
+		},
+		{
+			name:    "should fail when get the target pod fail",
+			jobApi:  fake.NewProfilingJobApi().WithProfilingJobs(running),
+			podApi:  fake.NewPodApi().WithReturnsError(),
+			wantErr: "error getting pod",
+		},
+		{
+			name:    "should fail when get profiling jobs fail",
+			jobApi:  fake.NewProfilingJobApi().WithGetProfilingJobsReturnsError(),
+			podApi:  fake.NewPodApi(),
+			wantErr: "error getting profiling jobs",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			out := &bytes.Buffer{}
+
+			// When
+			err := cleanupProfilingJobs(context.TODO(), out, tt.jobApi, tt.podApi, tt.namespace, tt.flags, now)
+
+			// Then
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDeleted, tt.jobApi.DeletedJobs())
+		})
+	}
+}
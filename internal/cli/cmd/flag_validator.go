@@ -3,11 +3,17 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path"
+	"slices"
+	"strings"
 
 	"github.com/agrison/go-commons-lang/stringUtils"
 	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/josepdcs/kubectl-prof/internal/cli"
 	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes/job"
 	"github.com/josepdcs/kubectl-prof/pkg/util/compressor"
+	"github.com/josepdcs/kubectl-prof/pkg/util/s3"
 	"github.com/pkg/errors"
 	apiv1 "k8s.io/api/core/v1"
 )
@@ -152,12 +158,103 @@ func (v *profilingToolAndOutputValidator) validate(flags *profilingFlags, target
 	return v.validateNext(flags, target, job)
 }
 
-// resourcesValidator validates requested resources, limits and tolerations for the job.
+// nodeTargetValidator validates that the profiling tool is able to profile a whole node.
+type nodeTargetValidator struct {
+	baseFlagValidator
+}
+
+// validate checks if the selected profiling tool supports node-wide profiling when the target is a node.
+func (v *nodeTargetValidator) validate(flags *profilingFlags, target *config.TargetConfig, job *config.JobConfig) error {
+	if target.Kind == config.Node && !slices.Contains(nodeWideProfilingTools, target.ProfilingTool) {
+		return errors.New(fmt.Sprintf("Unsupported profiling tool %s for node target, choose one of %v", target.ProfilingTool, nodeWideProfilingTools))
+	}
+	return v.validateNext(flags, target, job)
+}
+
+// launchModeValidator validates the launch mode of the agent.
+type launchModeValidator struct {
+	baseFlagValidator
+}
+
+// validate checks if the launch mode is supported and applicable to the target.
+func (v *launchModeValidator) validate(flags *profilingFlags, target *config.TargetConfig, job *config.JobConfig) error {
+	if stringUtils.IsBlank(flags.launchMode) {
+		flags.launchMode = string(config.JobLaunchMode)
+	}
+	if !config.IsSupportedLaunchMode(flags.launchMode) {
+		return errors.Errorf("unsupported launch mode, choose one of %s", config.AvailableLaunchModes())
+	}
+	if config.LaunchMode(flags.launchMode) == config.EphemeralLaunchMode && target.Kind == config.Node {
+		return errors.New("ephemeral launch mode requires a target pod, it cannot be used with a node target")
+	}
+	return v.validateNext(flags, target, job)
+}
+
+// securityProfileValidator validates the privileges granted to the agent.
+type securityProfileValidator struct {
+	baseFlagValidator
+}
+
+// validate checks if the security profile is supported and explains what the minimal one grants to the profiling tool.
+func (v *securityProfileValidator) validate(flags *profilingFlags, target *config.TargetConfig, job *config.JobConfig) error {
+	securityProfile, err := validateSecurityProfile(flags.securityProfile, flags.capabilities, config.LaunchMode(flags.launchMode), target)
+	if err != nil {
+		return err
+	}
+	job.SecurityProfile = securityProfile
+	return v.validateNext(flags, target, job)
+}
+
+// uploadValidator validates the object storage where the agent uploads the result files.
+type uploadValidator struct {
+	baseFlagValidator
+}
+
+// validate checks if the upload location is valid and the Secret with its configuration is given.
+func (v *uploadValidator) validate(flags *profilingFlags, target *config.TargetConfig, job *config.JobConfig) error {
+	if stringUtils.IsBlank(target.UploadTo) {
+		return v.validateNext(flags, target, job)
+	}
+	if _, err := s3.ParseLocation(target.UploadTo); err != nil {
+		return err
+	}
+	if stringUtils.IsBlank(target.UploadSecret) {
+		return errors.New("upload secret is mandatory when uploading the result files")
+	}
+	return v.validateNext(flags, target, job)
+}
+
+// outputValidator validates the PersistentVolumeClaim where the agent stores the result files and the detached mode.
+type outputValidator struct {
+	baseFlagValidator
+}
+
+// validate checks if the claim is valid for the launch mode and if the results are persisted when detaching.
+func (v *outputValidator) validate(flags *profilingFlags, target *config.TargetConfig, job *config.JobConfig) error {
+	if stringUtils.IsNotBlank(target.OutputPVC) {
+		claim, subPath := target.OutputVolume()
+		if stringUtils.IsBlank(claim) {
+			return errors.New("invalid output pvc, it must be given as <claim>[:subpath]")
+		}
+		if path.IsAbs(subPath) || slices.Contains(strings.Split(subPath, "/"), "..") {
+			return errors.Errorf("invalid output pvc sub path %s, it must be a relative path", subPath)
+		}
+		if config.LaunchMode(flags.launchMode) == config.EphemeralLaunchMode {
+			return errors.New("output pvc cannot be used with ephemeral launch mode, ephemeral containers cannot mount volumes")
+		}
+	}
+	if target.Detach && stringUtils.IsBlank(target.OutputPVC) && stringUtils.IsBlank(target.UploadTo) {
+		return errors.New("detach requires the result files to be persisted with --output-pvc or --upload-to")
+	}
+	return v.validateNext(flags, target, job)
+}
+
+// resourcesValidator validates requested resources, limits, tolerations and the template for the job.
 type resourcesValidator struct {
 	baseFlagValidator
 }
 
-// validate checks if resource and toleration configurations are valid for the job.
+// validate checks if resource, toleration and template configurations are valid for the job.
 func (v *resourcesValidator) validate(flags *profilingFlags, target *config.TargetConfig, job *config.JobConfig) error {
 	if _, err := job.RequestConfig.ParseResources(); err != nil {
 		return errors.Wrapf(err, "unable to parse resource requests")
@@ -168,6 +265,12 @@ func (v *resourcesValidator) validate(flags *profilingFlags, target *config.Targ
 	if err := job.ParseTolerations(); err != nil {
 		return errors.Wrapf(err, "unable to parse tolerations")
 	}
+	if err := job.ParseTemplate(); err != nil {
+		return err
+	}
+	if len(job.Template) > 0 && config.LaunchMode(flags.launchMode) == config.EphemeralLaunchMode {
+		return errors.New("job template cannot be used with ephemeral launch mode, no job is created")
+	}
 	return v.validateNext(flags, target, job)
 }
 
@@ -206,6 +309,11 @@ func validateFlags(flags *profilingFlags, target *config.TargetConfig, job *conf
 		setNext(&compressorValidator{}).
 		setNext(&imagePullPolicyValidator{}).
 		setNext(&profilingToolAndOutputValidator{}).
+		setNext(&nodeTargetValidator{}).
+		setNext(&launchModeValidator{}).
+		setNext(&securityProfileValidator{}).
+		setNext(&uploadValidator{}).
+		setNext(&outputValidator{}).
 		setNext(&resourcesValidator{}).
 		setNext(&localPathValidator{}).
 		setNext(&pidValidator{})
@@ -234,6 +342,31 @@ func validateRuntime(runtime string, target *config.TargetConfig) (string, error
 	return runtime, nil
 }
 
+// validateSecurityProfile checks if the security profile is supported.
+// If none is provided, the privileged one is used. With the minimal one, what is granted to the agent is reported.
+func validateSecurityProfile(securityProfile string, capabilities []string, launchMode config.LaunchMode, target *config.TargetConfig) (config.SecurityProfile, error) {
+	if stringUtils.IsBlank(securityProfile) {
+		return config.PrivilegedSecurityProfile, nil
+	}
+	if !config.IsSupportedSecurityProfile(securityProfile) {
+		return "", errors.Errorf("unsupported security profile, choose one of %s", config.AvailableSecurityProfiles())
+	}
+	if config.SecurityProfile(securityProfile) == config.PrivilegedSecurityProfile {
+		return config.PrivilegedSecurityProfile, nil
+	}
+
+	added := make([]apiv1.Capability, len(capabilities))
+	for i, capability := range capabilities {
+		added[i] = apiv1.Capability(capability)
+	}
+	granted, err := job.DescribeMinimalPrivileges(target, added, launchMode)
+	if err != nil {
+		return "", err
+	}
+	notice("Minimal security profile grants the agent: %s ... 🔒\n", granted)
+	return config.MinimalSecurityProfile, nil
+}
+
 // validateLang checks if the programming language is supported.
 func validateLang(lang string) error {
 	if lang == "" {
@@ -251,19 +384,19 @@ func validateLang(lang string) error {
 func validateProfilingTool(profilingTool string, outputType string, target *config.TargetConfig) {
 	if stringUtils.IsBlank(profilingTool) {
 		target.ProfilingTool = api.GetProfilingTool(target.Language, api.OutputType(outputType))
-		fmt.Printf("Default profiling tool %s will be used ... 🧐\n", target.ProfilingTool)
+		notice("Default profiling tool %s will be used ... 🧐\n", target.ProfilingTool)
 		return
 	}
 
 	defaultTool := api.GetProfilingToolsByProgrammingLanguage[target.Language][0]
 	if !api.IsSupportedProfilingTool(profilingTool) {
-		fmt.Printf("Unsupported profiling tool %s, default %s will be used ... 🧐\n", profilingTool, defaultTool)
+		notice("Unsupported profiling tool %s, default %s will be used ... 🧐\n", profilingTool, defaultTool)
 		target.ProfilingTool = defaultTool
 		return
 	}
 
 	if !api.IsValidProfilingTool(api.ProfilingTool(profilingTool), target.Language) {
-		fmt.Printf("Unsupported profiling tool %s for language %s, default %s will be used ... 🧐\n",
+		notice("Unsupported profiling tool %s for language %s, default %s will be used ... 🧐\n",
 			profilingTool, target.Language, defaultTool)
 		target.ProfilingTool = defaultTool
 		return
@@ -277,19 +410,19 @@ func validateProfilingTool(profilingTool string, outputType string, target *conf
 func validateOutputType(outputType string, target *config.TargetConfig) {
 	defaultOutputType := api.GetOutputTypesByProfilingTool[target.ProfilingTool][0]
 	if outputType == "" {
-		fmt.Printf("Default output type %s will be used ... 🧐\n", defaultOutputType)
+		notice("Default output type %s will be used ... 🧐\n", defaultOutputType)
 		target.OutputType = defaultOutputType
 		return
 	}
 
 	if !api.IsSupportedOutputType(outputType) {
-		fmt.Printf("Unsupported output type %s, default %s will be used ... ✔\n", outputType, defaultOutputType)
+		notice("Unsupported output type %s, default %s will be used ... ✔\n", outputType, defaultOutputType)
 		target.OutputType = defaultOutputType
 		return
 	}
 
 	if !api.IsValidOutputType(api.OutputType(outputType), target.ProfilingTool) {
-		fmt.Printf("Unsupported output type %s for profiling tool %s, default %s will be used ... ✔\n",
+		notice("Unsupported output type %s for profiling tool %s, default %s will be used ... ✔\n",
 			outputType, target.ProfilingTool, defaultOutputType)
 		target.OutputType = defaultOutputType
 		return
@@ -298,6 +431,13 @@ func validateOutputType(outputType string, target *config.TargetConfig) {
 	target.OutputType = api.OutputType(outputType)
 }
 
+// notice prints the given message about a default being used, which is reported as a notice with a structured output
+func notice(format string, a ...any) {
+	text := fmt.Sprintf(format, a...)
+	msg, _, _ := strings.Cut(text, " ...")
+	cli.NewPrinter(false).Report(text, cli.Record{Stage: cli.Notice, Message: msg})
+}
+
 // isSupportedImagePullPolicy checks if the image pull policy is supported.
 func isSupportedImagePullPolicy(imagePullPolicy string) bool {
 	for _, current := range imagePullPolicies {
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes"
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes/job"
+	apiprof "github.com/josepdcs/kubectl-prof/internal/cli/profiler/api"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+const listExamples = `
+	# List the profiling jobs of the current namespace
+	%[1]s prof list
+
+	# List the profiling jobs of all namespaces
+	%[1]s prof list -A
+`
+
+// NewList returns a new cobra.Command for the "list" subcommand.
+// This command shows the in-flight and orphaned profiling jobs launched by kubectl-prof.
+func NewList(streams genericiooptions.IOStreams) *cobra.Command {
+	var allNamespaces bool
+
+	options := NewProfileOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "list [-A]",
+		DisableFlagsInUseLine: true,
+		Short:                 "List the profiling jobs launched by kubectl-prof, showing their id, target, tool, node, age and phase",
+		Example:               fmt.Sprintf(listExamples, "kubectl"),
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			connectionInfo, err := kubernetes.Connect(options.configFlags)
+			if err != nil {
+				_, _ = fmt.Fprintf(streams.ErrOut, "Failed connecting to kubernetes cluster: %v\n", err)
+				os.Exit(1)
+			}
+
+			namespace := connectionInfo.Namespace
+			if allNamespaces {
+				namespace = ""
+			}
+
+			err = listProfilingJobs(cmd.Context(), streams.Out, apiprof.NewProfilingJobApi(connectionInfo), namespace, time.Now())
+			if err != nil {
+				_, _ = fmt.Fprintln(streams.ErrOut, err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "List the profiling jobs of all namespaces")
+	options.configFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+// listProfilingJobs prints a table with the profiling jobs of the given namespace (all namespaces if empty).
+func listProfilingJobs(ctx context.Context, out io.Writer, jobApi apiprof.ProfilingJobApi, namespace string, now time.Time) error {
+	jobs, err := jobApi.GetProfilingJobs(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	if len(jobs) == 0 {
+		_, _ = fmt.Fprintln(out, "No profiling jobs found")
+		return nil
+	}
+
+	w := printers.GetNewTabWriter(out)
+	_, _ = fmt.Fprintln(w, "ID\tNAMESPACE\tTARGET\tTOOL\tNODE\tAGE\tPHASE")
+	for i := range jobs {
+		info := job.InfoOf(&jobs[i])
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", info.ID, info.Namespace, orNone(targetOf(info)), orNone(info.Tool),
+			orNone(info.Node), duration.HumanDuration(info.Age(now)), info.Phase)
+	}
+
+	return w.Flush()
+}
+
+// targetOf returns the target of the profiling job as namespace/pod[/container], or the node for node-wide profiling.
+func targetOf(info job.Info) string {
+	namespace, pod := info.TargetPod()
+	if pod == "" {
+		return info.Target
+	}
+	target := namespace + "/" + pod
+	if info.TargetContainer != "" {
+		target += "/" + info.TargetContainer
+	}
+	return target
+}
+
+// orNone returns <none> when the given value is empty, as kubectl does.
+func orNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes/job"
+	"github.com/josepdcs/kubectl-prof/internal/cli/profiler/api/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newProfilingJob returns a profiling job as built by the job creators for unit test purposes
+func newProfilingJob(id, namespace, target string, created time.Time, status batchv1.JobStatus) batchv1.Job {
+	return batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kubectl-prof-perf-" + id,
+			Namespace: namespace,
+			Labels:    map[string]string{job.LabelID: id},
+			Annotations: map[string]string{
+				job.AnnotationTarget:          target,
+				job.AnnotationTargetNamespace: "apps",
+				job.AnnotationTargetContainer: "app",
+				job.AnnotationTool:            "perf",
+			},
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: batchv1.JobSpec{
+			Template: apiv1.PodTemplateSpec{
+				Spec: apiv1.PodSpec{NodeName: "worker-1"},
+			},
+		},
+		Status: status,
+	}
+}
+
+func Test_listProfilingJobs(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		jobApi    fake.ProfilingJobApi
+		namespace string
+		then      func(t *testing.T, out string, err error)
+	}{
+		{
+			name: "should list the profiling jobs of a namespace",
+			jobApi: fake.NewProfilingJobApi().WithProfilingJobs(
+				newProfilingJob("1", "profiling", "pod/my-pod", now.Add(-5*time.Minute), batchv1.JobStatus{Active: 1}),
+				newProfilingJob("2", "other", "node/worker-1", now.Add(-2*time.Hour), batchv1.JobStatus{Failed: 1}),
+			),
+			namespace: "profiling",
+			then: func(t *testing.T, out string, err error) {
+				require.NoError(t, err)
+				assert.Contains(t, out, "ID")
+				assert.Contains(t, out, "PHASE")
+				assert.Contains(t, out, "apps/my-pod/app")
+				assert.Contains(t, out, "5m")
+				assert.Contains(t, out, "Running")
+				assert.NotContains(t, out, "node/worker-1")
+			},
+		},
+		{
+			name: "should list the profiling jobs of all namespaces",
+			jobApi: fake.NewProfilingJobApi().WithProfilingJobs(
+				newProfilingJob("1", "profiling", "pod/my-pod", now.Add(-5*time.Minute), batchv1.JobStatus{Active: 1}),
+				newProfilingJob("2", "other", "node/worker-1", now.Add(-2*time.Hour), batchv1.JobStatus{Failed: 1}),
+			),
+			then: func(t *testing.T, out string, err error) {
+				require.NoError(t, err)
+				assert.Contains(t, out, "apps/my-pod/app")
+				assert.Contains(t, out, "node/worker-1")
+				assert.Contains(t, out, "Failed")
+			},
+		},
+		{
+			name:   "should inform when there are no profiling jobs",
+			jobApi: fake.NewProfilingJobApi(),
+			then: func(t *testing.T, out string, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, "No profiling jobs found\n", out)
+			},
+		},
+		{
+			name:   "should fail when get profiling jobs fail",
+			jobApi: fake.NewProfilingJobApi().WithGetProfilingJobsReturnsError(),
+			then: func(t *testing.T, out string, err error) {
+				require.Error(t, err)
+				assert.EqualError(t, err, "error getting profiling jobs")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			out := &bytes.Buffer{}
+
+			// When
+			err := listProfilingJobs(context.TODO(), out, tt.jobApi, tt.namespace, now)
+
+			// Then
+			tt.then(t, out.String(), err)
+		})
+	}
+}
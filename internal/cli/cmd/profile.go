@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/josepdcs/kubectl-prof/internal/cli"
@@ -10,8 +15,11 @@ import (
 	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes"
 	"github.com/josepdcs/kubectl-prof/internal/cli/profiler"
 	apiprof "github.com/josepdcs/kubectl-prof/internal/cli/profiler/api"
+	"github.com/josepdcs/kubectl-prof/internal/cli/session"
 	"github.com/josepdcs/kubectl-prof/internal/cli/version"
 	"github.com/josepdcs/kubectl-prof/pkg/util/compressor"
+	"github.com/josepdcs/kubectl-prof/pkg/util/s3"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
@@ -33,9 +41,14 @@ const (
 	defaultOutputSplitSize             = "50M"
 	defaultPoolSizeRetrieveChunks      = 5
 	defaultRetrieveFileRetries         = 3
+	defaultFileServerPort              = 8095
+	overrideConfirmation               = "override"
 	longDescription                    = `Profiling on existing applications with low-overhead.
 
 These commands help you identify application performance issues.
+
+A pod named as a subcommand (list, cleanup, attach or replay) must be given as pod/NAME,
+otherwise the subcommand is run.
 `
 	profilingExamples = `
 	# Profile a pod for 5 minutes with JFR format for java language
@@ -53,17 +66,38 @@ These commands help you identify application performance issues.
 	# Set custom resource requests and limits for the agent pod (default: neither requests nor limits are set) for python language
 	%[1]s prof my-pod --cpu-requests 100m --cpu-limits 200m --mem-requests 100Mi --mem-limits 200Mi -l python
 
+	# Profile the pod named "list", which would otherwise run the list subcommand
+	%[1]s prof pod/list -l go
+
 	# Profile the pods with the label selector "app=my-app" for 5 minutes with JFR format for java language
 	%[1]s prof -l java -o jfr -t 5m --selector app=my-app
+
+	# Profile the pods of the current ReplicaSet of the deployment "checkout" for go language
+	%[1]s prof deployment/checkout -l go
+
+	# Profile the pods of the statefulset "db" (also daemonset/, job/ and cronjob/ are supported) by choosing its container
+	%[1]s prof statefulset/db -l java --target-container-name=db
+
+	# Profile only the ready pods receiving traffic from the service "checkout" in zone "eu-west-1a" for go language
+	%[1]s prof service/checkout -l go --endpoint-zone=eu-west-1a
+
+	# Profile a pod by injecting the agent as an ephemeral container instead of launching a job
+	%[1]s prof my-pod -l java --launch-mode=ephemeral
+
+	# Profile every container running on the node "worker-1" with perf (also bpf and btf are supported)
+	%[1]s prof node/worker-1 -l clang --tool perf
 `
 )
 
 // imagePullPolicies defines a list of container image pull policies supported by the Kubernetes API.
 var imagePullPolicies = []apiv1.PullPolicy{apiv1.PullNever, apiv1.PullAlways, apiv1.PullIfNotPresent}
 
+// nodeWideProfilingTools defines the profiling tools able to profile every container of a node.
+var nodeWideProfilingTools = []api.ProfilingTool{api.Perf, api.Bpf, api.Btf}
+
 // Profiler defines the profile method.
 type Profiler interface {
-	Profile(cfg *config.ProfilerConfig) error
+	Profile(ctx context.Context, cfg *config.ProfilerConfig) error
 }
 
 // ProfileOptions holds configuration flags and IO streams for profile-related commands.
@@ -93,6 +127,12 @@ type profilingFlags struct {
 	imagePullPolicy string
 	privileged      bool
 	capabilities    []string
+	securityProfile string
+	launchMode      string
+	outputFormat    string
+	recordSession   string
+	keepPartial     bool
+	yes             bool
 }
 
 // profilingContext contains the necessary context to execute the profiling command.
@@ -120,11 +160,15 @@ func NewProfile(streams genericiooptions.IOStreams) *cobra.Command {
 
 	options := NewProfileOptions(streams)
 	cmd := &cobra.Command{
-		Use:                   "prof [pod-name | --selector label]",
+		Use:                   "prof [pod-name | TYPE/NAME | --selector label]",
 		DisableFlagsInUseLine: true,
 		Short:                 "Profile running applications. Several output types are supported: flamegraphs, jfrs, threadumps, heapdumps, etc.",
 		Long:                  longDescription,
 		Example:               fmt.Sprintf(profilingExamples, "kubectl"),
+		// the target is given as argument, so arguments not matching any subcommand must be accepted
+		Args: cobra.ArbitraryArgs,
+		// the completion command is not offered by the plugin
+		CompletionOptions: cobra.CompletionOptions{DisableDefaultCmd: true},
 		PersistentPreRun: func(c *cobra.Command, args []string) {
 			c.SetOut(streams.Out)
 			c.SetErr(streams.ErrOut)
@@ -146,6 +190,8 @@ func NewProfile(streams genericiooptions.IOStreams) *cobra.Command {
 
 	setProfileFlags(cmd, &target, &job, &flags, &showVersion, options)
 
+	cmd.AddCommand(NewList(streams), NewCleanup(streams), NewAttach(streams), NewReplay(streams))
+
 	return cmd
 }
 
@@ -161,31 +207,55 @@ func runProfile(ctx *profilingContext) {
 		return
 	}
 
-	if err := validateFlags(ctx.flags, ctx.target, ctx.job); err != nil {
-		_, _ = fmt.Fprintln(ctx.streams.Out, err)
-		os.Exit(1)
+	if err := setOutputFormat(ctx.flags.outputFormat, ctx.streams); err != nil {
+		_, _ = fmt.Fprintln(ctx.streams.ErrOut, err)
+		os.Exit(int(cli.ExitInvalidArguments))
 	}
 
-	// set log level
-	level, _ := log.ParseLevel(ctx.flags.logLevel)
-	log.SetLevel(level)
-
 	if ctx.target.LabelSelector == "" {
-		ctx.target.PodName = ctx.args[0]
+		kind, name, err := config.ParseTargetRef(ctx.args[0])
+		if err != nil {
+			exit(ctx.streams, cli.NewError(cli.ExitInvalidArguments, err))
+		}
+		ctx.target.Kind = kind
+		switch {
+		case kind.IsWorkload():
+			ctx.target.WorkloadName = name
+		case kind == config.Service:
+			ctx.target.ServiceName = name
+		case kind == config.Node:
+			ctx.target.NodeName = name
+		default:
+			ctx.target.PodName = name
+		}
 		if len(ctx.args) > 1 {
 			ctx.target.ContainerName = ctx.args[1]
 		}
 	}
 
+	if err := validateFlags(ctx.flags, ctx.target, ctx.job); err != nil {
+		exit(ctx.streams, cli.NewError(cli.ExitInvalidArguments, err))
+	}
+
+	if ctx.target.OverridePolicy {
+		if err := confirmOverridePolicy(ctx.streams, ctx.flags.yes); err != nil {
+			exit(ctx.streams, cli.NewError(cli.ExitInvalidArguments, err))
+		}
+	}
+
+	// set log level
+	level, _ := log.ParseLevel(ctx.flags.logLevel)
+	log.SetLevel(level)
+
 	// Prepare profiler
-	cfg, err := getProfilerConfig(*ctx.target, *ctx.job, ctx.flags.logLevel, ctx.flags.privileged, ctx.flags.capabilities)
+	cfg, err := getProfilerConfig(*ctx.target, *ctx.job, ctx.flags.logLevel, ctx.flags.privileged, ctx.flags.capabilities, ctx.flags.launchMode)
 	if err != nil {
-		log.Fatalf("Failed configure profiler: %v\n", err)
+		exit(ctx.streams, cli.NewError(cli.ExitInvalidArguments, errors.Wrap(err, "failed configure profiler")))
 	}
 
 	connectionInfo, err := kubernetes.Connect(ctx.options.configFlags)
 	if err != nil {
-		log.Fatalf("Failed connecting to kubernetes cluster: %v\n", err)
+		exit(ctx.streams, errors.Wrap(err, "failed connecting to kubernetes cluster"))
 	}
 
 	if cfg.Target.Namespace == "" {
@@ -193,11 +263,39 @@ func runProfile(ctx *profilingContext) {
 	}
 
 	cfg.Job.Namespace = connectionInfo.Namespace
+
+	// the profiling is cancelled on Ctrl-C or SIGTERM so that the launched profiling jobs get deleted, unless the
+	// partial results are kept: then the first Ctrl-C stops the profiling early and the second one cancels it
+	var (
+		profilingCtx context.Context
+		stop         context.CancelFunc
+		stopEarly    <-chan struct{}
+	)
+	if ctx.flags.keepPartial {
+		profilingCtx, stopEarly, stop = notifyStopEarly()
+	} else {
+		profilingCtx, stop = signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	}
+	defer stop()
+
+	var recorder *session.Recorder
+	if ctx.flags.recordSession != "" {
+		recorder, err = session.NewRecorder(ctx.flags.recordSession, cfg)
+		if err != nil {
+			exit(ctx.streams, err)
+		}
+	}
+
 	err = profiler.New(
 		apiprof.NewPodApi(connectionInfo),
 		apiprof.NewProfilingJobApi(connectionInfo),
 		apiprof.NewProfilingContainerApi(connectionInfo),
-	).Profile(cfg)
+		apiprof.NewProfilingEphemeralContainerApi(connectionInfo),
+	).WithRecorder(recorder).WithPreflightApi(apiprof.NewPreflightApi(connectionInfo)).WithStopEarly(stopEarly).
+		Profile(profilingCtx, cfg)
+	if recorder != nil {
+		_ = recorder.Close()
+	}
 
 	if err != nil {
 		printer := cli.NewPrinter(cfg.Target.DryRun)
@@ -205,6 +303,48 @@ func runProfile(ctx *profilingContext) {
 		printer.PrintError()
 		printer.Print("😥 " + err.Error())
 	}
+	if code := cli.Finish(err); code != int(cli.ExitOK) {
+		os.Exit(code)
+	}
+}
+
+// setOutputFormat sets the format of what the CLI reports. With a structured output, the records are written to
+// the standard output and the logs to the standard error, so that the records can be parsed.
+func setOutputFormat(format string, streams genericiooptions.IOStreams) error {
+	if !cli.IsSupportedOutputFormat(format) {
+		return errors.Errorf("unsupported output format %s, choose one of %v", format, cli.AvailableOutputFormats())
+	}
+	cli.SetOutput(cli.OutputFormat(format), streams.Out)
+	if cli.IsStructuredOutput() {
+		log.SetOutput(streams.ErrOut)
+	} else {
+		log.SetOutput(streams.Out)
+	}
+	return nil
+}
+
+// confirmOverridePolicy asks for confirming the profiling of the targets whose profiling policy does not allow it.
+// The question is written to the standard error, so that a structured output can still be parsed. It is not asked
+// when already confirmed, such as from a CI pipeline.
+func confirmOverridePolicy(streams genericiooptions.IOStreams, confirmed bool) error {
+	if confirmed {
+		_, _ = fmt.Fprintln(streams.ErrOut, "The targets will be profiled even if their namespace or pod disabled the profiling or does not allow the profiling tool, as confirmed by --yes")
+		return nil
+	}
+	_, _ = fmt.Fprintf(streams.ErrOut, "The targets will be profiled even if their namespace or pod disabled the profiling or does not allow the profiling tool. Type %q to confirm: ", overrideConfirmation)
+	answer, _ := bufio.NewReader(streams.In).ReadString('\n')
+	if strings.TrimSpace(answer) != overrideConfirmation {
+		return errors.New("overriding the profiling policy was not confirmed")
+	}
+	return nil
+}
+
+// exit reports the given error, which is printed with the text output, and exits with its exit code
+func exit(streams genericiooptions.IOStreams, err error) {
+	if !cli.IsStructuredOutput() {
+		_, _ = fmt.Fprintln(streams.Out, err)
+	}
+	os.Exit(cli.Finish(err))
 }
 
 // setProfileFlags defines and binds all CLI flags for the "prof" command.
@@ -259,21 +399,69 @@ func setProfileFlags(cmd *cobra.Command, target *config.TargetConfig, job *confi
 	cmd.Flags().StringVarP(&target.Pgrep, "pgrep", "p", "", "Filter the target process by name using pgrep. Use when the PID is not known but the process name is (e.g. java, python, dotnet)")
 	cmd.Flags().IntVar(&target.NodeHeapSnapshotSignal, "node-heap-snapshot-signal", 12, "OS signal number sent to the Node.js process to trigger a heap snapshot (default: 12 = SIGUSR2). Use 10 for SIGUSR1")
 	cmd.Flags().StringSliceVar(&flags.capabilities, "capabilities", nil, "Linux capabilities to add to the agent container (e.g. --capabilities SYS_ADMIN --capabilities SYS_PTRACE). May be required when --privileged is false")
+	cmd.Flags().StringVar(&flags.securityProfile, "security-profile", string(config.PrivilegedSecurityProfile),
+		fmt.Sprintf("Privileges granted to the agent container. Choose one of: %v. With minimal, the container is not privileged and gets only the capabilities, unless --capabilities is given, and the access to the node needed by the profiling tool", config.AvailableSecurityProfiles()))
 	cmd.Flags().StringSliceVar(&job.TolerationsRaw, "tolerations", nil, "Tolerations for the profiling job pod, in the format key=value:effect or key:effect (e.g. --tolerations node-role=infra:NoSchedule --tolerations dedicated:NoExecute)")
+	cmd.Flags().StringVar(&job.TemplateFile, "job-template", "", "File holding a patch applied to the generated profiling job before it is created, in YAML or JSON: a strategic merge patch (an object) or a JSON patch (a list). E.g. for setting a priorityClassName, labels, annotations, a seccomp profile or extra env vars. The job label kubectl-prof/id and the agent container cannot be changed")
 	cmd.Flags().DurationVar(&target.HeartbeatInterval, "heartbeat-interval", 30*time.Second, "Interval between heartbeat progress events emitted during profiling. Keeps connections alive through proxies/load balancers (e.g. 30s, 1m)")
 	cmd.Flags().StringSliceVar(&target.AsyncProfilerArgs, "async-profiler-args", nil, "Extra arguments forwarded directly to async-profiler (e.g. --async-profiler-args --alloc=2m --async-profiler-args --lock=1ms). See async-profiler docs for available options")
+	cmd.Flags().StringVar(&target.EndpointZone, "endpoint-zone", "", "Profile only the endpoints located in this zone. Used only with a service/<name> target")
+	cmd.Flags().StringVar(&target.EndpointPort, "endpoint-port", "", "Profile only the endpoints exposing this port, given by name or number. Used only with a service/<name> target")
+	cmd.Flags().StringVar(&flags.launchMode, "launch-mode", string(config.JobLaunchMode), fmt.Sprintf("How the agent is launched. Choose one of: %v. The ephemeral mode injects the agent as an ephemeral container sharing the process namespace of the target container, without hostPID nor hostPath volumes", config.AvailableLaunchModes()))
+	cmd.Flags().IntVar(&target.FileServerPort, "file-server-port", defaultFileServerPort, "Port of the loopback HTTP server used by the agent to serve the result files, which are downloaded through a port-forward. If the port-forward fails, the result files are downloaded by executing commands in the agent container. Set 0 to always do so. Not used with the ephemeral launch mode, where the agent shares the network of the target pod")
+	cmd.Flags().StringVar(&target.UploadTo, "upload-to", "", "Upload the result files from the agent to this S3-compatible object storage location, given as s3://bucket/prefix, instead of downloading them from the agent container")
+	cmd.Flags().StringVar(&target.UploadSecret, "upload-secret", "", fmt.Sprintf("Name of the Secret, in the namespace of the agent, holding the configuration of the object storage used with --upload-to. Keys: %s, %s, %s (optional), %s (optional) and %s (optional)",
+		s3.SecretAccessKeyID, s3.SecretSecretAccessKey, s3.SecretEndpoint, s3.SecretRegion, s3.SecretSessionToken))
+	cmd.Flags().BoolVar(&target.UploadFetch, "upload-fetch", true, "Download the result files uploaded with --upload-to from the object storage. If false, only their locations are printed")
+	cmd.Flags().StringVar(&target.OutputPVC, "output-pvc", "", "PersistentVolumeClaim, given as <claim>[:subpath], where the agent stores the result files under a <session>/<pod>/<iteration> layout along with a manifest.json. Not supported with ephemeral launch mode")
+	cmd.Flags().BoolVar(&target.OverridePolicy, "override-policy", false, fmt.Sprintf("Profile the targets even if the annotations of their namespace or pod disable the profiling (%s: %s) or do not allow the profiling tool (%s). Requires typing %q to confirm, or --yes", kubernetes.ProfilingAnnotation, kubernetes.ProfilingDisabled, kubernetes.AllowedToolsAnnotation, overrideConfirmation))
+	cmd.Flags().BoolVar(&target.Detach, "detach", false, "Exit right after launching the profiling, without waiting for the results. Requires --output-pvc or --upload-to; use the attach subcommand to reattach")
+	cmd.Flags().StringVar(&flags.outputFormat, "output-format", string(cli.TextOutput), fmt.Sprintf("Format of what the CLI reports. Choose one of: %v. The json and jsonl formats report structured records of each stage, either in a single document once finished or a record per line, and the logs go to the standard error", cli.AvailableOutputFormats()))
+	cmd.Flags().BoolVar(&flags.yes, "yes", false, "Confirm --override-policy without prompting, such as in CI pipelines")
+	cmd.Flags().BoolVar(&flags.keepPartial, "keep-partial", false, "On Ctrl-C, ask the agents to finish their current capture early and download the partial results instead of discarding them. Interrupt again to abort")
+	cmd.Flags().StringVar(&flags.recordSession, "record-session", "", "Record the session into this file: the configuration of the CLI, the launched jobs and every event read from the agents. Replay it offline with the replay subcommand, e.g. for attaching it to a bug report")
 	cmd.Flags().StringVar(&target.PprofPort, "pprof-port", "", "Port on the target pod where the Go pprof HTTP endpoint is exposed (default: 6060). Used only with --tool pprof")
 
 	options.configFlags.AddFlags(cmd.Flags())
 }
 
+// notifyStopEarly returns a channel closed on the first Ctrl-C, which stops the profiling early while keeping the
+// partial results, and a context cancelled on the next one or on SIGTERM, which aborts the profiling
+func notifyStopEarly() (context.Context, <-chan struct{}, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopEarly := make(chan struct{})
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		defer signal.Stop(sigs)
+		if s := waitSignal(ctx, sigs); s == os.Interrupt {
+			close(stopEarly)
+			waitSignal(ctx, sigs)
+		}
+		cancel()
+	}()
+	return ctx, stopEarly, cancel
+}
+
+// waitSignal waits for a signal until the given context is done, in which case nil is returned
+func waitSignal(ctx context.Context, sigs <-chan os.Signal) os.Signal {
+	select {
+	case s := <-sigs:
+		return s
+	case <-ctx.Done():
+		return nil
+	}
+}
+
 // getProfilerConfig creates a config.ProfilerConfig based on the provided target and job configurations,
-// log level, privileged status, and Linux capabilities.
-func getProfilerConfig(target config.TargetConfig, job config.JobConfig, logLevel string, privileged bool, capabilities []string) (*config.ProfilerConfig, error) {
+// log level, privileged status, Linux capabilities and launch mode.
+func getProfilerConfig(target config.TargetConfig, job config.JobConfig, logLevel string, privileged bool, capabilities []string, launchMode string) (*config.ProfilerConfig, error) {
 	job.Privileged = privileged
 	job.Capabilities = make([]apiv1.Capability, len(capabilities))
 	for i, capability := range capabilities {
 		job.Capabilities[i] = apiv1.Capability(capability)
 	}
-	return config.NewProfilerConfig(&target, config.WithJob(&job), config.WithLogLevel(api.LogLevel(logLevel)))
+	return config.NewProfilerConfig(&target, config.WithJob(&job), config.WithLogLevel(api.LogLevel(logLevel)),
+		config.WithLaunchMode(config.LaunchMode(launchMode)))
 }
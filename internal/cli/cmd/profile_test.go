@@ -4,8 +4,10 @@ import (
 	"testing"
 
 	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/josepdcs/kubectl-prof/internal/cli"
 	"github.com/josepdcs/kubectl-prof/internal/cli/config"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
 )
 
 func TestValidateFlags(t *testing.T) {
@@ -124,6 +126,305 @@ func TestValidateFlags(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid node target",
+			args: args{
+				flags: &profilingFlags{
+					lang:            string(api.Clang),
+					runtime:         string(api.Containerd),
+					event:           string(api.Cpu),
+					logLevel:        string(api.InfoLevel),
+					compressorType:  "gzip",
+					imagePullPolicy: "Always",
+					profilingTool:   string(api.Perf),
+				},
+				target: &config.TargetConfig{Kind: config.Node},
+				job:    &config.JobConfig{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unsupported profiling tool for node target",
+			args: args{
+				flags: &profilingFlags{
+					lang:            string(api.Java),
+					runtime:         string(api.Containerd),
+					event:           string(api.Cpu),
+					logLevel:        string(api.InfoLevel),
+					compressorType:  "gzip",
+					imagePullPolicy: "Always",
+				},
+				target: &config.TargetConfig{Kind: config.Node},
+				job:    &config.JobConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid ephemeral launch mode",
+			args: args{
+				flags: &profilingFlags{
+					lang:            string(api.Java),
+					runtime:         string(api.Containerd),
+					event:           string(api.Cpu),
+					logLevel:        string(api.InfoLevel),
+					compressorType:  "gzip",
+					imagePullPolicy: "Always",
+					launchMode:      string(config.EphemeralLaunchMode),
+				},
+				target: &config.TargetConfig{},
+				job:    &config.JobConfig{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid launch mode",
+			args: args{
+				flags: &profilingFlags{
+					lang:            string(api.Java),
+					runtime:         string(api.Containerd),
+					event:           string(api.Cpu),
+					logLevel:        string(api.InfoLevel),
+					compressorType:  "gzip",
+					imagePullPolicy: "Always",
+					launchMode:      "invalid",
+				},
+				target: &config.TargetConfig{},
+				job:    &config.JobConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "ephemeral launch mode with node target",
+			args: args{
+				flags: &profilingFlags{
+					lang:            string(api.Clang),
+					runtime:         string(api.Containerd),
+					event:           string(api.Cpu),
+					logLevel:        string(api.InfoLevel),
+					compressorType:  "gzip",
+					imagePullPolicy: "Always",
+					profilingTool:   string(api.Perf),
+					launchMode:      string(config.EphemeralLaunchMode),
+				},
+				target: &config.TargetConfig{Kind: config.Node},
+				job:    &config.JobConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid upload",
+			args: args{
+				flags: &profilingFlags{
+					lang:            string(api.Go),
+					runtime:         string(api.Containerd),
+					event:           string(api.Cpu),
+					logLevel:        string(api.InfoLevel),
+					compressorType:  "gzip",
+					imagePullPolicy: "Always",
+				},
+				target: &config.TargetConfig{ExtraTargetOptions: config.ExtraTargetOptions{UploadTo: "s3://bucket/profiling", UploadSecret: "minio"}},
+				job:    &config.JobConfig{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid upload location",
+			args: args{
+				flags: &profilingFlags{
+					lang:            string(api.Go),
+					runtime:         string(api.Containerd),
+					event:           string(api.Cpu),
+					logLevel:        string(api.InfoLevel),
+					compressorType:  "gzip",
+					imagePullPolicy: "Always",
+				},
+				target: &config.TargetConfig{ExtraTargetOptions: config.ExtraTargetOptions{UploadTo: "bucket/profiling", UploadSecret: "minio"}},
+				job:    &config.JobConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "upload without secret",
+			args: args{
+				flags: &profilingFlags{
+					lang:            string(api.Go),
+					runtime:         string(api.Containerd),
+					event:           string(api.Cpu),
+					logLevel:        string(api.InfoLevel),
+					compressorType:  "gzip",
+					imagePullPolicy: "Always",
+				},
+				target: &config.TargetConfig{ExtraTargetOptions: config.ExtraTargetOptions{UploadTo: "s3://bucket/profiling"}},
+				job:    &config.JobConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid output pvc with detach",
+			args: args{
+				flags: &profilingFlags{
+					lang:            string(api.Go),
+					runtime:         string(api.Containerd),
+					event:           string(api.Cpu),
+					logLevel:        string(api.InfoLevel),
+					compressorType:  "gzip",
+					imagePullPolicy: "Always",
+				},
+				target: &config.TargetConfig{ExtraTargetOptions: config.ExtraTargetOptions{OutputPVC: "results:profiling", Detach: true}},
+				job:    &config.JobConfig{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "output pvc with ephemeral launch mode",
+			args: args{
+				flags: &profilingFlags{
+					lang:            string(api.Go),
+					runtime:         string(api.Containerd),
+					event:           string(api.Cpu),
+					logLevel:        string(api.InfoLevel),
+					compressorType:  "gzip",
+					imagePullPolicy: "Always",
+					launchMode:      string(config.EphemeralLaunchMode),
+				},
+				target: &config.TargetConfig{ExtraTargetOptions: config.ExtraTargetOptions{OutputPVC: "results"}},
+				job:    &config.JobConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "output pvc with parent sub path",
+			args: args{
+				flags: &profilingFlags{
+					lang:            string(api.Go),
+					runtime:         string(api.Containerd),
+					event:           string(api.Cpu),
+					logLevel:        string(api.InfoLevel),
+					compressorType:  "gzip",
+					imagePullPolicy: "Always",
+				},
+				target: &config.TargetConfig{ExtraTargetOptions: config.ExtraTargetOptions{OutputPVC: "results:../other"}},
+				job:    &config.JobConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "job template",
+			args: args{
+				flags: &profilingFlags{
+					lang:            string(api.Go),
+					runtime:         string(api.Containerd),
+					event:           string(api.Cpu),
+					logLevel:        string(api.InfoLevel),
+					compressorType:  "gzip",
+					imagePullPolicy: "Always",
+				},
+				target: &config.TargetConfig{},
+				job:    &config.JobConfig{TemplateFile: "testdata/job-template.yaml"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "job template not found",
+			args: args{
+				flags: &profilingFlags{
+					lang:            string(api.Go),
+					runtime:         string(api.Containerd),
+					event:           string(api.Cpu),
+					logLevel:        string(api.InfoLevel),
+					compressorType:  "gzip",
+					imagePullPolicy: "Always",
+				},
+				target: &config.TargetConfig{},
+				job:    &config.JobConfig{TemplateFile: "testdata/other.yaml"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "job template with ephemeral launch mode",
+			args: args{
+				flags: &profilingFlags{
+					lang:            string(api.Go),
+					runtime:         string(api.Containerd),
+					event:           string(api.Cpu),
+					logLevel:        string(api.InfoLevel),
+					compressorType:  "gzip",
+					imagePullPolicy: "Always",
+					launchMode:      string(config.EphemeralLaunchMode),
+				},
+				target: &config.TargetConfig{},
+				job:    &config.JobConfig{TemplateFile: "testdata/job-template.yaml"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "minimal security profile",
+			args: args{
+				flags: &profilingFlags{
+					lang:            string(api.Go),
+					runtime:         string(api.Containerd),
+					event:           string(api.Cpu),
+					logLevel:        string(api.InfoLevel),
+					compressorType:  "gzip",
+					imagePullPolicy: "Always",
+					profilingTool:   string(api.GoPprof),
+					securityProfile: string(config.MinimalSecurityProfile),
+				},
+				target: &config.TargetConfig{},
+				job:    &config.JobConfig{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "minimal security profile of the default tool",
+			args: args{
+				flags: &profilingFlags{
+					lang:            string(api.Go),
+					runtime:         string(api.Containerd),
+					event:           string(api.Cpu),
+					logLevel:        string(api.InfoLevel),
+					compressorType:  "gzip",
+					imagePullPolicy: "Always",
+					securityProfile: string(config.MinimalSecurityProfile),
+				},
+				target: &config.TargetConfig{},
+				job:    &config.JobConfig{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unsupported security profile",
+			args: args{
+				flags: &profilingFlags{
+					lang:            string(api.Go),
+					runtime:         string(api.Containerd),
+					event:           string(api.Cpu),
+					logLevel:        string(api.InfoLevel),
+					compressorType:  "gzip",
+					imagePullPolicy: "Always",
+					securityProfile: "restricted",
+				},
+				target: &config.TargetConfig{},
+				job:    &config.JobConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "detach without persisting the results",
+			args: args{
+				flags: &profilingFlags{
+					lang:            string(api.Go),
+					runtime:         string(api.Containerd),
+					event:           string(api.Cpu),
+					logLevel:        string(api.InfoLevel),
+					compressorType:  "gzip",
+					imagePullPolicy: "Always",
+				},
+				target: &config.TargetConfig{ExtraTargetOptions: config.ExtraTargetOptions{Detach: true}},
+				job:    &config.JobConfig{},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -136,3 +437,46 @@ func TestValidateFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestSetOutputFormat(t *testing.T) {
+	streams, _, _, _ := genericiooptions.NewTestIOStreams()
+	defer func() { _ = setOutputFormat(string(cli.TextOutput), streams) }()
+
+	assert.Error(t, setOutputFormat("yaml", streams))
+	assert.False(t, cli.IsStructuredOutput())
+
+	assert.NoError(t, setOutputFormat("jsonl", streams))
+	assert.True(t, cli.IsStructuredOutput())
+}
+
+func TestConfirmOverridePolicy(t *testing.T) {
+	streams, in, _, errOut := genericiooptions.NewTestIOStreams()
+	in.WriteString("override\n")
+	assert.NoError(t, confirmOverridePolicy(streams, false))
+	assert.Contains(t, errOut.String(), `Type "override" to confirm`)
+
+	streams, in, _, _ = genericiooptions.NewTestIOStreams()
+	in.WriteString("yes\n")
+	assert.Error(t, confirmOverridePolicy(streams, false))
+
+	streams, _, _, _ = genericiooptions.NewTestIOStreams()
+	assert.Error(t, confirmOverridePolicy(streams, false))
+
+	streams, _, _, errOut = genericiooptions.NewTestIOStreams()
+	assert.NoError(t, confirmOverridePolicy(streams, true))
+	assert.Contains(t, errOut.String(), "confirmed by --yes")
+}
+
+func TestNewProfile_PodNamedAsSubcommand(t *testing.T) {
+	cmd := NewProfile(genericiooptions.NewTestIOStreamsDiscard())
+
+	// the pod is given with its kind, so that it is not taken as the subcommand
+	found, args, err := cmd.Find([]string{"pod/list"})
+	assert.NoError(t, err)
+	assert.Equal(t, cmd, found)
+	assert.Equal(t, []string{"pod/list"}, args)
+
+	found, _, err = cmd.Find([]string{"list"})
+	assert.NoError(t, err)
+	assert.Equal(t, "list", found.Name())
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/josepdcs/kubectl-prof/internal/cli"
+	"github.com/josepdcs/kubectl-prof/internal/cli/session"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+const replayExamples = `
+	# Record a profiling session
+	%[1]s prof my-pod -t 1m -l java --record-session=session.jsonl
+
+	# Replay it offline, printing also the logs of the agent
+	%[1]s prof replay session.jsonl --print-agent-logs
+`
+
+// NewReplay returns a new cobra.Command for the "replay" subcommand.
+// This command replays offline a profiling session recorded with --record-session, without any cluster.
+func NewReplay(streams genericiooptions.IOStreams) *cobra.Command {
+	var (
+		printAgentLogs bool
+		outputFormat   string
+	)
+
+	cmd := &cobra.Command{
+		Use:                   "replay FILE",
+		DisableFlagsInUseLine: true,
+		Short:                 "Replay offline a profiling session recorded with --record-session",
+		Long: `Replay offline a profiling session recorded with --record-session.
+
+The events of the agents are handled as while profiling, so that the CLI prints the same.
+The result files are printed but not downloaded, since no cluster is involved.
+`,
+		Example: fmt.Sprintf(replayExamples, "kubectl"),
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := setOutputFormat(outputFormat, streams); err != nil {
+				_, _ = fmt.Fprintln(streams.ErrOut, err)
+				os.Exit(int(cli.ExitInvalidArguments))
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				exit(streams, cli.NewError(cli.ExitInvalidArguments, err))
+			}
+			err = session.Replay(f, printAgentLogs)
+			_ = f.Close()
+			if code := cli.Finish(err); code != int(cli.ExitOK) {
+				os.Exit(code)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&printAgentLogs, "print-agent-logs", false, "Print the logs of the agents, even if they were not printed while profiling")
+	cmd.Flags().StringVar(&outputFormat, "output-format", string(cli.TextOutput), fmt.Sprintf("Format of what the CLI reports. Choose one of: %v", cli.AvailableOutputFormats()))
+
+	return cmd
+}
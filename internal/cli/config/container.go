@@ -19,6 +19,9 @@ type ContainerConfig struct {
 
 	// Capabilities indicate the capabilities that the container will have
 	Capabilities []apiv1.Capability
+
+	// SecurityProfile indicates the privileges granted to the container when they are not given
+	SecurityProfile SecurityProfile
 }
 
 // ResourceConfig holds resource configuration for either requests or limits.
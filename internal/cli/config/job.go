@@ -1,9 +1,14 @@
 package config
 
 import (
+	"bytes"
+	"os"
+	"slices"
 	"strings"
 
+	"github.com/pkg/errors"
 	apiv1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
 )
 
 // JobConfig holds configuration options for the profiling job that is launched
@@ -21,6 +26,12 @@ type JobConfig struct {
 
 	// TolerationsRaw holds raw toleration strings from command line
 	TolerationsRaw []string
+
+	// TemplateFile is the file holding the patch applied to the generated profiling job
+	TemplateFile string
+
+	// Template holds the patch read from TemplateFile as JSON, either a strategic merge patch or a JSON patch
+	Template []byte
 }
 
 // DeepCopy returns a deep copy of the JobConfig.
@@ -34,6 +45,8 @@ func (j *JobConfig) DeepCopy() *JobConfig {
 		Namespace:       j.Namespace,
 		Tolerations:     tolerations,
 		TolerationsRaw:  tolerationsRaw,
+		TemplateFile:    j.TemplateFile,
+		Template:        slices.Clone(j.Template),
 	}
 }
 
@@ -80,3 +93,27 @@ func (j *JobConfig) ParseTolerations() error {
 
 	return nil
 }
+
+// ParseTemplate reads the patch of the profiling job from TemplateFile, given in YAML or JSON.
+// A document holding an object is a strategic merge patch, while one holding a list is a JSON patch.
+func (j *JobConfig) ParseTemplate() error {
+	if j.TemplateFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(j.TemplateFile)
+	if err != nil {
+		return errors.Wrap(err, "could not read job template")
+	}
+	template, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return errors.Wrap(err, "could not parse job template")
+	}
+	template = bytes.TrimSpace(template)
+	if !bytes.HasPrefix(template, []byte("{")) && !bytes.HasPrefix(template, []byte("[")) {
+		return errors.New("job template must be either a strategic merge patch or a JSON patch")
+	}
+
+	j.Template = template
+	return nil
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -119,6 +121,8 @@ func TestJobConfig_DeepCopy(t *testing.T) {
 			},
 		},
 		TolerationsRaw: []string{"key1=value1:NoSchedule"},
+		TemplateFile:   "job-template.yaml",
+		Template:       []byte(`{"metadata":{}}`),
 	}
 
 	copied := original.DeepCopy()
@@ -128,13 +132,71 @@ func TestJobConfig_DeepCopy(t *testing.T) {
 	assert.Equal(t, original.Privileged, copied.Privileged)
 	assert.Equal(t, original.Tolerations, copied.Tolerations)
 	assert.Equal(t, original.TolerationsRaw, copied.TolerationsRaw)
+	assert.Equal(t, original.TemplateFile, copied.TemplateFile)
+	assert.Equal(t, original.Template, copied.Template)
 
 	// Verify modifying the copy doesn't affect the original
 	copied.Namespace = "modified-namespace"
 	copied.Tolerations[0].Key = "modified-key"
 	copied.TolerationsRaw[0] = "modified"
+	copied.Template[0] = '['
 
 	assert.NotEqual(t, original.Namespace, copied.Namespace)
 	assert.NotEqual(t, original.Tolerations[0].Key, copied.Tolerations[0].Key)
 	assert.NotEqual(t, original.TolerationsRaw[0], copied.TolerationsRaw[0])
+	assert.NotEqual(t, original.Template[0], copied.Template[0])
+}
+
+func TestJobConfig_ParseTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+		noFile  bool
+	}{
+		{
+			name:    "strategic merge patch in YAML",
+			content: "spec:\n  template:\n    spec:\n      priorityClassName: profiling\n",
+			want:    `{"spec":{"template":{"spec":{"priorityClassName":"profiling"}}}}`,
+		},
+		{
+			name:    "JSON patch",
+			content: `[{"op": "add", "path": "/spec/template/spec/runtimeClassName", "value": "gvisor"}]`,
+			want:    `[{"op":"add","path":"/spec/template/spec/runtimeClassName","value":"gvisor"}]`,
+		},
+		{
+			name:    "neither an object nor a list",
+			content: "profiling",
+			wantErr: true,
+		},
+		{
+			name:    "invalid YAML",
+			content: "spec: [",
+			wantErr: true,
+		},
+		{
+			name:    "file not found",
+			noFile:  true,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "job-template.yaml")
+			if !tt.noFile {
+				require.NoError(t, os.WriteFile(file, []byte(tt.content), 0644))
+			}
+			j := &JobConfig{TemplateFile: file}
+
+			err := j.ParseTemplate()
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(j.Template))
+		})
+	}
 }
@@ -0,0 +1,21 @@
+package config
+
+import "slices"
+
+// LaunchMode represents how the profiling agent is launched.
+type LaunchMode string
+
+const (
+	JobLaunchMode       LaunchMode = "job"       // JobLaunchMode launches the agent as a batch Job on the node of the target pod.
+	EphemeralLaunchMode LaunchMode = "ephemeral" // EphemeralLaunchMode injects the agent as an ephemeral container into the target pod.
+)
+
+// AvailableLaunchModes returns the list of supported launch modes.
+func AvailableLaunchModes() []LaunchMode {
+	return []LaunchMode{JobLaunchMode, EphemeralLaunchMode}
+}
+
+// IsSupportedLaunchMode returns true if the given launch mode is supported.
+func IsSupportedLaunchMode(launchMode string) bool {
+	return slices.Contains(AvailableLaunchModes(), LaunchMode(launchMode))
+}
@@ -0,0 +1,14 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSupportedLaunchMode(t *testing.T) {
+	assert.True(t, IsSupportedLaunchMode("job"))
+	assert.True(t, IsSupportedLaunchMode("ephemeral"))
+	assert.False(t, IsSupportedLaunchMode("daemonset"))
+	assert.False(t, IsSupportedLaunchMode(""))
+}
@@ -8,21 +8,23 @@ import (
 
 // ProfilerConfig encapsulates the profiler configuration.
 type ProfilerConfig struct {
-	Target   *TargetConfig
-	Job      *JobConfig
-	LogLevel api.LogLevel
+	Target     *TargetConfig
+	Job        *JobConfig
+	LogLevel   api.LogLevel
+	LaunchMode LaunchMode
 }
 
 // Option represents an option of the ProfilerConfig.
 type Option func(s *ProfilerConfig)
 
 // NewProfilerConfig instances a new ProfilerConfig. TargetConfig is always mandatory.
-// It's task of the invoker to choice between launch a Job or an EphemeralContainer,
-// but one of both (and only one) is mandatory, otherwise error is return.
+// It's task of the invoker to choice between launch a Job (default) or an EphemeralContainer through the LaunchMode.
+// JobConfig is mandatory in both cases since it holds the agent container settings, otherwise error is return.
 func NewProfilerConfig(Target *TargetConfig, options ...Option) (*ProfilerConfig, error) {
 	p := &ProfilerConfig{
-		Target:   Target,
-		LogLevel: api.InfoLevel,
+		Target:     Target,
+		LogLevel:   api.InfoLevel,
+		LaunchMode: JobLaunchMode,
 	}
 
 	for _, option := range options {
@@ -51,11 +53,19 @@ func WithLogLevel(logLevel api.LogLevel) Option {
 	}
 }
 
+// WithLaunchMode sets the launch mode of the agent
+func WithLaunchMode(launchMode LaunchMode) Option {
+	return func(p *ProfilerConfig) {
+		p.LaunchMode = launchMode
+	}
+}
+
 // DeepCopy returns a deep copy of the ProfilerConfig
 func (p *ProfilerConfig) DeepCopy() *ProfilerConfig {
 	return &ProfilerConfig{
-		Target:   p.Target.DeepCopy(),
-		Job:      p.Job.DeepCopy(),
-		LogLevel: p.LogLevel,
+		Target:     p.Target.DeepCopy(),
+		Job:        p.Job.DeepCopy(),
+		LogLevel:   p.LogLevel,
+		LaunchMode: p.LaunchMode,
 	}
 }
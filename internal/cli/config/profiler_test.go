@@ -45,6 +45,7 @@ func TestNewProfilerConfig(t *testing.T) {
 				assert.NotEmpty(t, config)
 				assert.NotEmpty(t, config.Target)
 				assert.NotEmpty(t, config.Job)
+				assert.Equal(t, JobLaunchMode, config.LaunchMode)
 			},
 		},
 		{
@@ -76,6 +77,28 @@ func TestNewProfilerConfig(t *testing.T) {
 				assert.Equal(t, api.DebugLevel, config.LogLevel)
 			},
 		},
+		{
+			name: "With Launch Mode",
+			given: func() args {
+				return args{
+					Target: &TargetConfig{
+						Namespace: "Namespace",
+					},
+					options: []Option{
+						WithJob(&JobConfig{Namespace: "Namespace"}),
+						WithLaunchMode(EphemeralLaunchMode),
+					},
+				}
+			},
+			when: func(args args) (*ProfilerConfig, error) {
+				return NewProfilerConfig(args.Target, args.options...)
+			},
+			then: func(t *testing.T, config *ProfilerConfig, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, EphemeralLaunchMode, config.LaunchMode)
+				assert.Equal(t, EphemeralLaunchMode, config.DeepCopy().LaunchMode)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
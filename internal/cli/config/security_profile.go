@@ -0,0 +1,21 @@
+package config
+
+import "slices"
+
+// SecurityProfile represents the privileges granted to the profiling agent.
+type SecurityProfile string
+
+const (
+	PrivilegedSecurityProfile SecurityProfile = "privileged" // PrivilegedSecurityProfile runs the agent privileged, unless disabled, with the default capabilities of its image.
+	MinimalSecurityProfile    SecurityProfile = "minimal"    // MinimalSecurityProfile runs the agent unprivileged with the least privileges needed by the profiling tool.
+)
+
+// AvailableSecurityProfiles returns the list of supported security profiles.
+func AvailableSecurityProfiles() []SecurityProfile {
+	return []SecurityProfile{PrivilegedSecurityProfile, MinimalSecurityProfile}
+}
+
+// IsSupportedSecurityProfile returns true if the given security profile is supported.
+func IsSupportedSecurityProfile(securityProfile string) bool {
+	return slices.Contains(AvailableSecurityProfiles(), SecurityProfile(securityProfile))
+}
@@ -0,0 +1,14 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSupportedSecurityProfile(t *testing.T) {
+	assert.True(t, IsSupportedSecurityProfile("privileged"))
+	assert.True(t, IsSupportedSecurityProfile("minimal"))
+	assert.False(t, IsSupportedSecurityProfile("restricted"))
+	assert.False(t, IsSupportedSecurityProfile(""))
+}
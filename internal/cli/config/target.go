@@ -1,6 +1,8 @@
 package config
 
 import (
+	"maps"
+	"strings"
 	"time"
 
 	"github.com/josepdcs/kubectl-prof/api"
@@ -11,6 +13,11 @@ import (
 type TargetConfig struct {
 	Namespace            string
 	PodName              string
+	Kind                 TargetKind
+	WorkloadName         string
+	ServiceName          string
+	NodeName             string
+	NodeContainers       map[string]string
 	ContainerName        string
 	LabelSelector        string
 	ContainerID          string
@@ -49,6 +56,22 @@ type ExtraTargetOptions struct {
 	NodeHeapSnapshotSignal      int
 	AsyncProfilerArgs           []string
 	PprofPort                   string
+	EndpointZone                string
+	EndpointPort                string
+	FileServerPort              int
+	UploadTo                    string
+	UploadSecret                string
+	UploadFetch                 bool
+	OutputPVC                   string
+	Detach                      bool
+	OverridePolicy              bool
+}
+
+// OutputVolume returns the PersistentVolumeClaim, and its optional sub path, given as <claim>[:subpath]
+// where the agent stores the result files
+func (e ExtraTargetOptions) OutputVolume() (claim string, subPath string) {
+	claim, subPath, _ = strings.Cut(e.OutputPVC, ":")
+	return claim, subPath
 }
 
 // DeepCopy returns a deep copy of the target config
@@ -56,6 +79,11 @@ func (t *TargetConfig) DeepCopy() *TargetConfig {
 	return &TargetConfig{
 		Namespace:            t.Namespace,
 		PodName:              t.PodName,
+		Kind:                 t.Kind,
+		WorkloadName:         t.WorkloadName,
+		ServiceName:          t.ServiceName,
+		NodeName:             t.NodeName,
+		NodeContainers:       maps.Clone(t.NodeContainers),
 		ContainerName:        t.ContainerName,
 		LabelSelector:        t.LabelSelector,
 		ContainerID:          t.ContainerID,
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TargetKind represents the kind of Kubernetes resource given as profiling target.
+type TargetKind string
+
+const (
+	Pod         TargetKind = "pod"         // Pod represents a single pod given by its name.
+	Deployment  TargetKind = "deployment"  // Deployment represents the pods owned by the current ReplicaSet of a Deployment.
+	StatefulSet TargetKind = "statefulset" // StatefulSet represents the pods of the current revision of a StatefulSet.
+	DaemonSet   TargetKind = "daemonset"   // DaemonSet represents the pods of the current revision of a DaemonSet.
+	Job         TargetKind = "job"         // Job represents the pods owned by a Job.
+	CronJob     TargetKind = "cronjob"     // CronJob represents the pods owned by the active Jobs of a CronJob.
+	Service     TargetKind = "service"     // Service represents the ready pods behind the EndpointSlices of a Service.
+	Node        TargetKind = "node"        // Node represents every container running on a node.
+)
+
+// targetKindAliases maps each accepted resource name (including the kubectl short names) to its TargetKind.
+var targetKindAliases = map[string]TargetKind{
+	"pod":          Pod,
+	"pods":         Pod,
+	"po":           Pod,
+	"deployment":   Deployment,
+	"deployments":  Deployment,
+	"deploy":       Deployment,
+	"statefulset":  StatefulSet,
+	"statefulsets": StatefulSet,
+	"sts":          StatefulSet,
+	"daemonset":    DaemonSet,
+	"daemonsets":   DaemonSet,
+	"ds":           DaemonSet,
+	"job":          Job,
+	"jobs":         Job,
+	"cronjob":      CronJob,
+	"cronjobs":     CronJob,
+	"cj":           CronJob,
+	"service":      Service,
+	"services":     Service,
+	"svc":          Service,
+	"node":         Node,
+	"nodes":        Node,
+	"no":           Node,
+}
+
+// IsWorkload returns true if the kind refers to a workload owning a set of pods instead of a single pod.
+func (k TargetKind) IsWorkload() bool {
+	return k == Deployment || k == StatefulSet || k == DaemonSet || k == Job || k == CronJob
+}
+
+// ParseTargetRef parses a target given as <name> or <kind>/<name> (e.g. deployment/checkout) and returns its kind and name.
+// A target without kind is considered a pod.
+func ParseTargetRef(ref string) (TargetKind, string, error) {
+	kind, name, found := strings.Cut(ref, "/")
+	if !found {
+		return Pod, ref, nil
+	}
+
+	targetKind, ok := targetKindAliases[strings.ToLower(kind)]
+	if !ok {
+		return "", "", errors.Errorf("unsupported target kind %q, choose one of %v", kind, availableTargetKinds())
+	}
+	if name == "" {
+		return "", "", errors.Errorf("missing %s name in target %q", targetKind, ref)
+	}
+
+	return targetKind, name, nil
+}
+
+// availableTargetKinds returns the list of supported target kinds.
+func availableTargetKinds() []TargetKind {
+	return []TargetKind{Pod, Deployment, StatefulSet, DaemonSet, Job, CronJob, Service, Node}
+}
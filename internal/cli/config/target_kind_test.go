@@ -0,0 +1,119 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTargetRef(t *testing.T) {
+	tests := []struct {
+		name     string
+		ref      string
+		wantKind TargetKind
+		wantName string
+		wantErr  string
+	}{
+		{
+			name:     "pod name without kind",
+			ref:      "my-pod",
+			wantKind: Pod,
+			wantName: "my-pod",
+		},
+		{
+			name:     "explicit pod",
+			ref:      "pod/my-pod",
+			wantKind: Pod,
+			wantName: "my-pod",
+		},
+		{
+			name:     "pod named as a subcommand",
+			ref:      "pod/list",
+			wantKind: Pod,
+			wantName: "list",
+		},
+		{
+			name:     "deployment",
+			ref:      "deployment/checkout",
+			wantKind: Deployment,
+			wantName: "checkout",
+		},
+		{
+			name:     "deployment short name",
+			ref:      "deploy/checkout",
+			wantKind: Deployment,
+			wantName: "checkout",
+		},
+		{
+			name:     "statefulset short name",
+			ref:      "sts/db",
+			wantKind: StatefulSet,
+			wantName: "db",
+		},
+		{
+			name:     "daemonset uppercase",
+			ref:      "DaemonSet/agent",
+			wantKind: DaemonSet,
+			wantName: "agent",
+		},
+		{
+			name:     "job",
+			ref:      "job/migration",
+			wantKind: Job,
+			wantName: "migration",
+		},
+		{
+			name:     "cronjob",
+			ref:      "cj/nightly",
+			wantKind: CronJob,
+			wantName: "nightly",
+		},
+		{
+			name:     "service short name",
+			ref:      "svc/checkout",
+			wantKind: Service,
+			wantName: "checkout",
+		},
+		{
+			name:     "node",
+			ref:      "node/worker-1",
+			wantKind: Node,
+			wantName: "worker-1",
+		},
+		{
+			name:    "unsupported kind",
+			ref:     "replicaset/foo",
+			wantErr: "unsupported target kind",
+		},
+		{
+			name:    "missing name",
+			ref:     "deployment/",
+			wantErr: "missing deployment name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, name, err := ParseTargetRef(tt.ref)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantKind, kind)
+			assert.Equal(t, tt.wantName, name)
+		})
+	}
+}
+
+func TestTargetKind_IsWorkload(t *testing.T) {
+	assert.False(t, Pod.IsWorkload())
+	assert.True(t, Deployment.IsWorkload())
+	assert.True(t, StatefulSet.IsWorkload())
+	assert.True(t, DaemonSet.IsWorkload())
+	assert.True(t, Job.IsWorkload())
+	assert.True(t, CronJob.IsWorkload())
+	assert.False(t, Service.IsWorkload())
+	assert.False(t, Node.IsWorkload())
+}
@@ -10,6 +10,11 @@ func TestTargetConfig_DeepCopy(t *testing.T) {
 	target := &TargetConfig{
 		Namespace:            "namespace",
 		PodName:              "pod",
+		Kind:                 Deployment,
+		WorkloadName:         "workload",
+		ServiceName:          "service",
+		NodeName:             "node",
+		NodeContainers:       map[string]string{"containerd://1234": "namespace/pod/container"},
 		ContainerName:        "container",
 		LabelSelector:        "label",
 		ContainerID:          "containerID",
@@ -26,4 +31,7 @@ func TestTargetConfig_DeepCopy(t *testing.T) {
 	}
 	deepCopy := target.DeepCopy()
 	assert.Equal(t, target, deepCopy)
+
+	deepCopy.NodeContainers["containerd://5678"] = "namespace/other/container"
+	assert.Len(t, target.NodeContainers, 1)
 }
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"errors"
+)
+
+// ExitCode is the exit code of the CLI, which tells the category of the failure
+type ExitCode int
+
+const (
+	ExitOK               ExitCode = 0   // ExitOK indicates the profiling succeeded.
+	ExitFailure          ExitCode = 1   // ExitFailure indicates a failure not belonging to any other category.
+	ExitInvalidArguments ExitCode = 2   // ExitInvalidArguments indicates the given arguments or flags are invalid.
+	ExitTargetNotFound   ExitCode = 3   // ExitTargetNotFound indicates the target, or any of its containers, was not found.
+	ExitAgentFailed      ExitCode = 4   // ExitAgentFailed indicates the agent could not be launched or failed while profiling.
+	ExitTransferFailed   ExitCode = 5   // ExitTransferFailed indicates a result file could not be transferred from the agent.
+	ExitTimeout          ExitCode = 6   // ExitTimeout indicates the agent did not start or answer in time.
+	ExitPreflightFailed  ExitCode = 7   // ExitPreflightFailed indicates the agent would be denied by the permissions or the Pod Security Admission.
+	ExitInterrupted      ExitCode = 130 // ExitInterrupted indicates the profiling was interrupted by the user.
+)
+
+var exitCodeNames = map[ExitCode]string{
+	ExitOK:               "ok",
+	ExitFailure:          "failure",
+	ExitInvalidArguments: "invalid-arguments",
+	ExitTargetNotFound:   "target-not-found",
+	ExitAgentFailed:      "agent-failed",
+	ExitTransferFailed:   "transfer-failed",
+	ExitTimeout:          "timeout",
+	ExitPreflightFailed:  "preflight-failed",
+	ExitInterrupted:      "interrupted",
+}
+
+// String returns the name of the category of the exit code
+func (c ExitCode) String() string {
+	if name, ok := exitCodeNames[c]; ok {
+		return name
+	}
+	return exitCodeNames[ExitFailure]
+}
+
+// Error is an error of the CLI categorized by the exit code it results in
+type Error struct {
+	Code ExitCode
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewError categorizes the given error with the given exit code. A nil error is returned as is.
+func NewError(code ExitCode, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// ExitCodeOf returns the exit code resulting of the given error: the one it was categorized with, if any,
+// otherwise the one of the cancelled or expired context it comes from, or ExitFailure.
+func ExitCodeOf(err error) ExitCode {
+	if err == nil {
+		return ExitOK
+	}
+	var cliErr *Error
+	switch {
+	case errors.As(err, &cliErr):
+		return cliErr.Code
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	case errors.Is(err, context.DeadlineExceeded):
+		return ExitTimeout
+	default:
+		return ExitFailure
+	}
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ExitCode
+	}{
+		{name: "should succeed without error", err: nil, want: ExitOK},
+		{name: "should fail with an uncategorized error", err: errors.New("boom"), want: ExitFailure},
+		{name: "should fail with the category of the error", err: NewError(ExitTransferFailed, errors.New("boom")), want: ExitTransferFailed},
+		{name: "should fail with the category of a wrapped error", err: errors.Wrap(NewError(ExitTargetNotFound, errors.New("boom")), "wrapped"), want: ExitTargetNotFound},
+		{name: "should be interrupted with a cancelled context", err: errors.Wrap(context.Canceled, "wrapped"), want: ExitInterrupted},
+		{name: "should time out with an expired context", err: context.DeadlineExceeded, want: ExitTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ExitCodeOf(tt.err))
+		})
+	}
+}
+
+func TestNewError(t *testing.T) {
+	assert.Nil(t, NewError(ExitAgentFailed, nil))
+
+	err := NewError(ExitAgentFailed, errors.New("boom"))
+
+	assert.EqualError(t, err, "boom")
+	assert.Equal(t, "agent-failed", ExitAgentFailed.String())
+	assert.Equal(t, "preflight-failed", ExitPreflightFailed.String())
+	assert.Equal(t, "failure", ExitCode(42).String())
+}
@@ -1,17 +1,30 @@
 package handler
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/josepdcs/kubectl-prof/api"
 	"github.com/josepdcs/kubectl-prof/internal/cli"
 	"github.com/josepdcs/kubectl-prof/internal/cli/config"
 	"github.com/josepdcs/kubectl-prof/internal/cli/result"
+	"github.com/josepdcs/kubectl-prof/internal/cli/version"
+	log "github.com/sirupsen/logrus"
 )
 
 type EventHandler struct {
 	target  *config.TargetConfig
 	printer cli.Printer
+	// hello tells whether the agent introduced itself
+	hello bool
+	// refused tells whether the agent was refused because its events cannot be understood
+	refused bool
+	// warned holds the warnings already printed, which are printed only once
+	warned map[string]bool
+	// err is the failure reported by the agent, if any
+	err error
 }
 
 func NewEventHandler(cfg *config.TargetConfig, printer cli.Printer) *EventHandler {
@@ -23,19 +36,31 @@ func NewEventHandler(cfg *config.TargetConfig, printer cli.Printer) *EventHandle
 
 func (h *EventHandler) Handle(events chan string, done chan bool, resultFile chan result.File) {
 	for eventString := range events {
-		event, _ := api.ParseEvent(eventString)
+		event, err := api.ParseEvent(eventString)
+		if errors.Is(err, api.ErrUnknownEvent) {
+			h.warnOnce(fmt.Sprintf("Ignored %s from the agent, it is likely newer than the CLI", err))
+			continue
+		}
+		if h.refused {
+			continue
+		}
 		switch eventType := event.(type) {
+		case *api.HelloData:
+			h.checkHello(eventType, done)
 		case *api.ErrorData:
-			h.printer.Print(fmt.Sprintf("Error: %s ", eventType.Reason))
-			h.printer.Print("❌\n")
+			h.fail(eventType)
 			done <- true
 		case *api.LogData:
 			if h.target.PrintAgentLogs {
-				h.printer.Print(fmt.Sprintf("Agent[%s]: %s\n", eventType.Level, eventType.Msg))
+				h.printer.Report(fmt.Sprintf("Agent[%s]: %s\n", eventType.Level, eventType.Msg),
+					cli.Record{Stage: cli.AgentLog, Message: eventType.Msg})
 			}
 		case *api.ResultData:
 			resultFile <- result.File{
-				FileName:        eventType.File,
+				// an uploaded result file is named after its object
+				FileName:        cmp.Or(eventType.File, eventType.Object),
+				URL:             eventType.URL,
+				Object:          eventType.Object,
 				FileSizeInBytes: eventType.FileSizeInBytes,
 				Checksum:        eventType.Checksum,
 				Chunks:          eventType.Chunks,
@@ -44,7 +69,7 @@ func (h *EventHandler) Handle(events chan string, done chan bool, resultFile cha
 		case *api.ProgressData:
 			h.reportProgress(eventType, done)
 		case *api.NoticeData:
-			h.printer.Print(fmt.Sprintf("⚠️ %s\n", eventType.Msg))
+			h.printer.Report(fmt.Sprintf("⚠️ %s\n", eventType.Msg), cli.Record{Stage: cli.Notice, Message: eventType.Msg})
 			h.printer.Print("Profiling ... 🔬\n")
 		default:
 		}
@@ -52,13 +77,94 @@ func (h *EventHandler) Handle(events chan string, done chan bool, resultFile cha
 	done <- true
 }
 
+// Err returns the failure reported by the agent, if any, once the handling is done
+func (h *EventHandler) Err() error {
+	return h.err
+}
+
+// fail reports the given failure of the agent, along with its details and remediation hint if categorized
+func (h *EventHandler) fail(data *api.ErrorData) {
+	code := cli.ExitAgentFailed
+	if data.Code == api.PIDNotFound {
+		code = cli.ExitTargetNotFound
+	}
+	h.err = cli.NewError(code, errors.New(data.Reason))
+
+	// agents prior to the error codes only give the reason
+	hint := cmp.Or(data.Hint, data.Code.Hint())
+	text := fmt.Sprintf("Error: %s ", data.Reason)
+	if details := errorDetails(data); details != "" {
+		text = fmt.Sprintf("Error: %s (%s) ", data.Reason, details)
+	}
+	h.printer.Report(text, cli.Record{Stage: cli.Failed, Message: data.Reason, Code: code.String(),
+		ErrorCode: string(data.Code), PID: data.PID, Tool: string(data.Tool), Hint: hint})
+	h.printer.Print("❌\n")
+	if hint != "" {
+		h.printer.Print(fmt.Sprintf("💡 %s\n", hint))
+	}
+}
+
+// errorDetails returns the code, the PID and the tool of the given error, if any, for printing
+func errorDetails(data *api.ErrorData) string {
+	var details []string
+	if data.Code != "" {
+		details = append(details, string(data.Code))
+	}
+	if data.PID != "" {
+		details = append(details, "PID "+data.PID)
+	}
+	if data.Tool != "" {
+		details = append(details, "tool "+string(data.Tool))
+	}
+	return strings.Join(details, ", ")
+}
+
+// checkHello checks whether the events of the agent introduced by the given hello event can be understood.
+// Otherwise, the agent is refused and its events are ignored.
+func (h *EventHandler) checkHello(data *api.HelloData, done chan bool) {
+	h.hello = true
+	log.Debugf("Agent version %s (protocol version %d, kernel %s, arch %s), tools: %v",
+		data.AgentVersion, data.ProtocolVersion, data.Kernel, data.Arch, data.Tools)
+
+	if err := api.CheckProtocolVersion(data.ProtocolVersion); err != nil {
+		h.refused = true
+		h.fail(&api.ErrorData{Reason: err.Error()})
+		done <- true
+		return
+	}
+	h.printer.Report("", cli.Record{Stage: cli.AgentIntroduced,
+		Message: fmt.Sprintf("agent version %s, protocol version %d", data.AgentVersion, data.ProtocolVersion)})
+	if cliVersion := version.GetCurrent(); cliVersion != "" && data.AgentVersion != "" && data.AgentVersion != cliVersion {
+		h.warnOnce(fmt.Sprintf("The agent version %s differs from the CLI version %s", data.AgentVersion, cliVersion))
+	}
+}
+
+// warnOnce prints the given warning unless it was already printed
+func (h *EventHandler) warnOnce(msg string) {
+	if h.warned[msg] {
+		return
+	}
+	if h.warned == nil {
+		h.warned = make(map[string]bool)
+	}
+	h.warned[msg] = true
+	h.printer.Report(fmt.Sprintf("⚠️ %s\n", msg), cli.Record{Stage: cli.Notice, Message: msg})
+}
+
 func (h *EventHandler) reportProgress(data *api.ProgressData, done chan bool) {
 	switch data.Stage {
 	case api.Started:
-		h.printer.Print("Profiling ... 🔬\n")
+		if !h.hello {
+			// agents prior to the versioned protocol do not introduce themselves, but their events are still understood
+			h.warnOnce("The agent did not introduce itself, it is likely older than the CLI")
+		}
+		h.printer.Report("Profiling ... 🔬\n", cli.Record{Stage: cli.Progress, Message: string(data.Stage)})
 	case api.Ended:
 		done <- true
 	case api.Profiling:
-		// heartbeat — keeps log stream alive, no output needed
+		// heartbeats without iterations only keep the log stream alive
+		if data.Iterations > 0 {
+			h.printer.Progress(data)
+		}
 	}
 }
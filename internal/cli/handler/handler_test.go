@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/josepdcs/kubectl-prof/api"
 	"github.com/josepdcs/kubectl-prof/internal/cli"
 	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/internal/cli/result"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestEventHandler_reportProgress(t *testing.T) {
@@ -55,3 +58,144 @@ func TestEventHandler_reportProgress(t *testing.T) {
 		})
 	}
 }
+
+// recordingPrinter records the printed messages
+type recordingPrinter struct {
+	printed []string
+	records []cli.Record
+}
+
+func (p *recordingPrinter) Print(str string) {
+	p.printed = append(p.printed, str)
+}
+
+func (p *recordingPrinter) PrintSuccess() {}
+
+func (p *recordingPrinter) PrintError() {}
+
+func (p *recordingPrinter) Progress(data *api.ProgressData) {
+	p.Print(cli.FormatProgress(data))
+}
+
+func (p *recordingPrinter) Report(text string, record cli.Record) {
+	if text != "" {
+		p.Print(text)
+	}
+	p.records = append(p.records, record)
+}
+
+func TestEventHandler_Handle(t *testing.T) {
+	resultEvent := `{"type":"result","version":1,"data":{"result-type":"flamegraph","file":"/tmp/flamegraph.svg"}}`
+	tests := []struct {
+		name        string
+		events      []string
+		wantPrinted []string
+		wantResults int
+		wantErr     bool
+	}{
+		{
+			name: "should accept an agent speaking the same protocol",
+			events: []string{
+				fmt.Sprintf(`{"type":"hello","version":1,"data":{"protocol-version":%d}}`, api.ProtocolVersion),
+				`{"type":"progress","version":1,"data":{"stage":"started"}}`,
+				resultEvent,
+			},
+			wantPrinted: []string{"Profiling ... 🔬\n"},
+			wantResults: 1,
+		},
+		{
+			name: "should refuse an agent speaking a newer protocol",
+			events: []string{
+				fmt.Sprintf(`{"type":"hello","version":%d,"data":{"protocol-version":%d}}`, api.ProtocolVersion+1, api.ProtocolVersion+1),
+				resultEvent,
+			},
+			wantPrinted: []string{
+				fmt.Sprintf("Error: the agent speaks the protocol version %d but the CLI only understands up to %d, upgrade the CLI ", api.ProtocolVersion+1, api.ProtocolVersion),
+				"❌\n",
+			},
+			wantErr: true,
+		},
+		{
+			name: "should report the failure of the agent",
+			events: []string{
+				fmt.Sprintf(`{"type":"hello","version":1,"data":{"protocol-version":%d}}`, api.ProtocolVersion),
+				`{"type":"error","version":1,"data":{"reason":"profiler not found"}}`,
+			},
+			wantPrinted: []string{"Error: profiler not found ", "❌\n"},
+			wantErr:     true,
+		},
+		{
+			name: "should report the categorized failure of the agent with its remediation hint",
+			events: []string{
+				fmt.Sprintf(`{"type":"hello","version":1,"data":{"protocol-version":%d}}`, api.ProtocolVersion),
+				`{"type":"error","version":1,"data":{"reason":"perf record failed: exit status 255","code":"permission-denied","pid":"1234","tool":"perf","hint":"Run the agent with --privileged"}}`,
+			},
+			wantPrinted: []string{
+				"Error: perf record failed: exit status 255 (permission-denied, PID 1234, tool perf) ",
+				"❌\n",
+				"💡 Run the agent with --privileged\n",
+			},
+			wantErr: true,
+		},
+		{
+			name: "should warn once about an agent prior to the versioned protocol",
+			events: []string{
+				`{"type":"progress","data":{"stage":"started"}}`,
+				`{"type":"progress","data":{"stage":"started"}}`,
+				`{"type":"result","data":{"result-type":"flamegraph","file":"/tmp/flamegraph.svg"}}`,
+			},
+			wantPrinted: []string{
+				"⚠️ The agent did not introduce itself, it is likely older than the CLI\n",
+				"Profiling ... 🔬\n",
+				"Profiling ... 🔬\n",
+			},
+			wantResults: 1,
+		},
+		{
+			name: "should print the progress of the agent but not its bare heartbeats",
+			events: []string{
+				fmt.Sprintf(`{"type":"hello","version":1,"data":{"protocol-version":%d}}`, api.ProtocolVersion),
+				`{"type":"progress","version":1,"data":{"stage":"profiling"}}`,
+				`{"type":"progress","version":1,"data":{"stage":"profiling","iteration":1,"iterations":2,"elapsed":30000000000,"remaining":90000000000,"pids":[{"pid":"1234","state":"running"}]}}`,
+			},
+			wantPrinted: []string{"iteration 1/2, 25% (30s elapsed, 1m30s remaining), PIDs: 1234 running"},
+		},
+		{
+			name: "should warn once about unknown events",
+			events: []string{
+				`{"type":"hello","version":1,"data":{"protocol-version":1}}`,
+				`{"type":"other","version":2,"data":{}}`,
+				`{"type":"other","version":2,"data":{}}`,
+				resultEvent,
+			},
+			wantPrinted: []string{"⚠️ Ignored unknown event type other from the agent, it is likely newer than the CLI\n"},
+			wantResults: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			printer := &recordingPrinter{}
+			h := NewEventHandler(&config.TargetConfig{}, printer)
+			events := make(chan string, len(tt.events))
+			for _, e := range tt.events {
+				events <- e
+			}
+			close(events)
+			done := make(chan bool, 2)
+			resultFile := make(chan result.File, len(tt.events))
+
+			// When
+			h.Handle(events, done, resultFile)
+
+			// Then
+			assert.Equal(t, tt.wantPrinted, printer.printed)
+			assert.Len(t, resultFile, tt.wantResults)
+			if tt.wantErr {
+				assert.Equal(t, cli.ExitAgentFailed, cli.ExitCodeOf(h.Err()))
+			} else {
+				assert.NoError(t, h.Err())
+			}
+		})
+	}
+}
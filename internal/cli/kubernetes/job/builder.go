@@ -0,0 +1,210 @@
+package job
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes"
+	"github.com/josepdcs/kubectl-prof/internal/cli/version"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+// targetFilesystemVolume is the name of the volume holding the root filesystem of the container runtime
+const targetFilesystemVolume = "target-filesystem"
+
+// profile describes the profiling job needed by a profiling tool
+type profile struct {
+	// name is the part of the job name identifying the tool
+	name string
+	// imageVariant is the suffix of the tag of the agent image
+	imageVariant string
+	// alpine tells whether the agent image has an alpine variant
+	alpine bool
+	// capabilities are added to the agent container unless other ones are configured
+	capabilities []apiv1.Capability
+	// unprivileged tells whether the agent container never runs privileged, whatever the configuration
+	unprivileged bool
+	// hostPID tells whether the agent shares the process namespace of the node, along with the filesystem of the
+	// container runtime
+	hostPID bool
+	// hostMounts are the paths of the node mounted into the agent container
+	hostMounts []hostMount
+	// env are the environment variables of the agent container
+	env []apiv1.EnvVar
+	// podIPArg is the argument of the agent given the IP of the target pod, for the tools reaching it over the network
+	podIPArg string
+}
+
+// hostMount is a path of the node mounted at the same path into the agent container
+type hostMount struct {
+	name     string
+	path     string
+	readOnly bool
+}
+
+// jobBuilder builds the profiling job described by a profile
+type jobBuilder struct {
+	profile profile
+}
+
+func (b *jobBuilder) Create(targetPod *apiv1.Pod, cfg *config.ProfilerConfig) (string, *batchv1.Job, error) {
+	id := string(uuid.NewUUID())
+
+	resources, err := cfg.Job.ToResourceRequirements()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "unable to generate resource requirements")
+	}
+
+	var imagePullSecret []apiv1.LocalObjectReference
+	if cfg.Target.ImagePullSecret != "" {
+		imagePullSecret = []apiv1.LocalObjectReference{{Name: cfg.Target.ImagePullSecret}}
+	}
+
+	privileges, err := b.privileges(cfg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	commonMeta := b.getObjectMeta(id, targetPod, cfg)
+	volumes, volumeMounts := volumes(privileges, cfg)
+
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "JobConfig",
+			APIVersion: "batch/v1",
+		},
+		ObjectMeta: commonMeta,
+		Spec: batchv1.JobSpec{
+			Parallelism:             new(int32(1)),
+			Completions:             new(int32(1)),
+			TTLSecondsAfterFinished: new(int32(5)),
+			BackoffLimit:            new(int32(2)),
+			Template: apiv1.PodTemplateSpec{
+				ObjectMeta: commonMeta,
+				Spec: apiv1.PodSpec{
+					HostPID:          privileges.hostPID,
+					Tolerations:      cfg.Job.Tolerations,
+					Volumes:          volumes,
+					ImagePullSecrets: imagePullSecret,
+					InitContainers:   nil,
+					Containers: []apiv1.Container{
+						{
+							ImagePullPolicy: cfg.Target.ImagePullPolicy,
+							Name:            ContainerName,
+							Image:           b.getImageName(cfg.Target),
+							Command:         []string{command},
+							Args:            b.args(targetPod, cfg, id),
+							Env:             slices.Concat(b.profile.env, uploadEnv(cfg)),
+							VolumeMounts:    volumeMounts,
+							SecurityContext: b.securityContext(privileges, cfg),
+							Resources:       resources,
+						},
+					},
+					RestartPolicy: "Never",
+					NodeName:      targetPod.Spec.NodeName,
+				},
+			},
+		},
+	}
+
+	if cfg.Target.ServiceAccountName != "" {
+		job.Spec.Template.Spec.ServiceAccountName = cfg.Target.ServiceAccountName
+	}
+	addOutputVolume(&job.Spec.Template.Spec, cfg)
+
+	return id, job, nil
+}
+
+// getImageName if image name is provided from config.TargetConfig this one is returned otherwise a new one is built
+func (b *jobBuilder) getImageName(t *config.TargetConfig) string {
+	if t.Image != "" {
+		return t.Image
+	}
+
+	tag := fmt.Sprintf("%s-%s", version.GetCurrent(), b.profile.imageVariant)
+	if t.Alpine && b.profile.alpine {
+		tag = fmt.Sprintf("%s-alpine", tag)
+	}
+
+	return fmt.Sprintf("%s:%s", baseImageName, tag)
+}
+
+func (b *jobBuilder) getObjectMeta(id string, targetPod *apiv1.Pod, cfg *config.ProfilerConfig) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      fmt.Sprintf("%s-%s-%s", ContainerName, b.profile.name, id),
+		Namespace: cfg.Job.Namespace,
+		Labels: map[string]string{
+			LabelID: id,
+		},
+		Annotations: annotations(targetPod, cfg),
+	}
+}
+
+// args returns the arguments of the agent
+func (b *jobBuilder) args(targetPod *apiv1.Pod, cfg *config.ProfilerConfig, id string) []string {
+	args := kubernetes.Arguments(targetPod, cfg, id)
+	if b.profile.podIPArg != "" {
+		args = append(args, b.profile.podIPArg, targetPod.Status.PodIP)
+	}
+	return args
+}
+
+// privileges returns the privileges of the agent: the ones of the profile, with the configured capabilities if any,
+// or else, under the minimal security profile, the least ones needed by the profiling tool.
+func (b *jobBuilder) privileges(cfg *config.ProfilerConfig) (leastPrivileges, error) {
+	if cfg.Job.SecurityProfile == config.MinimalSecurityProfile {
+		return leastPrivilegesOf(cfg.Target.ProfilingTool, cfg.Job.Capabilities)
+	}
+	capabilities := cfg.Job.Capabilities
+	if len(capabilities) == 0 {
+		capabilities = b.profile.capabilities
+	}
+	return leastPrivileges{capabilities: capabilities, hostPID: b.profile.hostPID, hostMounts: b.profile.hostMounts}, nil
+}
+
+// volumes returns the volumes of the node needed by the agent, along with their mounts into the agent container
+func volumes(privileges leastPrivileges, cfg *config.ProfilerConfig) ([]apiv1.Volume, []apiv1.VolumeMount) {
+	mounts := privileges.hostMounts
+	if privileges.hostPID {
+		mounts = append([]hostMount{{name: targetFilesystemVolume, path: cfg.Target.ContainerRuntimePath}}, mounts...)
+	}
+
+	var volumes []apiv1.Volume
+	var volumeMounts []apiv1.VolumeMount
+	for _, m := range mounts {
+		volumes = append(volumes, apiv1.Volume{
+			Name: m.name,
+			VolumeSource: apiv1.VolumeSource{
+				HostPath: &apiv1.HostPathVolumeSource{
+					Path: m.path,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, apiv1.VolumeMount{
+			Name:      m.name,
+			MountPath: m.path,
+			ReadOnly:  m.readOnly,
+		})
+	}
+	return volumes, volumeMounts
+}
+
+// securityContext returns the security context of the agent container with the given capabilities.
+// Under the minimal security profile, the container is never privileged.
+func (b *jobBuilder) securityContext(privileges leastPrivileges, cfg *config.ProfilerConfig) *apiv1.SecurityContext {
+	privileged := cfg.Job.Privileged && !b.profile.unprivileged && cfg.Job.SecurityProfile != config.MinimalSecurityProfile
+	securityContext := &apiv1.SecurityContext{
+		Privileged: &privileged,
+	}
+	if len(privileges.capabilities) > 0 {
+		securityContext.Capabilities = &apiv1.Capabilities{
+			Add: slices.Clone(privileges.capabilities),
+		}
+	}
+	return securityContext
+}
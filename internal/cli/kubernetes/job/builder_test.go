@@ -0,0 +1,459 @@
+package job
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_jobBuilder_Create(t *testing.T) {
+	targetPod := &apiv1.Pod{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Pod",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			UID: "UID",
+		},
+		Spec: apiv1.PodSpec{
+			NodeName: "NodeName",
+		},
+	}
+	cfg := &config.ProfilerConfig{
+		Target: &config.TargetConfig{
+			Namespace:            "Namespace",
+			PodName:              "PodName",
+			ContainerName:        "ContainerName",
+			ContainerID:          "ContainerID",
+			Event:                "Event",
+			Duration:             100,
+			Id:                   "ID",
+			LocalPath:            "LocalPath",
+			Alpine:               false,
+			DryRun:               false,
+			Image:                "Image",
+			ContainerRuntime:     "ContainerRuntime",
+			ContainerRuntimePath: "ContainerRuntimePath",
+			Language:             "Language",
+			Compressor:           "Compressor",
+			ImagePullSecret:      "ImagePullSecret",
+			ServiceAccountName:   "ServiceAccountName",
+			ImagePullPolicy:      apiv1.PullAlways,
+		},
+		Job: &config.JobConfig{
+			ContainerConfig: config.ContainerConfig{
+				RequestConfig: config.ResourceConfig{
+					CPU:    "100m",
+					Memory: "100Mi",
+				},
+				LimitConfig: config.ResourceConfig{
+					CPU:    "200m",
+					Memory: "200Mi",
+				},
+				Privileged: false,
+			},
+			Namespace: "Namespace",
+		},
+	}
+	b := &jobBuilder{profile: jvmProfile}
+	id, job, err := b.Create(targetPod, cfg)
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, id)
+	assert.NotEmpty(t, job)
+
+	wantedObjectMeta := b.getObjectMeta(id, targetPod, cfg)
+	assert.Equal(t, job.ObjectMeta, wantedObjectMeta)
+
+	resources, err := cfg.Job.ToResourceRequirements()
+
+	wantedJob := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "JobConfig",
+			APIVersion: "batch/v1",
+		},
+		ObjectMeta: wantedObjectMeta,
+		Spec: batchv1.JobSpec{
+			Parallelism:             new(int32(1)),
+			Completions:             new(int32(1)),
+			TTLSecondsAfterFinished: new(int32(5)),
+			BackoffLimit:            new(int32(2)),
+			Template: apiv1.PodTemplateSpec{
+				ObjectMeta: wantedObjectMeta,
+				Spec: apiv1.PodSpec{
+					HostPID:     true,
+					Tolerations: cfg.Job.Tolerations,
+					Volumes: []apiv1.Volume{
+						{
+							Name: "target-filesystem",
+							VolumeSource: apiv1.VolumeSource{
+								HostPath: &apiv1.HostPathVolumeSource{
+									Path: cfg.Target.ContainerRuntimePath,
+								},
+							},
+						},
+					},
+					ImagePullSecrets: []apiv1.LocalObjectReference{{Name: cfg.Target.ImagePullSecret}},
+					InitContainers:   nil,
+					Containers: []apiv1.Container{
+						{
+							ImagePullPolicy: apiv1.PullAlways,
+							Name:            ContainerName,
+							Image:           cfg.Target.Image,
+							Command:         []string{command},
+							Args:            kubernetes.Arguments(targetPod, cfg, id),
+							VolumeMounts: []apiv1.VolumeMount{
+								{
+									Name:      "target-filesystem",
+									MountPath: cfg.Target.ContainerRuntimePath,
+								},
+							},
+							SecurityContext: &apiv1.SecurityContext{
+								Privileged: &cfg.Job.Privileged,
+								Capabilities: &apiv1.Capabilities{
+									Add: jvmProfile.capabilities,
+								},
+							},
+							Resources: resources,
+						},
+					},
+					RestartPolicy:      "Never",
+					NodeName:           targetPod.Spec.NodeName,
+					ServiceAccountName: cfg.Target.ServiceAccountName,
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, job, wantedJob)
+}
+
+func Test_jobBuilder_Create_shouldFailWhenUnableGenerateResources(t *testing.T) {
+	targetPod := &apiv1.Pod{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Pod",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			UID: "UID",
+		},
+		Spec: apiv1.PodSpec{
+			NodeName: "NodeName",
+		},
+	}
+	cfg := &config.ProfilerConfig{
+		Target: &config.TargetConfig{
+			Namespace:            "Namespace",
+			PodName:              "PodName",
+			ContainerName:        "ContainerName",
+			ContainerID:          "ContainerID",
+			Event:                "Event",
+			Duration:             100,
+			Id:                   "ID",
+			LocalPath:            "LocalPath",
+			DryRun:               false,
+			Image:                "Image",
+			ContainerRuntime:     "ContainerRuntime",
+			ContainerRuntimePath: "ContainerRuntimePath",
+			Language:             "Language",
+			Compressor:           "Compressor",
+			ServiceAccountName:   "ServiceAccountName",
+			ImagePullPolicy:      apiv1.PullAlways,
+		},
+		Job: &config.JobConfig{
+			ContainerConfig: config.ContainerConfig{
+				RequestConfig: config.ResourceConfig{
+					CPU:    "error",
+					Memory: "100Mi",
+				},
+				LimitConfig: config.ResourceConfig{
+					CPU:    "error",
+					Memory: "200Mi",
+				},
+				Privileged: false,
+			},
+			Namespace: "Namespace",
+		},
+	}
+	b := &jobBuilder{profile: jvmProfile}
+	id, job, err := b.Create(targetPod, cfg)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to generate resource requirements")
+	assert.Empty(t, id)
+	assert.Empty(t, job)
+
+}
+
+func Test_jobBuilder_Create_profiles(t *testing.T) {
+	targetPod := &apiv1.Pod{
+		Spec:   apiv1.PodSpec{NodeName: "NodeName"},
+		Status: apiv1.PodStatus{PodIP: "10.0.0.1"},
+	}
+	tests := []struct {
+		name             string
+		profile          profile
+		wantName         string
+		wantHostPID      bool
+		wantVolumes      []string
+		wantCapabilities []apiv1.Capability
+		wantPrivileged   bool
+		wantEnv          []string
+		wantArgs         []string
+	}{
+		{
+			name:             "bpf mounts the kernel modules",
+			profile:          bpfProfile,
+			wantName:         "kubectl-prof-bpf-",
+			wantHostPID:      true,
+			wantVolumes:      []string{"target-filesystem", "modules"},
+			wantCapabilities: []apiv1.Capability{"SYS_ADMIN"},
+			wantPrivileged:   true,
+		},
+		{
+			name:             "btf mounts the sysfs",
+			profile:          btfProfile,
+			wantName:         "kubectl-prof-btf-",
+			wantHostPID:      true,
+			wantVolumes:      []string{"target-filesystem", "sys"},
+			wantCapabilities: []apiv1.Capability{"SYS_ADMIN", "PERFMON", "BPF"},
+			wantPrivileged:   true,
+		},
+		{
+			name:           "dummy adds no capability",
+			profile:        dummyProfile,
+			wantName:       "kubectl-prof-dummy-",
+			wantHostPID:    true,
+			wantVolumes:    []string{"target-filesystem"},
+			wantPrivileged: true,
+		},
+		{
+			name:     "pprof reaches the target pod without any access to the node",
+			profile:  pprofProfile,
+			wantName: "kubectl-prof-pprof-",
+			wantArgs: []string{"--pprof-host", "10.0.0.1"},
+		},
+		{
+			name:             "rust sets the path",
+			profile:          rustProfile,
+			wantName:         "kubectl-prof-rust-",
+			wantHostPID:      true,
+			wantVolumes:      []string{"target-filesystem"},
+			wantCapabilities: []apiv1.Capability{"SYS_PTRACE", "SYS_ADMIN"},
+			wantPrivileged:   true,
+			wantEnv:          []string{"PATH"},
+		},
+		{
+			name:             "memray adds SYS_ADMIN",
+			profile:          memrayProfile,
+			wantName:         "kubectl-prof-python-",
+			wantHostPID:      true,
+			wantVolumes:      []string{"target-filesystem"},
+			wantCapabilities: []apiv1.Capability{"SYS_PTRACE", "SYS_ADMIN"},
+			wantPrivileged:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			cfg := &config.ProfilerConfig{
+				Target: &config.TargetConfig{ContainerRuntimePath: "ContainerRuntimePath"},
+				Job:    &config.JobConfig{ContainerConfig: config.ContainerConfig{Privileged: true}},
+			}
+			b := &jobBuilder{profile: tt.profile}
+
+			// When
+			_, job, err := b.Create(targetPod, cfg)
+
+			// Then
+			require.NoError(t, err)
+			spec := job.Spec.Template.Spec
+			container := spec.Containers[0]
+			assert.True(t, strings.HasPrefix(job.Name, tt.wantName))
+			assert.Equal(t, tt.wantHostPID, spec.HostPID)
+			var volumes, mounts []string
+			for i := range spec.Volumes {
+				volumes = append(volumes, spec.Volumes[i].Name)
+				mounts = append(mounts, container.VolumeMounts[i].Name)
+			}
+			assert.Equal(t, tt.wantVolumes, volumes)
+			assert.Equal(t, tt.wantVolumes, mounts)
+			if tt.wantCapabilities != nil {
+				assert.Equal(t, tt.wantCapabilities, container.SecurityContext.Capabilities.Add)
+			} else {
+				assert.Nil(t, container.SecurityContext.Capabilities)
+			}
+			assert.Equal(t, tt.wantPrivileged, *container.SecurityContext.Privileged)
+			var env []string
+			for _, e := range container.Env {
+				env = append(env, e.Name)
+			}
+			assert.Equal(t, tt.wantEnv, env)
+			for _, arg := range tt.wantArgs {
+				assert.Contains(t, container.Args, arg)
+			}
+		})
+	}
+}
+
+func Test_jobBuilder_Create_withConfiguredCapabilities(t *testing.T) {
+	cfg := &config.ProfilerConfig{
+		Target: &config.TargetConfig{},
+		Job:    &config.JobConfig{ContainerConfig: config.ContainerConfig{Capabilities: []apiv1.Capability{"SYS_PTRACE"}}},
+	}
+	b := &jobBuilder{profile: bpfProfile}
+
+	_, job, err := b.Create(&apiv1.Pod{}, cfg)
+
+	require.NoError(t, err)
+	assert.Equal(t, []apiv1.Capability{"SYS_PTRACE"}, job.Spec.Template.Spec.Containers[0].SecurityContext.Capabilities.Add)
+	assert.Equal(t, []apiv1.Capability{"SYS_ADMIN"}, bpfProfile.capabilities)
+}
+
+func Test_jobBuilder_Create_withMinimalSecurityProfile(t *testing.T) {
+	tests := []struct {
+		name             string
+		profile          profile
+		tool             api.ProfilingTool
+		capabilities     []apiv1.Capability
+		wantCapabilities []apiv1.Capability
+		wantHostPID      bool
+		wantVolumes      []string
+		wantErr          bool
+	}{
+		{
+			name:             "btf gets BPF and PERFMON along with the process namespace and /sys of the node",
+			profile:          btfProfile,
+			tool:             api.Btf,
+			wantCapabilities: []apiv1.Capability{"BPF", "PERFMON"},
+			wantHostPID:      true,
+			wantVolumes:      []string{targetFilesystemVolume, "sys"},
+		},
+		{
+			name:             "pyspy gets SYS_PTRACE along with the process namespace of the node",
+			profile:          pythonProfile,
+			tool:             api.Pyspy,
+			wantCapabilities: []apiv1.Capability{"SYS_PTRACE"},
+			wantHostPID:      true,
+			wantVolumes:      []string{targetFilesystemVolume},
+		},
+		{
+			name:    "pprof gets no capability nor access to the node",
+			profile: pprofProfile,
+			tool:    api.GoPprof,
+		},
+		{
+			name:    "fake tool gets no access to the node whatever its profile",
+			profile: bpfProfile,
+			tool:    api.FakeTool,
+		},
+		{
+			name:             "configured capabilities are kept",
+			profile:          perfProfile,
+			tool:             api.Perf,
+			capabilities:     []apiv1.Capability{"SYS_ADMIN"},
+			wantCapabilities: []apiv1.Capability{"SYS_ADMIN"},
+			wantHostPID:      true,
+			wantVolumes:      []string{targetFilesystemVolume},
+		},
+		{
+			name:    "tool without minimal security profile",
+			profile: bpfProfile,
+			tool:    "other",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			cfg := &config.ProfilerConfig{
+				Target: &config.TargetConfig{ProfilingTool: tt.tool},
+				Job: &config.JobConfig{ContainerConfig: config.ContainerConfig{
+					Privileged:      true,
+					Capabilities:    tt.capabilities,
+					SecurityProfile: config.MinimalSecurityProfile,
+				}},
+			}
+			b := &jobBuilder{profile: tt.profile}
+
+			// When
+			_, job, err := b.Create(&apiv1.Pod{}, cfg)
+
+			// Then
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			spec := job.Spec.Template.Spec
+			securityContext := spec.Containers[0].SecurityContext
+			assert.False(t, *securityContext.Privileged)
+			if tt.wantCapabilities != nil {
+				assert.Equal(t, tt.wantCapabilities, securityContext.Capabilities.Add)
+			} else {
+				assert.Nil(t, securityContext.Capabilities)
+			}
+			assert.Equal(t, tt.wantHostPID, spec.HostPID)
+			var gotVolumes []string
+			for _, v := range spec.Volumes {
+				gotVolumes = append(gotVolumes, v.Name)
+			}
+			assert.Equal(t, tt.wantVolumes, gotVolumes)
+		})
+	}
+}
+
+func Test_jobBuilder_getImageName(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile profile
+		cfg     *config.TargetConfig
+		want    string
+	}{
+		{
+			name:    "image name from TargetConfig",
+			profile: jvmProfile,
+			cfg:     &config.TargetConfig{Image: "Image"},
+			want:    "Image",
+		},
+		{
+			name:    "default image",
+			profile: jvmProfile,
+			cfg:     &config.TargetConfig{},
+			want:    "josepdcs/kubectl-prof:-jvm",
+		},
+		{
+			name:    "alpine image",
+			profile: jvmProfile,
+			cfg:     &config.TargetConfig{Alpine: true},
+			want:    "josepdcs/kubectl-prof:-jvm-alpine",
+		},
+		{
+			name:    "no alpine image when the profile has none",
+			profile: bpfProfile,
+			cfg:     &config.TargetConfig{Alpine: true},
+			want:    "josepdcs/kubectl-prof:-bpf",
+		},
+		{
+			name:    "image of another profile",
+			profile: pprofProfile,
+			cfg:     &config.TargetConfig{},
+			want:    "josepdcs/kubectl-prof:-dummy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &jobBuilder{profile: tt.profile}
+			assert.Equalf(t, tt.want, b.getImageName(tt.cfg), "getImageName(%v)", tt.cfg)
+		})
+	}
+}
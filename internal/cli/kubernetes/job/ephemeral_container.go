@@ -0,0 +1,51 @@
+package job
+
+import (
+	"fmt"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// NewEphemeralContainer returns the profiling agent as an ephemeral container to be injected into the target pod,
+// together with the profiling ID. The container is derived from the one of the profiling job for the same language
+// and profiling tool, and it shares the process namespace of the target container instead of using hostPID and
+// hostPath volumes. Since ephemeral containers cannot declare volumes nor resources, these ones are discarded.
+func NewEphemeralContainer(targetPod *apiv1.Pod, cfg *config.ProfilerConfig) (string, *apiv1.EphemeralContainer, error) {
+	creator, err := NewCreator(cfg.Target.Language, cfg.Target.ProfilingTool)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "unable to create the job creator")
+	}
+
+	// the agent resolves the target container through the shared process namespace
+	ephemeralCfg := cfg.DeepCopy()
+	ephemeralCfg.Target.ContainerRuntime = api.SharedProcessNamespace
+	ephemeralCfg.Target.ContainerRuntimePath = ""
+
+	id, job, err := creator.Create(targetPod, ephemeralCfg)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "unable to create job")
+	}
+
+	container := job.Spec.Template.Spec.Containers[0]
+	return id, &apiv1.EphemeralContainer{
+		EphemeralContainerCommon: apiv1.EphemeralContainerCommon{
+			Name:            EphemeralContainerName(id),
+			Image:           container.Image,
+			Command:         container.Command,
+			Args:            container.Args,
+			Env:             container.Env,
+			ImagePullPolicy: container.ImagePullPolicy,
+			SecurityContext: container.SecurityContext,
+		},
+		TargetContainerName: cfg.Target.ContainerName,
+	}, nil
+}
+
+// EphemeralContainerName returns the name of the profiling ephemeral container for the given profiling ID.
+// Ephemeral containers cannot be removed from a pod, so each profiling needs its own container name.
+func EphemeralContainerName(id string) string {
+	return fmt.Sprintf("%s-%s", ContainerName, id)
+}
@@ -0,0 +1,85 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewEphemeralContainer(t *testing.T) {
+	targetPod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "PodName", UID: "UID"},
+		Spec:       apiv1.PodSpec{NodeName: "NodeName"},
+	}
+
+	tests := []struct {
+		name string
+		cfg  *config.ProfilerConfig
+		then func(t *testing.T, id string, container *apiv1.EphemeralContainer, err error)
+	}{
+		{
+			name: "should derive the ephemeral container from the profiling job",
+			cfg: &config.ProfilerConfig{
+				Target: &config.TargetConfig{
+					ContainerName:        "app",
+					ContainerID:          "containerd://1234",
+					ContainerRuntime:     api.Containerd,
+					ContainerRuntimePath: "/run/containerd",
+					Language:             api.Go,
+					ProfilingTool:        api.Perf,
+					OutputType:           api.FlameGraph,
+					Image:                "Image",
+					ImagePullPolicy:      apiv1.PullIfNotPresent,
+				},
+				Job: &config.JobConfig{
+					ContainerConfig: config.ContainerConfig{
+						LimitConfig: config.ResourceConfig{CPU: "200m"},
+					},
+				},
+			},
+			then: func(t *testing.T, id string, container *apiv1.EphemeralContainer, err error) {
+				require.NoError(t, err)
+				assert.NotEmpty(t, id)
+				assert.Equal(t, "kubectl-prof-"+id, container.Name)
+				assert.Equal(t, "app", container.TargetContainerName)
+				assert.Equal(t, "Image", container.Image)
+				assert.Equal(t, apiv1.PullIfNotPresent, container.ImagePullPolicy)
+				assert.Equal(t, []string{command}, container.Command)
+				assert.Contains(t, container.Args, string(api.SharedProcessNamespace))
+				assert.Contains(t, container.Args, "containerd://1234")
+				assert.Empty(t, container.VolumeMounts)
+				assert.Empty(t, container.Resources)
+				assert.NotNil(t, container.SecurityContext)
+			},
+		},
+		{
+			name: "should fail when there is no job creator",
+			cfg: &config.ProfilerConfig{
+				Target: &config.TargetConfig{Language: "unknown"},
+				Job:    &config.JobConfig{},
+			},
+			then: func(t *testing.T, id string, container *apiv1.EphemeralContainer, err error) {
+				require.Error(t, err)
+				assert.Nil(t, container)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			id, container, err := NewEphemeralContainer(targetPod, tt.cfg)
+
+			// Then
+			tt.then(t, id, container, err)
+			if err == nil {
+				// the given config is not modified
+				assert.Equal(t, api.Containerd, tt.cfg.Target.ContainerRuntime)
+			}
+		})
+	}
+}
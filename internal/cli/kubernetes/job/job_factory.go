@@ -22,168 +22,49 @@ type Creator interface {
 	Create(targetPod *apiv1.Pod, cfg *config.ProfilerConfig) (string, *batchv1.Job, error)
 }
 
-// jobCreatorLink defines the interface for the job creator chain of responsibility.
-type jobCreatorLink interface {
-	// next sets the next link in the chain.
-	next(link jobCreatorLink) jobCreatorLink
-	// get returns the Creator implementation according the programming language and profiling tool.
-	get(lang api.ProgrammingLanguage, tool api.ProfilingTool) (Creator, error)
-}
-
-// baseJobCreatorLink is the base implementation for the job creator chain of responsibility.
-type baseJobCreatorLink struct {
-	nextLink jobCreatorLink
-}
-
-// next sets the next link in the chain.
-func (b *baseJobCreatorLink) next(link jobCreatorLink) jobCreatorLink {
-	b.nextLink = link
-	return link
-}
-
-// get returns the Creator implementation according the programming language and profiling tool from the next link in the chain.
-func (b *baseJobCreatorLink) get(lang api.ProgrammingLanguage, tool api.ProfilingTool) (Creator, error) {
-	if b.nextLink != nil {
-		return b.nextLink.get(lang, tool)
-	}
-	return nil, errors.New("got language without job creator")
-}
-
-// jvmCreatorLink is the job creator link for Java.
-type jvmCreatorLink struct {
-	baseJobCreatorLink
-}
-
-// get returns the Creator implementation for Java.
-func (l *jvmCreatorLink) get(lang api.ProgrammingLanguage, tool api.ProfilingTool) (Creator, error) {
-	if lang == api.Java {
-		return &jvmCreator{}, nil
-	}
-	return l.baseJobCreatorLink.get(lang, tool)
-}
-
-// nativeCreatorLink is the job creator link for Go, Clang, ClangPlusPlus and Node.
-type nativeCreatorLink struct {
-	baseJobCreatorLink
-}
-
-// get returns the Creator implementation for Go, Clang, ClangPlusPlus and Node according to the profiling tool.
-func (l *nativeCreatorLink) get(lang api.ProgrammingLanguage, tool api.ProfilingTool) (Creator, error) {
-	if lang == api.Go || lang == api.Clang || lang == api.ClangPlusPlus || lang == api.Node {
-		if tool == api.Perf {
-			return &perfCreator{}, nil
-		}
-		if tool == api.NodeDummy {
-			return &dummyCreator{}, nil
-		}
-		if tool == api.Btf {
-			return &btfCreator{}, nil
-		}
-		if tool == api.GoPprof {
-			return &pprofCreator{}, nil
-		}
-		return &bpfCreator{}, nil
-	}
-	return l.baseJobCreatorLink.get(lang, tool)
-}
-
-// rustCreatorLink is the job creator link for Rust.
-type rustCreatorLink struct {
-	baseJobCreatorLink
-}
-
-// get returns the Creator implementation for Rust.
-func (l *rustCreatorLink) get(lang api.ProgrammingLanguage, tool api.ProfilingTool) (Creator, error) {
-	if lang == api.Rust {
-		if tool == api.CargoFlame {
-			return &rustCreator{}, nil
-		}
-		if tool == api.Perf {
-			return &perfCreator{}, nil
-		}
-		if tool == api.Btf {
-			return &btfCreator{}, nil
-		}
-		return &bpfCreator{}, nil
-	}
-	return l.baseJobCreatorLink.get(lang, tool)
-}
-
-// pythonCreatorLink is the job creator link for Python.
-type pythonCreatorLink struct {
-	baseJobCreatorLink
-}
-
-// get returns the Creator implementation for Python.
-func (l *pythonCreatorLink) get(lang api.ProgrammingLanguage, tool api.ProfilingTool) (Creator, error) {
-	if lang == api.Python {
-		return &pythonCreator{}, nil
-	}
-	return l.baseJobCreatorLink.get(lang, tool)
-}
-
-// rubyCreatorLink is the job creator link for Ruby.
-type rubyCreatorLink struct {
-	baseJobCreatorLink
-}
-
-// get returns the Creator implementation for Ruby.
-func (l *rubyCreatorLink) get(lang api.ProgrammingLanguage, tool api.ProfilingTool) (Creator, error) {
-	if lang == api.Ruby {
-		return &rubyCreator{}, nil
-	}
-	return l.baseJobCreatorLink.get(lang, tool)
-}
-
-// phpCreatorLink is the job creator link for PHP.
-type phpCreatorLink struct {
-	baseJobCreatorLink
-}
-
-// get returns the Creator implementation for PHP.
-func (l *phpCreatorLink) get(lang api.ProgrammingLanguage, tool api.ProfilingTool) (Creator, error) {
-	if lang == api.PHP {
-		return &phpCreator{}, nil
-	}
-	return l.baseJobCreatorLink.get(lang, tool)
-}
-
-// dotnetCreatorLink is the job creator link for DotNet.
-type dotnetCreatorLink struct {
-	baseJobCreatorLink
-}
-
-// get returns the Creator implementation for DotNet.
-func (l *dotnetCreatorLink) get(lang api.ProgrammingLanguage, tool api.ProfilingTool) (Creator, error) {
-	if lang == api.DotNet {
-		return &dotnetCreator{}, nil
+// anyTool is the key of the registry for the creator used when no other one is registered for the profiling tool
+const anyTool api.ProfilingTool = ""
+
+// nativeCreators are the creators for the native languages according to the profiling tool
+var nativeCreators = map[api.ProfilingTool]Creator{
+	api.Perf:      &jobBuilder{profile: perfProfile},
+	api.NodeDummy: &jobBuilder{profile: dummyProfile},
+	api.Btf:       &jobBuilder{profile: btfProfile},
+	api.GoPprof:   &jobBuilder{profile: pprofProfile},
+	anyTool:       &jobBuilder{profile: bpfProfile},
+}
+
+// registry holds the Creator implementations according the programming language and profiling tool
+var registry = map[api.ProgrammingLanguage]map[api.ProfilingTool]Creator{
+	api.Java:          {anyTool: &jobBuilder{profile: jvmProfile}},
+	api.Go:            nativeCreators,
+	api.Clang:         nativeCreators,
+	api.ClangPlusPlus: nativeCreators,
+	api.Node:          nativeCreators,
+	api.Rust: {
+		api.CargoFlame: &jobBuilder{profile: rustProfile},
+		api.Perf:       &jobBuilder{profile: perfProfile},
+		api.Btf:        &jobBuilder{profile: btfProfile},
+		anyTool:        &jobBuilder{profile: bpfProfile},
+	},
+	api.Python: {
+		api.Memray: &jobBuilder{profile: memrayProfile},
+		anyTool:    &jobBuilder{profile: pythonProfile},
+	},
+	api.Ruby:     {anyTool: &jobBuilder{profile: rubyProfile}},
+	api.PHP:      {anyTool: &jobBuilder{profile: phpProfile}},
+	api.DotNet:   {anyTool: &jobBuilder{profile: dotnetProfile}},
+	api.FakeLang: {anyTool: &fakeCreator{}},
+}
+
+// NewCreator returns the Creator implementation according the programming language and profiling tool.
+func NewCreator(lang api.ProgrammingLanguage, tool api.ProfilingTool) (Creator, error) {
+	creators, ok := registry[lang]
+	if !ok {
+		return nil, errors.New("got language without job creator")
 	}
-	return l.baseJobCreatorLink.get(lang, tool)
-}
-
-// fakeCreatorLink is the job creator link for FakeLang.
-type fakeCreatorLink struct {
-	baseJobCreatorLink
-}
-
-// get returns the Creator implementation for FakeLang.
-func (l *fakeCreatorLink) get(lang api.ProgrammingLanguage, tool api.ProfilingTool) (Creator, error) {
-	if lang == api.FakeLang {
-		return &fakeCreator{}, nil
+	if creator, ok := creators[tool]; ok {
+		return creator, nil
 	}
-	return l.baseJobCreatorLink.get(lang, tool)
-}
-
-// NewCreator returns the Creator implementation according the programming language.
-func NewCreator(lang api.ProgrammingLanguage, tool api.ProfilingTool) (Creator, error) {
-	chain := &jvmCreatorLink{}
-	chain.next(&nativeCreatorLink{}).
-		next(&rustCreatorLink{}).
-		next(&pythonCreatorLink{}).
-		next(&rubyCreatorLink{}).
-		next(&phpCreatorLink{}).
-		next(&dotnetCreatorLink{}).
-		next(&fakeCreatorLink{})
-
-	return chain.get(lang, tool)
+	return creators[anyTool], nil
 }
@@ -23,21 +23,21 @@ func TestGet(t *testing.T) {
 			args: args{
 				lang: api.Java,
 			},
-			want: &jvmCreator{},
+			want: &jobBuilder{profile: jvmProfile},
 		},
 		{
 			name: "go creator is instanced",
 			args: args{
 				lang: api.Go,
 			},
-			want: &bpfCreator{},
+			want: &jobBuilder{profile: bpfProfile},
 		},
 		{
 			name: "python creator is instanced",
 			args: args{
 				lang: api.Python,
 			},
-			want: &pythonCreator{},
+			want: &jobBuilder{profile: pythonProfile},
 		},
 		{
 			name: "python with memray creator is instanced",
@@ -45,35 +45,35 @@ func TestGet(t *testing.T) {
 				lang: api.Python,
 				tool: api.Memray,
 			},
-			want: &pythonCreator{},
+			want: &jobBuilder{profile: memrayProfile},
 		},
 		{
 			name: "ruby creator is instanced",
 			args: args{
 				lang: api.Ruby,
 			},
-			want: &rubyCreator{},
+			want: &jobBuilder{profile: rubyProfile},
 		},
 		{
 			name: "php creator is instanced",
 			args: args{
 				lang: api.PHP,
 			},
-			want: &phpCreator{},
+			want: &jobBuilder{profile: phpProfile},
 		},
 		{
 			name: "dotnet creator is instanced",
 			args: args{
 				lang: api.DotNet,
 			},
-			want: &dotnetCreator{},
+			want: &jobBuilder{profile: dotnetProfile},
 		},
 		{
 			name: "node creator is instanced",
 			args: args{
 				lang: api.Node,
 			},
-			want: &bpfCreator{},
+			want: &jobBuilder{profile: bpfProfile},
 		},
 		{
 			name: "node with perf creator is instanced",
@@ -81,7 +81,7 @@ func TestGet(t *testing.T) {
 				lang: api.Node,
 				tool: api.Perf,
 			},
-			want: &perfCreator{},
+			want: &jobBuilder{profile: perfProfile},
 		},
 		{
 			name: "node with node dummy creator is instanced",
@@ -89,14 +89,14 @@ func TestGet(t *testing.T) {
 				lang: api.Node,
 				tool: api.NodeDummy,
 			},
-			want: &dummyCreator{},
+			want: &jobBuilder{profile: dummyProfile},
 		},
 		{
 			name: "rust creator is instanced",
 			args: args{
 				lang: api.Rust,
 			},
-			want: &bpfCreator{},
+			want: &jobBuilder{profile: bpfProfile},
 		},
 		{
 			name: "rust with perf creator is instanced",
@@ -104,7 +104,7 @@ func TestGet(t *testing.T) {
 				lang: api.Rust,
 				tool: api.Perf,
 			},
-			want: &perfCreator{},
+			want: &jobBuilder{profile: perfProfile},
 		},
 		{
 			name: "rust with cargo-flamegraph creator is instanced",
@@ -112,14 +112,14 @@ func TestGet(t *testing.T) {
 				lang: api.Rust,
 				tool: api.CargoFlame,
 			},
-			want: &rustCreator{},
+			want: &jobBuilder{profile: rustProfile},
 		},
 		{
 			name: "clang creator is instanced",
 			args: args{
 				lang: api.Clang,
 			},
-			want: &bpfCreator{},
+			want: &jobBuilder{profile: bpfProfile},
 		},
 		{
 			name: "clang with perf creator is instanced",
@@ -127,14 +127,14 @@ func TestGet(t *testing.T) {
 				lang: api.Clang,
 				tool: api.Perf,
 			},
-			want: &perfCreator{},
+			want: &jobBuilder{profile: perfProfile},
 		},
 		{
 			name: "clang++ creator is instanced",
 			args: args{
 				lang: api.ClangPlusPlus,
 			},
-			want: &bpfCreator{},
+			want: &jobBuilder{profile: bpfProfile},
 		},
 		{
 			name: "clang++ with perf creator is instanced",
@@ -142,7 +142,23 @@ func TestGet(t *testing.T) {
 				lang: api.ClangPlusPlus,
 				tool: api.Perf,
 			},
-			want: &perfCreator{},
+			want: &jobBuilder{profile: perfProfile},
+		},
+		{
+			name: "go with btf creator is instanced",
+			args: args{
+				lang: api.Go,
+				tool: api.Btf,
+			},
+			want: &jobBuilder{profile: btfProfile},
+		},
+		{
+			name: "go with pprof creator is instanced",
+			args: args{
+				lang: api.Go,
+				tool: api.GoPprof,
+			},
+			want: &jobBuilder{profile: pprofProfile},
 		},
 		{
 			name: "fake creator is instanced",
@@ -166,7 +182,7 @@ func TestGet(t *testing.T) {
 			if err != nil {
 				assert.Contains(t, err.Error(), tt.containedErrMsg)
 			} else {
-				assert.IsType(t, tt.want, got)
+				assert.Equal(t, tt.want, got)
 			}
 		})
 	}
@@ -0,0 +1,122 @@
+package job
+
+import (
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+const (
+	AnnotationLanguage        = "kubectl-prof/lang"
+	AnnotationTool            = "kubectl-prof/tool"
+	AnnotationTarget          = "kubectl-prof/target"
+	AnnotationTargetNamespace = "kubectl-prof/target-namespace"
+	AnnotationTargetContainer = "kubectl-prof/target-container"
+)
+
+// Phase represents the phase of a profiling job.
+type Phase string
+
+const (
+	PendingPhase   Phase = "Pending"   // PendingPhase means that the agent has not started yet.
+	RunningPhase   Phase = "Running"   // RunningPhase means that the agent is running.
+	SucceededPhase Phase = "Succeeded" // SucceededPhase means that the agent ended successfully.
+	FailedPhase    Phase = "Failed"    // FailedPhase means that the agent failed.
+)
+
+// Info holds the metadata of a profiling job read from the labels and annotations set at creation time.
+type Info struct {
+	ID              string
+	Name            string
+	Namespace       string
+	Target          string
+	TargetNamespace string
+	TargetContainer string
+	Language        string
+	Tool            string
+	Node            string
+	CreationTime    time.Time
+	Phase           Phase
+}
+
+// IsFinished returns true if the agent of the profiling job has already ended.
+func (i Info) IsFinished() bool {
+	return i.Phase == SucceededPhase || i.Phase == FailedPhase
+}
+
+// Age returns how long ago the profiling job was created.
+func (i Info) Age(now time.Time) time.Duration {
+	return now.Sub(i.CreationTime)
+}
+
+// TargetPod returns the namespace and the name of the target pod.
+// The name is empty when the target is not a pod (e.g. a node).
+func (i Info) TargetPod() (string, string) {
+	kind, name, err := config.ParseTargetRef(i.Target)
+	if err != nil || kind != config.Pod {
+		return "", ""
+	}
+	return i.TargetNamespace, name
+}
+
+// TargetName returns the name of the profiling target (pod or node) recorded in the given annotations
+// of a profiling job or pod. If it is not recorded, empty string is returned.
+func TargetName(annotations map[string]string) string {
+	_, name, err := config.ParseTargetRef(annotations[AnnotationTarget])
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// InfoOf returns the metadata of the given profiling job.
+func InfoOf(job *batchv1.Job) Info {
+	return Info{
+		ID:              job.Labels[LabelID],
+		Name:            job.Name,
+		Namespace:       job.Namespace,
+		Target:          job.Annotations[AnnotationTarget],
+		TargetNamespace: job.Annotations[AnnotationTargetNamespace],
+		TargetContainer: job.Annotations[AnnotationTargetContainer],
+		Language:        job.Annotations[AnnotationLanguage],
+		Tool:            job.Annotations[AnnotationTool],
+		Node:            job.Spec.Template.Spec.NodeName,
+		CreationTime:    job.CreationTimestamp.Time,
+		Phase:           phaseOf(job),
+	}
+}
+
+// phaseOf returns the phase of the profiling job from its status.
+func phaseOf(job *batchv1.Job) Phase {
+	switch {
+	case job.Status.Succeeded > 0:
+		return SucceededPhase
+	case job.Status.Failed > 0 && job.Status.Active == 0:
+		return FailedPhase
+	case job.Status.Active > 0:
+		return RunningPhase
+	default:
+		return PendingPhase
+	}
+}
+
+// annotations returns the annotations of the profiling job, which disable the injection of service mesh sidecars
+// and record the profiling target, the language and the profiling tool.
+// They are annotations rather than labels because their values (e.g. clang++) are not valid label values.
+func annotations(targetPod *apiv1.Pod, cfg *config.ProfilerConfig) map[string]string {
+	target := string(config.Pod) + "/" + targetPod.Name
+	if cfg.Target.Kind == config.Node {
+		target = string(config.Node) + "/" + cfg.Target.NodeName
+	}
+	return map[string]string{
+		"sidecar.istio.io/inject": "false",
+		"linkerd.io/inject":       "disabled",
+		AnnotationTarget:          target,
+		AnnotationTargetNamespace: cfg.Target.Namespace,
+		AnnotationTargetContainer: cfg.Target.ContainerName,
+		AnnotationLanguage:        string(cfg.Target.Language),
+		AnnotationTool:            string(cfg.Target.ProfilingTool),
+	}
+}
@@ -0,0 +1,158 @@
+package job
+
+import (
+	"testing"
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInfoOf(t *testing.T) {
+	creationTime := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	targetPod := &apiv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "PodName"}}
+	cfg := &config.ProfilerConfig{
+		Target: &config.TargetConfig{
+			Namespace:     "TargetNamespace",
+			ContainerName: "ContainerName",
+			Language:      api.ClangPlusPlus,
+			ProfilingTool: api.Perf,
+		},
+	}
+	profilingJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "kubectl-prof-perf-ID",
+			Namespace:         "Namespace",
+			Labels:            map[string]string{LabelID: "ID"},
+			Annotations:       annotations(targetPod, cfg),
+			CreationTimestamp: metav1.NewTime(creationTime),
+		},
+		Spec: batchv1.JobSpec{
+			Template: apiv1.PodTemplateSpec{
+				Spec: apiv1.PodSpec{NodeName: "NodeName"},
+			},
+		},
+		Status: batchv1.JobStatus{Active: 1},
+	}
+
+	info := InfoOf(profilingJob)
+
+	assert.Equal(t, Info{
+		ID:              "ID",
+		Name:            "kubectl-prof-perf-ID",
+		Namespace:       "Namespace",
+		Target:          "pod/PodName",
+		TargetNamespace: "TargetNamespace",
+		TargetContainer: "ContainerName",
+		Language:        "clang++",
+		Tool:            "perf",
+		Node:            "NodeName",
+		CreationTime:    creationTime,
+		Phase:           RunningPhase,
+	}, info)
+	assert.False(t, info.IsFinished())
+	assert.Equal(t, 90*time.Minute, info.Age(creationTime.Add(90*time.Minute)))
+	namespace, podName := info.TargetPod()
+	assert.Equal(t, "TargetNamespace", namespace)
+	assert.Equal(t, "PodName", podName)
+}
+
+func TestInfo_TargetPod(t *testing.T) {
+	tests := []struct {
+		name          string
+		info          Info
+		wantNamespace string
+		wantPod       string
+	}{
+		{
+			name:          "pod target",
+			info:          Info{Target: "pod/PodName", TargetNamespace: "Namespace"},
+			wantNamespace: "Namespace",
+			wantPod:       "PodName",
+		},
+		{
+			name: "node target",
+			info: Info{Target: "node/worker-1"},
+		},
+		{
+			name: "unknown target",
+			info: Info{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			namespace, podName := tt.info.TargetPod()
+
+			assert.Equal(t, tt.wantNamespace, namespace)
+			assert.Equal(t, tt.wantPod, podName)
+		})
+	}
+}
+
+func TestTargetName(t *testing.T) {
+	assert.Equal(t, "PodName", TargetName(map[string]string{AnnotationTarget: "pod/PodName"}))
+	assert.Equal(t, "worker-1", TargetName(map[string]string{AnnotationTarget: "node/worker-1"}))
+	assert.Empty(t, TargetName(map[string]string{}))
+}
+
+func Test_phaseOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		status batchv1.JobStatus
+		want   Phase
+	}{
+		{
+			name:   "pending",
+			status: batchv1.JobStatus{},
+			want:   PendingPhase,
+		},
+		{
+			name:   "running",
+			status: batchv1.JobStatus{Active: 1},
+			want:   RunningPhase,
+		},
+		{
+			name:   "running after a failed attempt",
+			status: batchv1.JobStatus{Active: 1, Failed: 1},
+			want:   RunningPhase,
+		},
+		{
+			name:   "succeeded",
+			status: batchv1.JobStatus{Succeeded: 1},
+			want:   SucceededPhase,
+		},
+		{
+			name:   "failed",
+			status: batchv1.JobStatus{Failed: 3},
+			want:   FailedPhase,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, phaseOf(&batchv1.Job{Status: tt.status}))
+		})
+	}
+}
+
+func Test_annotations(t *testing.T) {
+	cfg := &config.ProfilerConfig{
+		Target: &config.TargetConfig{
+			Kind:          config.Node,
+			NodeName:      "worker-1",
+			Language:      api.Go,
+			ProfilingTool: api.Bpf,
+		},
+	}
+
+	result := annotations(&apiv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}}, cfg)
+
+	assert.Equal(t, "node/worker-1", result[AnnotationTarget])
+	assert.Equal(t, "go", result[AnnotationLanguage])
+	assert.Equal(t, "bpf", result[AnnotationTool])
+	assert.Equal(t, "false", result["sidecar.istio.io/inject"])
+	assert.Equal(t, "disabled", result["linkerd.io/inject"])
+}
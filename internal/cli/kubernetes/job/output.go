@@ -0,0 +1,30 @@
+package job
+
+import (
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// outputVolumeName is the name of the volume holding the PersistentVolumeClaim where the agent stores the result files
+const outputVolumeName = "output"
+
+// addOutputVolume mounts the PersistentVolumeClaim given by the target configuration into the agent container of the
+// given pod spec, so that the agent stores the result files there. Nothing is done if no claim is given.
+func addOutputVolume(spec *apiv1.PodSpec, cfg *config.ProfilerConfig) {
+	claim, subPath := cfg.Target.OutputVolume()
+	if claim == "" {
+		return
+	}
+	spec.Volumes = append(spec.Volumes, apiv1.Volume{
+		Name: outputVolumeName,
+		VolumeSource: apiv1.VolumeSource{
+			PersistentVolumeClaim: &apiv1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+		},
+	})
+	spec.Containers[0].VolumeMounts = append(spec.Containers[0].VolumeMounts, apiv1.VolumeMount{
+		Name:      outputVolumeName,
+		MountPath: kubernetes.OutputDir,
+		SubPath:   subPath,
+	})
+}
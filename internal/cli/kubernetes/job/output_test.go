@@ -0,0 +1,60 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+func TestCreate_WithOutputPVC(t *testing.T) {
+	creator, err := NewCreator(api.Java, api.AsyncProfiler)
+	require.NoError(t, err)
+	cfg := &config.ProfilerConfig{
+		Target: &config.TargetConfig{
+			Language:      api.Java,
+			ProfilingTool: api.AsyncProfiler,
+			ExtraTargetOptions: config.ExtraTargetOptions{
+				OutputPVC: "results:profiling",
+			},
+		},
+		Job: &config.JobConfig{},
+	}
+
+	id, job, err := creator.Create(&apiv1.Pod{}, cfg)
+
+	require.NoError(t, err)
+	spec := job.Spec.Template.Spec
+	assert.Contains(t, spec.Volumes, apiv1.Volume{
+		Name: outputVolumeName,
+		VolumeSource: apiv1.VolumeSource{
+			PersistentVolumeClaim: &apiv1.PersistentVolumeClaimVolumeSource{ClaimName: "results"},
+		},
+	})
+	assert.Contains(t, spec.Containers[0].VolumeMounts, apiv1.VolumeMount{
+		Name:      outputVolumeName,
+		MountPath: kubernetes.OutputDir,
+		SubPath:   "profiling",
+	})
+	assert.Equal(t, kubernetes.OutputDir+"/"+id, kubernetes.ArgumentValue(spec.Containers[0].Args, "--output-dir"))
+}
+
+func TestCreate_WithoutOutputPVC(t *testing.T) {
+	creator, err := NewCreator(api.Java, api.AsyncProfiler)
+	require.NoError(t, err)
+	cfg := &config.ProfilerConfig{
+		Target: &config.TargetConfig{Language: api.Java, ProfilingTool: api.AsyncProfiler},
+		Job:    &config.JobConfig{},
+	}
+
+	_, job, err := creator.Create(&apiv1.Pod{}, cfg)
+
+	require.NoError(t, err)
+	for _, volume := range job.Spec.Template.Spec.Volumes {
+		assert.NotEqual(t, outputVolumeName, volume.Name)
+	}
+}
@@ -0,0 +1,111 @@
+package job
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// the paths of the node needed by the BPF tools
+var (
+	modulesMount = hostMount{name: "modules", path: "/lib/modules"}
+	sysMount     = hostMount{name: "sys", path: "/sys", readOnly: true}
+)
+
+// the profiles of the profiling jobs, one per agent image
+var (
+	jvmProfile = profile{
+		name:         "jvm",
+		imageVariant: "jvm",
+		alpine:       true,
+		capabilities: []apiv1.Capability{"PERFMON", "SYSLOG"},
+		hostPID:      true,
+	}
+
+	bpfProfile = profile{
+		name:         "bpf",
+		imageVariant: "bpf",
+		capabilities: []apiv1.Capability{"SYS_ADMIN"},
+		hostPID:      true,
+		hostMounts:   []hostMount{modulesMount},
+	}
+
+	btfProfile = profile{
+		name:         "btf",
+		imageVariant: "btf",
+		capabilities: []apiv1.Capability{"SYS_ADMIN", "PERFMON", "BPF"},
+		hostPID:      true,
+		hostMounts:   []hostMount{sysMount},
+	}
+
+	perfProfile = profile{
+		name:         "perf",
+		imageVariant: "perf",
+		capabilities: []apiv1.Capability{"SYS_ADMIN"},
+		hostPID:      true,
+	}
+
+	// dummyProfile is used by the tools signaling the target process, which need no capability
+	dummyProfile = profile{
+		name:         "dummy",
+		imageVariant: "dummy",
+		hostPID:      true,
+	}
+
+	// pprofProfile reaches the pprof endpoint of the target pod over the network, so it needs no access to the node
+	pprofProfile = profile{
+		name:         "pprof",
+		imageVariant: "dummy",
+		unprivileged: true,
+		podIPArg:     "--pprof-host",
+	}
+
+	rustProfile = profile{
+		name:         "rust",
+		imageVariant: "rust",
+		capabilities: []apiv1.Capability{"SYS_PTRACE", "SYS_ADMIN"},
+		hostPID:      true,
+		env: []apiv1.EnvVar{
+			{
+				Name:  "PATH",
+				Value: "/app:/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+			},
+		},
+	}
+
+	pythonProfile = profile{
+		name:         "python",
+		imageVariant: "python",
+		capabilities: []apiv1.Capability{"SYS_PTRACE"},
+		hostPID:      true,
+	}
+
+	// memrayProfile adds SYS_ADMIN to the capabilities of pythonProfile.
+	// memray uses nsenter to enter target container namespaces (--net, --mount, --pid), which
+	// requires CAP_SYS_ADMIN in addition to SYS_PTRACE for the ptrace-based attach.
+	memrayProfile = profile{
+		name:         "python",
+		imageVariant: "python",
+		capabilities: []apiv1.Capability{"SYS_PTRACE", "SYS_ADMIN"},
+		hostPID:      true,
+	}
+
+	rubyProfile = profile{
+		name:         "ruby",
+		imageVariant: "ruby",
+		capabilities: []apiv1.Capability{"SYS_PTRACE"},
+		hostPID:      true,
+	}
+
+	phpProfile = profile{
+		name:         "php",
+		imageVariant: "php",
+		capabilities: []apiv1.Capability{"SYS_PTRACE"},
+		hostPID:      true,
+	}
+
+	dotnetProfile = profile{
+		name:         "dotnet",
+		imageVariant: "dotnet",
+		capabilities: []apiv1.Capability{"SYS_PTRACE"},
+		hostPID:      true,
+	}
+)
@@ -0,0 +1,129 @@
+package job
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// leastPrivileges are the privileges needed by a profiling tool when the agent container is not privileged
+type leastPrivileges struct {
+	capabilities []apiv1.Capability
+	// hostPID tells whether the tool reaches the target process through the process namespace of the node, along
+	// with the filesystem of the container runtime
+	hostPID bool
+	// hostMounts are the other paths of the node needed by the tool
+	hostMounts []hostMount
+}
+
+// minimalPrivileges are the least privileges needed by each profiling tool
+var minimalPrivileges = map[api.ProfilingTool]leastPrivileges{
+	api.AsyncProfiler: {capabilities: []apiv1.Capability{"PERFMON", "SYSLOG", "SYS_PTRACE"}, hostPID: true},
+	api.Jcmd:          {capabilities: []apiv1.Capability{"SYS_PTRACE"}, hostPID: true},
+	api.Pyspy:         {capabilities: []apiv1.Capability{"SYS_PTRACE"}, hostPID: true},
+	// memray enters the namespaces of the target container with nsenter
+	api.Memray:         {capabilities: []apiv1.Capability{"SYS_PTRACE", "SYS_ADMIN"}, hostPID: true},
+	api.Rbspy:          {capabilities: []apiv1.Capability{"SYS_PTRACE"}, hostPID: true},
+	api.Phpspy:         {capabilities: []apiv1.Capability{"SYS_PTRACE"}, hostPID: true},
+	api.DotnetTrace:    {capabilities: []apiv1.Capability{"SYS_PTRACE"}, hostPID: true},
+	api.DotnetGcdump:   {capabilities: []apiv1.Capability{"SYS_PTRACE"}, hostPID: true},
+	api.DotnetCounters: {capabilities: []apiv1.Capability{"SYS_PTRACE"}, hostPID: true},
+	api.DotnetDump:     {capabilities: []apiv1.Capability{"SYS_PTRACE"}, hostPID: true},
+	// BCC compiles and loads its programs at runtime, which BPF and PERFMON are not enough for
+	api.Bpf:        {capabilities: []apiv1.Capability{"SYS_ADMIN"}, hostPID: true, hostMounts: []hostMount{modulesMount}},
+	api.Btf:        {capabilities: []apiv1.Capability{"BPF", "PERFMON"}, hostPID: true, hostMounts: []hostMount{sysMount}},
+	api.Perf:       {capabilities: []apiv1.Capability{"PERFMON", "SYSLOG"}, hostPID: true},
+	api.CargoFlame: {capabilities: []apiv1.Capability{"PERFMON", "SYSLOG"}, hostPID: true},
+	// node-dummy signals the target process, which runs as another user
+	api.NodeDummy: {capabilities: []apiv1.Capability{"KILL"}, hostPID: true},
+	// pprof reaches the target pod over the network
+	api.GoPprof:  {},
+	api.FakeTool: {},
+}
+
+// leastPrivilegesOf returns the least privileges needed by the given profiling tool, with the configured capabilities
+// if any
+func leastPrivilegesOf(tool api.ProfilingTool, configured []apiv1.Capability) (leastPrivileges, error) {
+	privileges, ok := minimalPrivileges[tool]
+	if !ok {
+		return leastPrivileges{}, errors.Errorf("no %s security profile for profiling tool %s", config.MinimalSecurityProfile, tool)
+	}
+	if len(configured) > 0 {
+		privileges.capabilities = configured
+	}
+	return privileges, nil
+}
+
+// DescribeMinimalPrivileges returns what the agent is granted to profile with the given target configuration under
+// the minimal security profile, with the given capabilities or else the least ones needed by the profiling tool.
+// Whether the namespace of the agent admits it is left to the pre-flight checks.
+func DescribeMinimalPrivileges(target *config.TargetConfig, capabilities []apiv1.Capability, launchMode config.LaunchMode) (string, error) {
+	cfg := &config.ProfilerConfig{
+		Target: target,
+		Job: &config.JobConfig{ContainerConfig: config.ContainerConfig{
+			Capabilities:    capabilities,
+			SecurityProfile: config.MinimalSecurityProfile,
+		}},
+		LaunchMode: launchMode,
+	}
+	spec, err := minimalPodSpec(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	granted := []string{"unprivileged container"}
+	var added []apiv1.Capability
+	if len(spec.Containers) > 0 && spec.Containers[0].SecurityContext.Capabilities != nil {
+		added = spec.Containers[0].SecurityContext.Capabilities.Add
+	}
+	if len(added) > 0 {
+		granted = append(granted, fmt.Sprintf("capabilities %v", added))
+	} else {
+		granted = append(granted, "no capability")
+	}
+	if spec.HostPID {
+		granted = append(granted, "host PID namespace")
+	}
+	var paths []string
+	for _, v := range spec.Volumes {
+		if v.HostPath != nil {
+			paths = append(paths, v.HostPath.Path)
+		}
+	}
+	if len(paths) > 0 {
+		granted = append(granted, fmt.Sprintf("host paths %v", paths))
+	}
+	return strings.Join(granted, ", "), nil
+}
+
+// minimalPodSpec returns the pod spec of the agent under the minimal security profile, which only holds the agent
+// container with the ephemeral launch mode
+func minimalPodSpec(cfg *config.ProfilerConfig) (*apiv1.PodSpec, error) {
+	if _, err := leastPrivilegesOf(cfg.Target.ProfilingTool, cfg.Job.Capabilities); err != nil {
+		return nil, err
+	}
+	if cfg.LaunchMode == config.EphemeralLaunchMode {
+		_, container, err := NewEphemeralContainer(&apiv1.Pod{}, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &apiv1.PodSpec{Containers: []apiv1.Container{{
+			Name:            container.Name,
+			SecurityContext: container.SecurityContext,
+		}}}, nil
+	}
+
+	creator, err := NewCreator(cfg.Target.Language, cfg.Target.ProfilingTool)
+	if err != nil {
+		return nil, err
+	}
+	_, job, err := creator.Create(&apiv1.Pod{}, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &job.Spec.Template.Spec, nil
+}
@@ -0,0 +1,90 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+func TestDescribeMinimalPrivileges(t *testing.T) {
+	tests := []struct {
+		name         string
+		target       *config.TargetConfig
+		capabilities []apiv1.Capability
+		launchMode   config.LaunchMode
+		want         string
+		wantErr      string
+	}{
+		{
+			name:   "pprof",
+			target: &config.TargetConfig{Language: api.Go, ProfilingTool: api.GoPprof},
+			want:   "unprivileged container, no capability",
+		},
+		{
+			name:       "node-dummy sharing the process namespace of the target container",
+			target:     &config.TargetConfig{Language: api.Node, ProfilingTool: api.NodeDummy},
+			launchMode: config.EphemeralLaunchMode,
+			want:       "unprivileged container, capabilities [KILL]",
+		},
+		{
+			name:         "configured capabilities",
+			target:       &config.TargetConfig{Language: api.Python, ProfilingTool: api.Pyspy},
+			capabilities: []apiv1.Capability{"SETUID"},
+			launchMode:   config.EphemeralLaunchMode,
+			want:         "unprivileged container, capabilities [SETUID]",
+		},
+		{
+			name: "btf",
+			target: &config.TargetConfig{
+				Language:             api.Go,
+				ProfilingTool:        api.Btf,
+				ContainerRuntimePath: "/run/containerd",
+			},
+			want: "unprivileged container, capabilities [BPF PERFMON], host PID namespace, host paths [/run/containerd /sys]",
+		},
+		{
+			name:       "pyspy sharing the process namespace of the target container",
+			target:     &config.TargetConfig{Language: api.Python, ProfilingTool: api.Pyspy},
+			launchMode: config.EphemeralLaunchMode,
+			want:       "unprivileged container, capabilities [SYS_PTRACE]",
+		},
+		{
+			name: "node-dummy",
+			target: &config.TargetConfig{
+				Language:             api.Node,
+				ProfilingTool:        api.NodeDummy,
+				ContainerRuntimePath: "/run/containerd",
+			},
+			want: "unprivileged container, capabilities [KILL], host PID namespace, host paths [/run/containerd]",
+		},
+		{
+			name:    "tool without minimal security profile",
+			target:  &config.TargetConfig{Language: api.Go, ProfilingTool: "other"},
+			wantErr: "no minimal security profile for profiling tool other",
+		},
+		{
+			name:    "language without job creator",
+			target:  &config.TargetConfig{Language: "other", ProfilingTool: api.Pyspy},
+			wantErr: "got language without job creator",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			got, err := DescribeMinimalPrivileges(tt.target, tt.capabilities, tt.launchMode)
+
+			// Then
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
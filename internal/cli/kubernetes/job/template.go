@@ -0,0 +1,65 @@
+package job
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	jsonpatch "gopkg.in/evanphx/json-patch.v4"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// ApplyTemplate returns the given profiling job patched by the given template, which is either a strategic merge patch
+// or a JSON patch. The template cannot change what the CLI relies on to follow the profiling: the ID label of the job
+// and its pod, and the agent container.
+// The job is returned unchanged if there is no template.
+func ApplyTemplate(job *batchv1.Job, template []byte) (*batchv1.Job, error) {
+	if len(template) == 0 {
+		return job, nil
+	}
+
+	original, err := json.Marshal(job)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encode job")
+	}
+
+	var patched []byte
+	if bytes.HasPrefix(template, []byte("[")) {
+		patch, err := jsonpatch.DecodePatch(template)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not decode JSON patch")
+		}
+		patched, err = patch.Apply(original)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not apply JSON patch")
+		}
+	} else {
+		patched, err = strategicpatch.StrategicMergePatch(original, template, batchv1.Job{})
+		if err != nil {
+			return nil, errors.Wrap(err, "could not apply strategic merge patch")
+		}
+	}
+
+	result := &batchv1.Job{}
+	if err := json.Unmarshal(patched, result); err != nil {
+		return nil, errors.Wrap(err, "could not decode patched job")
+	}
+	if err := checkTemplated(job, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkTemplated checks that the patched job can still be followed as the original one
+func checkTemplated(original, patched *batchv1.Job) error {
+	id := original.Labels[LabelID]
+	if patched.Labels[LabelID] != id || patched.Spec.Template.Labels[LabelID] != id {
+		return errors.Errorf("job template cannot change the label %s", LabelID)
+	}
+	containers := patched.Spec.Template.Spec.Containers
+	if len(containers) == 0 || containers[0].Name != ContainerName {
+		return errors.Errorf("job template cannot remove nor reorder the container %s", ContainerName)
+	}
+	return nil
+}
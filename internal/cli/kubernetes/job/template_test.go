@@ -0,0 +1,95 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+func TestApplyTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		then     func(t *testing.T, original, patched *batchv1.Job, err error)
+	}{
+		{
+			name: "should return the job unchanged without template",
+			then: func(t *testing.T, original, patched *batchv1.Job, err error) {
+				require.NoError(t, err)
+				assert.Same(t, original, patched)
+			},
+		},
+		{
+			name: "should apply the strategic merge patch",
+			template: `{"metadata":{"labels":{"team":"perf"}},"spec":{"template":{"spec":{"priorityClassName":"profiling",` +
+				`"containers":[{"name":"kubectl-prof","env":[{"name":"EXTRA","value":"true"}]}]}}}}`,
+			then: func(t *testing.T, original, patched *batchv1.Job, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, "perf", patched.Labels["team"])
+				assert.Equal(t, original.Labels[LabelID], patched.Labels[LabelID])
+				assert.Equal(t, "profiling", patched.Spec.Template.Spec.PriorityClassName)
+				container := patched.Spec.Template.Spec.Containers[0]
+				assert.Equal(t, original.Spec.Template.Spec.Containers[0].Image, container.Image)
+				assert.Contains(t, container.Env, apiv1.EnvVar{Name: "EXTRA", Value: "true"})
+				assert.Contains(t, container.Env, original.Spec.Template.Spec.Containers[0].Env[0])
+			},
+		},
+		{
+			name:     "should apply the JSON patch",
+			template: `[{"op":"add","path":"/spec/template/spec/runtimeClassName","value":"gvisor"}]`,
+			then: func(t *testing.T, original, patched *batchv1.Job, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, patched.Spec.Template.Spec.RuntimeClassName)
+				assert.Equal(t, "gvisor", *patched.Spec.Template.Spec.RuntimeClassName)
+				assert.Equal(t, original.Spec.Template.Spec.Containers, patched.Spec.Template.Spec.Containers)
+			},
+		},
+		{
+			name:     "should fail when the JSON patch cannot be applied",
+			template: `[{"op":"test","path":"/spec/template/spec/hostPID","value":false}]`,
+			then: func(t *testing.T, _, patched *batchv1.Job, err error) {
+				require.ErrorContains(t, err, "could not apply JSON patch")
+				assert.Nil(t, patched)
+			},
+		},
+		{
+			name:     "should fail when the ID label is changed",
+			template: `{"spec":{"template":{"metadata":{"labels":{"kubectl-prof/id":"other"}}}}}`,
+			then: func(t *testing.T, _, patched *batchv1.Job, err error) {
+				require.ErrorContains(t, err, "cannot change the label kubectl-prof/id")
+				assert.Nil(t, patched)
+			},
+		},
+		{
+			name:     "should fail when the agent container is removed",
+			template: `[{"op":"remove","path":"/spec/template/spec/containers/0"}]`,
+			then: func(t *testing.T, _, patched *batchv1.Job, err error) {
+				require.ErrorContains(t, err, "cannot remove nor reorder the container kubectl-prof")
+				assert.Nil(t, patched)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			creator, err := NewCreator(api.Java, api.AsyncProfiler)
+			require.NoError(t, err)
+			_, original, err := creator.Create(&apiv1.Pod{}, &config.ProfilerConfig{
+				Target: &config.TargetConfig{ExtraTargetOptions: config.ExtraTargetOptions{UploadTo: "s3://bucket", UploadSecret: "secret"}},
+				Job:    &config.JobConfig{},
+			})
+			require.NoError(t, err)
+
+			// When
+			patched, err := ApplyTemplate(original, []byte(tt.template))
+
+			// Then
+			tt.then(t, original, patched, err)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package job
+
+import (
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/pkg/util/s3"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// uploadEnv returns the environment variables of the agent holding the configuration of the object storage
+// where the result files are uploaded, read from the Secret given by the target configuration.
+// No environment variable is returned if the result files are not uploaded.
+func uploadEnv(cfg *config.ProfilerConfig) []apiv1.EnvVar {
+	if cfg.Target.UploadTo == "" {
+		return nil
+	}
+	return []apiv1.EnvVar{
+		secretEnv(s3.EnvAccessKeyID, cfg.Target.UploadSecret, s3.SecretAccessKeyID, false),
+		secretEnv(s3.EnvSecretAccessKey, cfg.Target.UploadSecret, s3.SecretSecretAccessKey, false),
+		secretEnv(s3.EnvEndpoint, cfg.Target.UploadSecret, s3.SecretEndpoint, true),
+		secretEnv(s3.EnvRegion, cfg.Target.UploadSecret, s3.SecretRegion, true),
+		secretEnv(s3.EnvSessionToken, cfg.Target.UploadSecret, s3.SecretSessionToken, true),
+	}
+}
+
+// UploadSecretName returns the name of the Secret holding the configuration of the object storage
+// where the given agent container uploads the result files, or empty string if they are not uploaded.
+func UploadSecretName(container apiv1.Container) string {
+	for _, env := range container.Env {
+		if env.Name == s3.EnvAccessKeyID && env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+			return env.ValueFrom.SecretKeyRef.Name
+		}
+	}
+	return ""
+}
+
+// secretEnv returns the environment variable with the value of the given key of the Secret
+func secretEnv(name, secretName, key string, optional bool) apiv1.EnvVar {
+	return apiv1.EnvVar{
+		Name: name,
+		ValueFrom: &apiv1.EnvVarSource{
+			SecretKeyRef: &apiv1.SecretKeySelector{
+				LocalObjectReference: apiv1.LocalObjectReference{Name: secretName},
+				Key:                  key,
+				Optional:             &optional,
+			},
+		},
+	}
+}
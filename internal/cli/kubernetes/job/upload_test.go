@@ -0,0 +1,63 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/pkg/util/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+func Test_uploadEnv(t *testing.T) {
+	cfg := &config.ProfilerConfig{
+		Target: &config.TargetConfig{
+			ExtraTargetOptions: config.ExtraTargetOptions{
+				UploadTo:     "s3://bucket/prefix",
+				UploadSecret: "minio",
+			},
+		},
+	}
+
+	env := uploadEnv(cfg)
+
+	require.Len(t, env, 5)
+	assert.Equal(t, s3.EnvAccessKeyID, env[0].Name)
+	assert.Equal(t, "minio", env[0].ValueFrom.SecretKeyRef.Name)
+	assert.Equal(t, s3.SecretAccessKeyID, env[0].ValueFrom.SecretKeyRef.Key)
+	assert.False(t, *env[0].ValueFrom.SecretKeyRef.Optional)
+	assert.Equal(t, s3.EnvEndpoint, env[2].Name)
+	assert.True(t, *env[2].ValueFrom.SecretKeyRef.Optional)
+	assert.Equal(t, "minio", UploadSecretName(apiv1.Container{Env: env}))
+}
+
+func Test_uploadEnv_NotUploading(t *testing.T) {
+	assert.Nil(t, uploadEnv(&config.ProfilerConfig{Target: &config.TargetConfig{}}))
+	assert.Empty(t, UploadSecretName(apiv1.Container{}))
+}
+
+func TestCreate_WithUpload(t *testing.T) {
+	creator, err := NewCreator(api.Rust, api.CargoFlame)
+	require.NoError(t, err)
+	cfg := &config.ProfilerConfig{
+		Target: &config.TargetConfig{
+			Language:      api.Rust,
+			ProfilingTool: api.CargoFlame,
+			ExtraTargetOptions: config.ExtraTargetOptions{
+				UploadTo:     "s3://bucket/prefix",
+				UploadSecret: "minio",
+			},
+		},
+		Job: &config.JobConfig{},
+	}
+
+	_, job, err := creator.Create(&apiv1.Pod{}, cfg)
+
+	require.NoError(t, err)
+	env := job.Spec.Template.Spec.Containers[0].Env
+	// the PATH of the rust agent is kept
+	assert.Equal(t, "PATH", env[0].Name)
+	assert.Equal(t, "minio", UploadSecretName(job.Spec.Template.Spec.Containers[0]))
+}
@@ -0,0 +1,100 @@
+package kubernetes
+
+import (
+	"fmt"
+	"slices"
+
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// PodSecurityEnforceLabel is the label of a namespace giving the Pod Security Standards level enforced by the
+// Pod Security Admission.
+const PodSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+
+// the Pod Security Standards levels
+const (
+	PrivilegedPodSecurity = "privileged"
+	BaselinePodSecurity   = "baseline"
+	RestrictedPodSecurity = "restricted"
+)
+
+// baselineCapabilities are the capabilities allowed to be added by the baseline level
+var baselineCapabilities = []apiv1.Capability{"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL",
+	"MKNOD", "NET_BIND_SERVICE", "SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT"}
+
+// PodSecurityViolations returns why the given pod spec is rejected by the given Pod Security Standards level.
+// Only the controls the profiling agent may break are checked. An unknown level is considered privileged.
+func PodSecurityViolations(level string, spec *apiv1.PodSpec) []string {
+	if level != BaselinePodSecurity && level != RestrictedPodSecurity {
+		return nil
+	}
+
+	var violations []string
+	if spec.HostPID {
+		violations = append(violations, "host PID namespace")
+	}
+	if spec.HostNetwork {
+		violations = append(violations, "host network")
+	}
+	if spec.HostIPC {
+		violations = append(violations, "host IPC namespace")
+	}
+	for _, volume := range spec.Volumes {
+		if volume.HostPath != nil {
+			violations = append(violations, fmt.Sprintf("hostPath volume %s", volume.Name))
+		}
+	}
+
+	for _, container := range spec.Containers {
+		sc := container.SecurityContext
+		if sc == nil {
+			sc = &apiv1.SecurityContext{}
+		}
+		if sc.Privileged != nil && *sc.Privileged {
+			violations = append(violations, fmt.Sprintf("privileged container %s", container.Name))
+		}
+		var added []apiv1.Capability
+		if sc.Capabilities != nil {
+			added = sc.Capabilities.Add
+		}
+		allowed := baselineCapabilities
+		if level == RestrictedPodSecurity {
+			allowed = []apiv1.Capability{"NET_BIND_SERVICE"}
+		}
+		for _, capability := range added {
+			if !slices.Contains(allowed, capability) {
+				violations = append(violations, fmt.Sprintf("capability %s of container %s", capability, container.Name))
+			}
+		}
+		if level == RestrictedPodSecurity {
+			violations = append(violations, restrictedViolations(spec, container.Name, sc)...)
+		}
+	}
+	return violations
+}
+
+// restrictedViolations returns why the given container is rejected by the restricted level, on top of the baseline one
+func restrictedViolations(spec *apiv1.PodSpec, name string, sc *apiv1.SecurityContext) []string {
+	var violations []string
+	if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+		violations = append(violations, fmt.Sprintf("privilege escalation allowed for container %s", name))
+	}
+	if sc.Capabilities == nil || !slices.Contains(sc.Capabilities.Drop, "ALL") {
+		violations = append(violations, fmt.Sprintf("capabilities of container %s not dropped", name))
+	}
+	runAsNonRoot := sc.RunAsNonRoot
+	if runAsNonRoot == nil && spec.SecurityContext != nil {
+		runAsNonRoot = spec.SecurityContext.RunAsNonRoot
+	}
+	if runAsNonRoot == nil || !*runAsNonRoot {
+		violations = append(violations, fmt.Sprintf("container %s may run as root", name))
+	}
+	seccomp := sc.SeccompProfile
+	if seccomp == nil && spec.SecurityContext != nil {
+		seccomp = spec.SecurityContext.SeccompProfile
+	}
+	if seccomp == nil || seccomp.Type == apiv1.SeccompProfileTypeUnconfined {
+		violations = append(violations, fmt.Sprintf("no seccomp profile for container %s", name))
+	}
+	return violations
+}
@@ -0,0 +1,86 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestPodSecurityViolations(t *testing.T) {
+	privileged := true
+	agentSpec := &v1.PodSpec{
+		HostPID: true,
+		Volumes: []v1.Volume{
+			{Name: "target-filesystem", VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: "/run/containerd"}}},
+		},
+		Containers: []v1.Container{
+			{
+				Name: "kubectl-prof",
+				SecurityContext: &v1.SecurityContext{
+					Privileged:   &privileged,
+					Capabilities: &v1.Capabilities{Add: []v1.Capability{"SYS_PTRACE", "KILL"}},
+				},
+			},
+		},
+	}
+	killSpec := &v1.PodSpec{
+		Containers: []v1.Container{
+			{
+				Name:            "kubectl-prof",
+				SecurityContext: &v1.SecurityContext{Capabilities: &v1.Capabilities{Add: []v1.Capability{"KILL"}}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		level string
+		spec  *v1.PodSpec
+		want  []string
+	}{
+		{
+			name:  "privileged level allows everything",
+			level: PrivilegedPodSecurity,
+			spec:  agentSpec,
+		},
+		{
+			name:  "no level allows everything",
+			level: "",
+			spec:  agentSpec,
+		},
+		{
+			name:  "baseline level rejects the host access, the privileged mode and the extra capabilities",
+			level: BaselinePodSecurity,
+			spec:  agentSpec,
+			want: []string{
+				"host PID namespace",
+				"hostPath volume target-filesystem",
+				"privileged container kubectl-prof",
+				"capability SYS_PTRACE of container kubectl-prof",
+			},
+		},
+		{
+			name:  "baseline level allows the default capabilities",
+			level: BaselinePodSecurity,
+			spec:  killSpec,
+		},
+		{
+			name:  "restricted level rejects the default capabilities and the unhardened container",
+			level: RestrictedPodSecurity,
+			spec:  killSpec,
+			want: []string{
+				"capability KILL of container kubectl-prof",
+				"privilege escalation allowed for container kubectl-prof",
+				"capabilities of container kubectl-prof not dropped",
+				"container kubectl-prof may run as root",
+				"no seccomp profile for container kubectl-prof",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, PodSecurityViolations(tt.level, tt.spec))
+		})
+	}
+}
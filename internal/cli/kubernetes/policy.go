@@ -0,0 +1,41 @@
+package kubernetes
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/pkg/errors"
+)
+
+// the annotations of namespaces and pods giving their profiling policy
+const (
+	// ProfilingAnnotation disables the profiling when its value is ProfilingDisabled
+	ProfilingAnnotation = "kubectl-prof.io/profiling"
+	// AllowedToolsAnnotation gives the comma separated list of the only profiling tools allowed
+	AllowedToolsAnnotation = "kubectl-prof.io/allowed-tools"
+)
+
+// ProfilingDisabled is the value of ProfilingAnnotation disabling the profiling
+const ProfilingDisabled = "disabled"
+
+// CheckProfilingPolicy returns why the profiling policy given by the annotations of the described object
+// (e.g. pod default/my-pod) does not allow the given profiling tool, if so
+func CheckProfilingPolicy(object string, annotations map[string]string, tool api.ProfilingTool) error {
+	if strings.EqualFold(strings.TrimSpace(annotations[ProfilingAnnotation]), ProfilingDisabled) {
+		return errors.Errorf("profiling is disabled for %s by the annotation %s=%s", object, ProfilingAnnotation, ProfilingDisabled)
+	}
+
+	allowed, ok := annotations[AllowedToolsAnnotation]
+	if !ok {
+		return nil
+	}
+	tools := strings.Split(allowed, ",")
+	for i := range tools {
+		tools[i] = strings.TrimSpace(tools[i])
+	}
+	if !slices.Contains(tools, string(tool)) {
+		return errors.Errorf("profiling tool %s is not allowed for %s by the annotation %s=%s", tool, object, AllowedToolsAnnotation, allowed)
+	}
+	return nil
+}
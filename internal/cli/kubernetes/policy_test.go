@@ -0,0 +1,54 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckProfilingPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		tool        api.ProfilingTool
+		wantErr     string
+	}{
+		{
+			name: "no policy",
+			tool: api.Jcmd,
+		},
+		{
+			name:        "profiling disabled",
+			annotations: map[string]string{ProfilingAnnotation: "disabled"},
+			tool:        api.GoPprof,
+			wantErr:     "profiling is disabled for pod payments/api by the annotation kubectl-prof.io/profiling=disabled",
+		},
+		{
+			name:        "profiling enabled",
+			annotations: map[string]string{ProfilingAnnotation: "enabled"},
+			tool:        api.GoPprof,
+		},
+		{
+			name:        "allowed tool",
+			annotations: map[string]string{AllowedToolsAnnotation: "pprof, pyspy"},
+			tool:        api.Pyspy,
+		},
+		{
+			name:        "not allowed tool",
+			annotations: map[string]string{AllowedToolsAnnotation: "pprof,pyspy"},
+			tool:        api.Memray,
+			wantErr:     "profiling tool memray is not allowed for pod payments/api by the annotation kubectl-prof.io/allowed-tools=pprof,pyspy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckProfilingPolicy("pod payments/api", tt.annotations, tt.tool)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
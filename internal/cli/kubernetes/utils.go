@@ -1,6 +1,9 @@
 package kubernetes
 
 import (
+	"maps"
+	"path"
+	"slices"
 	"strconv"
 
 	"github.com/agrison/go-commons-lang/stringUtils"
@@ -10,6 +13,12 @@ import (
 	apiv1 "k8s.io/api/core/v1"
 )
 
+// DefaultContainerAnnotation is the annotation used by kubectl to select the default container of a pod.
+const DefaultContainerAnnotation = "kubectl.kubernetes.io/default-container"
+
+// OutputDir is the directory of the agent container where the PersistentVolumeClaim given by --output-pvc is mounted.
+const OutputDir = "/kubectl-prof/output"
+
 func ToContainerId(containerName string, pod *apiv1.Pod) (string, error) {
 	for _, containerStatus := range pod.Status.ContainerStatuses {
 		if containerStatus.Name == containerName {
@@ -20,6 +29,48 @@ func ToContainerId(containerName string, pod *apiv1.Pod) (string, error) {
 	return "", errors.New("Could not find container id for " + containerName)
 }
 
+// DefaultContainerName returns the container to be profiled according to the given pod template.
+// The container named by the kubectl.kubernetes.io/default-container annotation is preferred; otherwise
+// the only container of the template is returned. If the container cannot be determined, empty string is returned.
+func DefaultContainerName(template *apiv1.PodTemplateSpec) string {
+	if template == nil {
+		return ""
+	}
+
+	if name, ok := template.Annotations[DefaultContainerAnnotation]; ok {
+		for _, container := range template.Spec.Containers {
+			if container.Name == name {
+				return name
+			}
+		}
+	}
+
+	if len(template.Spec.Containers) == 1 {
+		return template.Spec.Containers[0].Name
+	}
+
+	return ""
+}
+
+// ToNodeContainers returns the running containers of the given pods, mapping each container ID
+// to its <namespace>/<pod>/<container> legend.
+func ToNodeContainers(pods []apiv1.Pod) map[string]string {
+	containers := make(map[string]string)
+	for _, pod := range pods {
+		if pod.Status.Phase != apiv1.PodRunning {
+			continue
+		}
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			if stringUtils.IsBlank(containerStatus.ContainerID) {
+				continue
+			}
+			containers[containerStatus.ContainerID] = pod.Namespace + "/" + pod.Name + "/" + containerStatus.Name
+		}
+	}
+
+	return containers
+}
+
 // Arguments generates and returns a slice of arguments for the specified target pod, profiler configuration, and job ID.
 func Arguments(targetPod *apiv1.Pod, cfg *config.ProfilerConfig, id string) []string {
 	args := []string{
@@ -57,10 +108,32 @@ func Arguments(targetPod *apiv1.Pod, cfg *config.ProfilerConfig, id string) []st
 	args = appendArgument(args, "--pprof-port", cfg.Target.PprofPort, func() bool {
 		return stringUtils.IsNotBlank(cfg.Target.PprofPort) && cfg.Target.ProfilingTool == api.GoPprof
 	})
+	// an ephemeral agent shares the network namespace of the target pod, whose applications may use the port and
+	// reach the result files over the loopback interface
+	args = appendArgument(args, "--file-server-port", strconv.Itoa(cfg.Target.FileServerPort), func() bool {
+		return cfg.Target.FileServerPort > 0 && cfg.LaunchMode != config.EphemeralLaunchMode
+	})
+	args = appendArgument(args, "--upload-to", cfg.Target.UploadTo, func() bool { return stringUtils.IsNotBlank(cfg.Target.UploadTo) })
+	// the results of every session and target are stored apart in the claim
+	args = appendArgument(args, "--output-dir", path.Join(OutputDir, id, targetPod.Name), func() bool {
+		return stringUtils.IsNotBlank(cfg.Target.OutputPVC)
+	})
+	args = appendArgument(args, "--target-node-wide", "", func() bool { return cfg.Target.Kind == config.Node })
+	args = appendNodeContainers(args, cfg.Target.NodeContainers, func() bool { return cfg.Target.Kind == config.Node })
 
 	return args
 }
 
+// ArgumentValue returns the value of the given argument (e.g. --compressor-type) from the arguments of an agent container.
+// If the argument is not found, empty string is returned.
+func ArgumentValue(args []string, name string) string {
+	i := slices.Index(args, name)
+	if i < 0 || i+1 >= len(args) {
+		return ""
+	}
+	return args[i+1]
+}
+
 // appendAsyncProfilerArgs conditionally appends async-profiler arguments to the provided args slice if the condition is true.
 func appendAsyncProfilerArgs(args []string, asyncProfilerArgs []string, condition func() bool) []string {
 	if condition() {
@@ -71,6 +144,17 @@ func appendAsyncProfilerArgs(args []string, asyncProfilerArgs []string, conditio
 	return args
 }
 
+// appendNodeContainers conditionally appends the containers of the node, sorted by container ID, to the provided args slice
+// if the condition is true.
+func appendNodeContainers(args []string, nodeContainers map[string]string, condition func() bool) []string {
+	if condition() {
+		for _, id := range slices.Sorted(maps.Keys(nodeContainers)) {
+			args = append(args, "--target-node-container", id+"="+nodeContainers[id])
+		}
+	}
+	return args
+}
+
 // appendArgument conditionally appends a key-value pair to the args slice based on a provided condition function.
 func appendArgument(args []string, key string, value string, condition func() bool) []string {
 	if condition() {
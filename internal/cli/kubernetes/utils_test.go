@@ -96,6 +96,84 @@ func TestToContainerId(t *testing.T) {
 	}
 }
 
+func TestDefaultContainerName(t *testing.T) {
+	tests := []struct {
+		name     string
+		template *v1.PodTemplateSpec
+		want     string
+	}{
+		{
+			name: "should return empty when no template",
+		},
+		{
+			name: "should return the only container",
+			template: &v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: "app"}},
+				},
+			},
+			want: "app",
+		},
+		{
+			name: "should return the annotated default container",
+			template: &v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{DefaultContainerAnnotation: "app"},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: "istio-proxy"}, {Name: "app"}},
+				},
+			},
+			want: "app",
+		},
+		{
+			name: "should ignore annotation pointing to unknown container",
+			template: &v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{DefaultContainerAnnotation: "unknown"},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: "istio-proxy"}, {Name: "app"}},
+				},
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DefaultContainerName(tt.template))
+		})
+	}
+}
+
+func TestToNodeContainers(t *testing.T) {
+	pods := []v1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-a", Namespace: "team-a"},
+			Status: v1.PodStatus{
+				Phase: v1.PodRunning,
+				ContainerStatuses: []v1.ContainerStatus{
+					{Name: "app", ContainerID: "containerd://aaa"},
+					{Name: "sidecar", ContainerID: "containerd://bbb"},
+					{Name: "starting"},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-b", Namespace: "team-b"},
+			Status: v1.PodStatus{
+				Phase:             v1.PodSucceeded,
+				ContainerStatuses: []v1.ContainerStatus{{Name: "app", ContainerID: "containerd://ccc"}},
+			},
+		},
+	}
+
+	assert.Equal(t, map[string]string{
+		"containerd://aaa": "team-a/pod-a/app",
+		"containerd://bbb": "team-a/pod-a/sidecar",
+	}, ToNodeContainers(pods))
+}
+
 func TestGetArgs(t *testing.T) {
 	type args struct {
 		targetPod *v1.Pod
@@ -349,6 +427,62 @@ func TestGetArgs(t *testing.T) {
 				"--async-profiler-arg", "--alloc=2m",
 			},
 		},
+		{
+			name: "With node-wide arguments",
+			args: args{
+				targetPod: &v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "worker-1",
+					},
+					Spec: v1.PodSpec{
+						NodeName: "worker-1",
+					},
+				},
+				cfg: &config.ProfilerConfig{
+					Target: &config.TargetConfig{
+						Kind:     config.Node,
+						NodeName: "worker-1",
+						NodeContainers: map[string]string{
+							"containerd://bbb": "team-b/pod-b/app",
+							"containerd://aaa": "team-a/pod-a/app",
+						},
+						Event:                api.Cpu,
+						Duration:             60 * time.Second,
+						ContainerRuntime:     api.Containerd,
+						ContainerRuntimePath: "/run/containerd",
+						Language:             api.Clang,
+						Compressor:           compressor.Gzip,
+						ProfilingTool:        api.Perf,
+						OutputType:           api.FlameGraph,
+						ExtraTargetOptions: config.ExtraTargetOptions{
+							GracePeriodEnding: 5 * time.Minute,
+							FileServerPort:    8095,
+							OutputPVC:         "results",
+						},
+					},
+				},
+				id: "ID",
+			},
+			want: []string{
+				"--target-container-runtime", "containerd",
+				"--target-container-runtime-path", "/run/containerd",
+				"--target-pod-uid", "",
+				"--target-container-id", "",
+				"--lang", "clang",
+				"--event-type", "cpu",
+				"--compressor-type", "gzip",
+				"--profiling-tool", "perf",
+				"--output-type", "flamegraph",
+				"--grace-period-ending", "5m0s",
+				"--job-id", "ID",
+				"--duration", "1m0s",
+				"--file-server-port", "8095",
+				"--output-dir", "/kubectl-prof/output/ID/worker-1",
+				"--target-node-wide",
+				"--target-node-container", "containerd://aaa=team-a/pod-a/app",
+				"--target-node-container", "containerd://bbb=team-b/pod-b/app",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -357,6 +491,32 @@ func TestGetArgs(t *testing.T) {
 	}
 }
 
+func TestArguments_withEphemeralLaunchMode(t *testing.T) {
+	cfg := &config.ProfilerConfig{
+		Target: &config.TargetConfig{
+			ContainerRuntime: api.SharedProcessNamespace,
+			Language:         api.Java,
+			ExtraTargetOptions: config.ExtraTargetOptions{
+				FileServerPort: 8095,
+			},
+		},
+		LaunchMode: config.EphemeralLaunchMode,
+	}
+
+	args := Arguments(&v1.Pod{}, cfg, "ID")
+
+	assert.NotContains(t, args, "--file-server-port")
+}
+
+func TestArgumentValue(t *testing.T) {
+	args := []string{"--target-container-runtime", "containerd", "--compressor-type", "gzip", "--print-logs"}
+
+	assert.Equal(t, "gzip", ArgumentValue(args, "--compressor-type"))
+	assert.Equal(t, "containerd", ArgumentValue(args, "--target-container-runtime"))
+	assert.Empty(t, ArgumentValue(args, "--print-logs"))
+	assert.Empty(t, ArgumentValue(args, "--lang"))
+}
+
 func Test_appendAsyncProfilerArgs(t *testing.T) {
 	type args struct {
 		args              []string
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/api"
+)
+
+// OutputFormat is the format of what the CLI reports
+type OutputFormat string
+
+const (
+	TextOutput      OutputFormat = "text"  // TextOutput reports human-readable messages.
+	JSONOutput      OutputFormat = "json"  // JSONOutput reports a single JSON document with every record once finished.
+	JSONLinesOutput OutputFormat = "jsonl" // JSONLinesOutput reports a JSON record per line as soon as it happens.
+)
+
+// AvailableOutputFormats returns the supported output formats
+func AvailableOutputFormats() []OutputFormat {
+	return []OutputFormat{TextOutput, JSONOutput, JSONLinesOutput}
+}
+
+// IsSupportedOutputFormat returns whether the given output format is supported
+func IsSupportedOutputFormat(format string) bool {
+	return slices.Contains(AvailableOutputFormats(), OutputFormat(format))
+}
+
+// Stage is the stage of the profiling reported by a record
+type Stage string
+
+const (
+	PodVerified               Stage = "pod-verified"                // PodVerified indicates the target pod was verified.
+	PodIgnored                Stage = "pod-ignored"                 // PodIgnored indicates a target pod is ignored because it is not running.
+	NodeVerified              Stage = "node-verified"               // NodeVerified indicates the target node was verified.
+	JobCreated                Stage = "job-created"                 // JobCreated indicates the profiling job was created.
+	EphemeralContainerCreated Stage = "ephemeral-container-created" // EphemeralContainerCreated indicates the agent was injected as an ephemeral container.
+	Attached                  Stage = "attached"                    // Attached indicates the CLI reattached to a profiling session.
+	Detached                  Stage = "detached"                    // Detached indicates the CLI detached from a profiling session.
+	AgentIntroduced           Stage = "agent-introduced"            // AgentIntroduced indicates the agent introduced itself.
+	Progress                  Stage = "progress"                    // Progress indicates the progress of the profiling.
+	Notice                    Stage = "notice"                      // Notice indicates a notice of the agent or a warning of the CLI.
+	AgentLog                  Stage = "agent-log"                   // AgentLog indicates a log message of the agent.
+	ResultUploaded            Stage = "result-uploaded"             // ResultUploaded indicates the agent uploaded a result file to the object storage.
+	ResultSkipped             Stage = "result-skipped"              // ResultSkipped indicates a result file was already downloaded.
+	ResultDownloaded          Stage = "result-downloaded"           // ResultDownloaded indicates a result file was downloaded.
+	Stopping                  Stage = "stopping"                    // Stopping indicates the profiling is stopped early, keeping its partial results.
+	Interrupted               Stage = "interrupted"                 // Interrupted indicates the profiling was interrupted.
+	Failed                    Stage = "error"                       // Failed indicates an error, either of a step or of the whole profiling.
+	Finished                  Stage = "finished"                    // Finished indicates the profiling succeeded.
+)
+
+// Record is a structured record of what the CLI reports
+type Record struct {
+	Time            time.Time `json:"time"`
+	Stage           Stage     `json:"stage"`
+	Target          string    `json:"target,omitempty"`
+	Session         string    `json:"session,omitempty"`
+	Message         string    `json:"message,omitempty"`
+	File            string    `json:"file,omitempty"`
+	Location        string    `json:"location,omitempty"`
+	FileSizeInBytes int64     `json:"file-size-in-bytes,omitempty"`
+	Checksum        string    `json:"checksum,omitempty"`
+	Code            string    `json:"code,omitempty"`
+	ExitCode        int       `json:"exit-code,omitempty"`
+	// the details of a failure of the agent
+	ErrorCode string `json:"error-code,omitempty"`
+	PID       string `json:"pid,omitempty"`
+	Tool      string `json:"tool,omitempty"`
+	Hint      string `json:"hint,omitempty"`
+	// the progress of the profiling
+	Progress *api.ProgressData `json:"progress,omitempty"`
+}
+
+// Summary is the document reported with the JSON output once the profiling is finished
+type Summary struct {
+	ExitCode int      `json:"exit-code"`
+	Code     string   `json:"code"`
+	Error    string   `json:"error,omitempty"`
+	Records  []Record `json:"records"`
+}
+
+// recorder reports the records according to the output format
+type recorder struct {
+	mutex   sync.Mutex
+	format  OutputFormat
+	w       io.Writer
+	records []Record
+}
+
+// output is the recorder of the CLI
+var output = &recorder{format: TextOutput, w: os.Stdout}
+
+// SetOutput sets the format of what the CLI reports and where it is written
+func SetOutput(format OutputFormat, w io.Writer) {
+	output = &recorder{format: format, w: w}
+}
+
+// Out returns where the CLI reports, either the human-readable messages or the structured records
+func Out() io.Writer {
+	return output.w
+}
+
+// IsStructuredOutput returns whether the CLI reports structured records instead of human-readable messages
+func IsStructuredOutput() bool {
+	return output.format == JSONOutput || output.format == JSONLinesOutput
+}
+
+// record reports the given record, setting its time if not given
+func (r *recorder) record(rec Record) {
+	if rec.Time.IsZero() {
+		rec.Time = time.Now()
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	switch r.format {
+	case JSONLinesOutput:
+		_ = json.NewEncoder(r.w).Encode(rec)
+	case JSONOutput:
+		r.records = append(r.records, rec)
+	}
+}
+
+// Finish reports the outcome of the profiling given by its error, and returns the exit code of the CLI
+func Finish(err error) int {
+	code := ExitCodeOf(err)
+	rec := Record{Stage: Finished, Code: code.String()}
+	if err != nil {
+		rec = Record{Stage: Failed, Message: err.Error(), Code: code.String(), ExitCode: int(code)}
+	}
+	output.record(rec)
+
+	if output.format == JSONOutput {
+		output.mutex.Lock()
+		defer output.mutex.Unlock()
+		summary := Summary{ExitCode: int(code), Code: code.String(), Records: output.records}
+		if err != nil {
+			summary.Error = err.Error()
+		}
+		encoder := json.NewEncoder(output.w)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(summary)
+	}
+	return int(code)
+}
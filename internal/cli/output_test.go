@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport_Text(t *testing.T) {
+	// Given
+	var out bytes.Buffer
+	SetOutput(TextOutput, &out)
+	defer SetOutput(TextOutput, &bytes.Buffer{})
+	printer := NewPrinterWithTargetPod(false, "my-pod")
+
+	// When
+	printer.Report("Verified target pod ... ✔\n", Record{Stage: PodVerified})
+	printer.Progress(&api.ProgressData{Iteration: 1, Iterations: 2})
+	printer.PrintError()
+
+	// Then
+	assert.Equal(t, "[my-pod] Verified target pod ... ✔\n"+
+		"[my-pod] Profiling ... 🔬 iteration 1/2, 0% (0s elapsed, 0s remaining)\n"+
+		"❌\n", out.String())
+}
+
+func TestReport_JSONLines(t *testing.T) {
+	// Given
+	var out bytes.Buffer
+	SetOutput(JSONLinesOutput, &out)
+	defer SetOutput(TextOutput, &bytes.Buffer{})
+	printer := NewPrinterWithTargetPod(false, "my-pod")
+
+	// When
+	printer.Print("not reported\n")
+	printer.Report("Verified target pod ... ✔\n", Record{Stage: PodVerified})
+	printer.Report("downloaded\n", Record{Stage: ResultDownloaded, File: "flamegraph.svg", FileSizeInBytes: 10, Checksum: "abc"})
+	code := Finish(NewError(ExitTransferFailed, errors.New("boom")))
+
+	// Then
+	assert.Equal(t, int(ExitTransferFailed), code)
+	var records []Record
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var rec Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &rec))
+		records = append(records, rec)
+	}
+	require.Len(t, records, 3)
+	assert.Equal(t, PodVerified, records[0].Stage)
+	assert.Equal(t, "my-pod", records[0].Target)
+	assert.False(t, records[0].Time.IsZero())
+	assert.Equal(t, "flamegraph.svg", records[1].File)
+	assert.Equal(t, int64(10), records[1].FileSizeInBytes)
+	assert.Equal(t, Failed, records[2].Stage)
+	assert.Equal(t, "transfer-failed", records[2].Code)
+	assert.Equal(t, int(ExitTransferFailed), records[2].ExitCode)
+}
+
+func TestReport_JSON(t *testing.T) {
+	// Given
+	var out bytes.Buffer
+	SetOutput(JSONOutput, &out)
+	defer SetOutput(TextOutput, &bytes.Buffer{})
+	printer := NewPrinter(false)
+
+	// When
+	printer.Report("Launched profiler ... 🚀\n", Record{Stage: JobCreated, Session: "id"})
+	assert.Empty(t, out.String())
+	code := Finish(nil)
+
+	// Then
+	assert.Equal(t, int(ExitOK), code)
+	var summary Summary
+	require.NoError(t, json.Unmarshal(out.Bytes(), &summary))
+	assert.Equal(t, 0, summary.ExitCode)
+	assert.Equal(t, "ok", summary.Code)
+	require.Len(t, summary.Records, 2)
+	assert.Equal(t, JobCreated, summary.Records[0].Stage)
+	assert.Equal(t, Finished, summary.Records[1].Stage)
+}
+
+func TestIsSupportedOutputFormat(t *testing.T) {
+	assert.True(t, IsSupportedOutputFormat("jsonl"))
+	assert.False(t, IsSupportedOutputFormat("yaml"))
+}
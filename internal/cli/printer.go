@@ -1,12 +1,22 @@
 package cli
 
-import "fmt"
+import (
+	"fmt"
+	"sync"
 
-// Printer defines the methods for printing messages
+	"github.com/josepdcs/kubectl-prof/api"
+)
+
+// Printer defines the methods for printing messages.
+// With a structured output, only the records given to Report and Progress are reported.
 type Printer interface {
 	Print(str string)
 	PrintSuccess()
 	PrintError()
+	// Report prints the given human-readable text, if any, or reports the given record with a structured output
+	Report(text string, record Record)
+	// Progress prints the given progress as a status line, or updates the progress table if any
+	Progress(data *api.ProgressData)
 }
 
 // NewPrinter returns new instance of Printer
@@ -24,26 +34,100 @@ func NewPrinterWithTargetPod(dryRun bool, targetPod string) Printer {
 	}
 }
 
+// NewPrinterWithProgressTable returns new instance of Printer with target pod, whose progress is given to the table
+func NewPrinterWithProgressTable(dryRun bool, targetPod string, table *ProgressTable) Printer {
+	return &dryRunPrinter{
+		dryRun:    dryRun,
+		targetPod: targetPod,
+		table:     table,
+	}
+}
+
 type dryRunPrinter struct {
 	dryRun    bool
 	targetPod string
+	table     *ProgressTable
+	// statusLine tells whether the last printed line is a status line to be rewritten
+	mutex      sync.Mutex
+	statusLine bool
 }
 
 func (p *dryRunPrinter) Print(str string) {
-	if !p.dryRun {
+	if !p.dryRun && !IsStructuredOutput() {
 		if p.targetPod != "" {
 			str = fmt.Sprintf("[%s] %s", p.targetPod, str)
 		}
-		fmt.Print(str)
+		p.endStatusLine()
+		p.write(str)
 	}
 }
 
 func (p *dryRunPrinter) PrintSuccess() {
-	if !p.dryRun {
-		fmt.Printf("✔\n")
+	if !p.dryRun && !IsStructuredOutput() {
+		p.write("✔\n")
 	}
 }
 
 func (p *dryRunPrinter) PrintError() {
-	fmt.Printf("❌\n")
+	if !IsStructuredOutput() {
+		p.endStatusLine()
+		p.write("❌\n")
+	}
+}
+
+func (p *dryRunPrinter) Report(text string, record Record) {
+	if p.dryRun {
+		return
+	}
+	if !IsStructuredOutput() {
+		if text != "" {
+			p.Print(text)
+		}
+		return
+	}
+	if record.Target == "" {
+		record.Target = p.targetPod
+	}
+	output.record(record)
+}
+
+func (p *dryRunPrinter) Progress(data *api.ProgressData) {
+	switch {
+	case p.dryRun:
+	case IsStructuredOutput():
+		p.Report("", Record{Stage: Progress, Message: FormatProgress(data), Progress: data})
+	case p.table != nil:
+		p.table.Update(p.targetPod, data)
+	case isTerminal(Out()):
+		line := "Profiling ... 🔬 " + FormatProgress(data)
+		if p.targetPod != "" {
+			line = fmt.Sprintf("[%s] %s", p.targetPod, line)
+		}
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		// the status line is rewritten in place
+		p.write("\r\033[K" + line)
+		p.statusLine = true
+	default:
+		p.Print("Profiling ... 🔬 " + FormatProgress(data) + "\n")
+	}
+}
+
+// endStatusLine ends the status line, if any, so that it is not overwritten
+func (p *dryRunPrinter) endStatusLine() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.statusLine {
+		p.write("\n")
+		p.statusLine = false
+	}
+}
+
+// write writes the given text where the CLI reports, after the progress table if any
+func (p *dryRunPrinter) write(str string) {
+	if p.table != nil {
+		p.table.Print(str)
+		return
+	}
+	_, _ = fmt.Fprint(Out(), str)
 }
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/alitto/pond"
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/internal/cli/result"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// chunkState is the state of a chunked download.
+// It is persisted next to the partial file so that an interrupted download continues where it stopped.
+type chunkState struct {
+	File            string `json:"file"`
+	FileSizeInBytes int64  `json:"file-size-in-bytes"`
+	// Chunks holds the checksum of each downloaded chunk by its index
+	Chunks map[int]string `json:"chunks"`
+}
+
+// chunkDownloader downloads the chunks of a remote file in parallel, writing each chunk at its offset
+// into a preallocated partial file
+type chunkDownloader struct {
+	fetcher       fetcher
+	remoteFile    result.File
+	target        *config.TargetConfig
+	partFileName  string
+	stateFileName string
+
+	mutex sync.Mutex
+	state chunkState
+}
+
+// newChunkDownloader returns a new chunkDownloader for the given remote file
+func newChunkDownloader(f fetcher, remoteFile result.File, targetPodName string, target *config.TargetConfig) *chunkDownloader {
+	partFileName := filepath.Join(localDir(target), "."+targetPodName+"-"+renameChunkFileName(remoteFile.FileName, remoteFile.Timestamp)+".part")
+	return &chunkDownloader{
+		fetcher:       f,
+		remoteFile:    remoteFile,
+		target:        target,
+		partFileName:  partFileName,
+		stateFileName: partFileName + ".state",
+	}
+}
+
+// Download downloads the chunks not downloaded yet into the partial file.
+// A failed chunk does not stop the others, so that only the failed chunks are downloaded again when resuming.
+func (d *chunkDownloader) Download() error {
+	var size int64
+	offsets := make([]int64, len(d.remoteFile.Chunks))
+	for i, chunk := range d.remoteFile.Chunks {
+		offsets[i] = size
+		size += chunk.FileSizeInBytes
+	}
+
+	partFile, err := os.OpenFile(d.partFileName, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return errors.Wrap(err, "could not write chunk file")
+	}
+	defer func() { _ = partFile.Close() }()
+
+	d.loadState(partFile, size)
+	if err := partFile.Truncate(size); err != nil {
+		return errors.Wrap(err, "could not allocate chunk file")
+	}
+
+	pending := make([]int, 0, len(d.remoteFile.Chunks))
+	for i, chunk := range d.remoteFile.Chunks {
+		if checksum, ok := d.state.Chunks[i]; ok && checksum == chunk.Checksum {
+			log.Debugf("Chunk file %s already downloaded, skipped", chunk.File)
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	pool := pond.New(d.target.PoolSizeRetrieveChunks, 0, pond.MinWorkers(d.target.PoolSizeRetrieveChunks))
+	defer pool.StopAndWait()
+
+	errs := make([]error, len(d.remoteFile.Chunks))
+	group := pool.Group()
+	for _, i := range pending {
+		group.Submit(func() {
+			errs[i] = d.downloadChunk(partFile, i, offsets[i])
+		})
+	}
+	group.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			log.Warnf("%d of %d chunks of file %s could not be downloaded, the download can be resumed",
+				countErrors(errs), len(errs), d.remoteFile.FileName)
+			return errs[i]
+		}
+	}
+
+	return nil
+}
+
+// downloadChunk downloads the chunk of the given index at the given offset of the partial file and records it
+func (d *chunkDownloader) downloadChunk(partFile *os.File, index int, offset int64) error {
+	chunk := d.remoteFile.Chunks[index]
+	src := source{File: chunk.File, URL: chunk.URL, Object: chunk.Object}
+	log.Debugf("Downloading chunk file %s ...", src.name())
+	err := download(d.fetcher, src, chunk.FileSizeInBytes, chunk.Checksum, d.target.RetrieveFileRetries, partFile, offset)
+	if errors.Is(err, errChecksumMismatch) {
+		return errors.Errorf("checksum does not match for chunk file %s", src.name())
+	}
+	if err != nil {
+		return err
+	}
+
+	downloaded := d.record(index, chunk.Checksum)
+	log.Infof("Chunk %d of %d of file %s downloaded (%d bytes)", downloaded, len(d.remoteFile.Chunks), d.remoteFile.FileName, chunk.FileSizeInBytes)
+	return nil
+}
+
+// loadState loads the state of a previous download of the same remote file.
+// The state is discarded if it does not belong to the remote file or the partial file does not match it.
+func (d *chunkDownloader) loadState(partFile *os.File, size int64) {
+	d.state = chunkState{
+		File:            d.remoteFile.FileName,
+		FileSizeInBytes: size,
+		Chunks:          map[int]string{},
+	}
+
+	data, err := os.ReadFile(d.stateFileName)
+	if err != nil {
+		return
+	}
+	var state chunkState
+	if err := json.Unmarshal(data, &state); err != nil {
+		log.Debugf("could not read the state of the chunk download %s: %v", d.stateFileName, err)
+		return
+	}
+	info, err := partFile.Stat()
+	if err != nil || info.Size() != size || state.File != d.state.File || state.FileSizeInBytes != size || state.Chunks == nil {
+		return
+	}
+
+	log.Debugf("Resuming the download of file %s (%d of %d chunks already downloaded)", d.remoteFile.FileName, len(state.Chunks), len(d.remoteFile.Chunks))
+	d.state = state
+}
+
+// record records the chunk of the given index as downloaded and returns the number of downloaded chunks
+func (d *chunkDownloader) record(index int, checksum string) int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.state.Chunks[index] = checksum
+	if err := d.saveState(); err != nil {
+		log.Debugf("could not save the state of the chunk download %s: %v", d.stateFileName, err)
+	}
+	return len(d.state.Chunks)
+}
+
+// saveState atomically replaces the state file with the current state
+func (d *chunkDownloader) saveState() error {
+	data, err := json.Marshal(d.state)
+	if err != nil {
+		return err
+	}
+	tmp := d.stateFileName + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, d.stateFileName)
+}
+
+// CleanUp removes the partial file and its state once they are not needed anymore
+func (d *chunkDownloader) CleanUp() {
+	removeFiles([]string{d.partFileName, d.stateFileName})
+}
+
+// countErrors returns the number of non nil errors
+func countErrors(errs []error) int {
+	var n int
+	for _, err := range errs {
+		if err != nil {
+			n++
+		}
+	}
+	return n
+}
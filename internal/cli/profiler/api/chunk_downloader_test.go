@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/internal/cli/result"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// chunkExecutor serves the content of several remote files as "tail -c +<offset> <file>" does,
+// failing always for the given files
+type chunkExecutor struct {
+	mutex    sync.Mutex
+	files    map[string]string
+	failing  map[string]bool
+	requests []string
+}
+
+func (e *chunkExecutor) Execute(_ string, _ string, _ string, command []string, stdout, _ io.Writer) error {
+	var offset int
+	var file string
+	_, _ = fmt.Sscanf(command[2], "tail -c +%d %s", &offset, &file)
+
+	e.mutex.Lock()
+	e.requests = append(e.requests, file)
+	e.mutex.Unlock()
+
+	if e.failing[file] {
+		return errors.New("connection reset")
+	}
+	_, _ = stdout.Write([]byte(e.files[file][offset-1:]))
+	return nil
+}
+
+func newChunkedRemoteFile(chunks ...string) (result.File, map[string]string) {
+	timestamp, _ := time.Parse(time.RFC3339, "2023-02-28T11:44:12.678378359Z")
+	remoteFile := result.File{
+		FileName:  "/tmp/heapdump.hprof.gz",
+		Timestamp: timestamp,
+	}
+	files := map[string]string{}
+	for i, chunk := range chunks {
+		name := fmt.Sprintf("/tmp/heapdump.hprof.gz.%02d", i)
+		files[name] = chunk
+		remoteFile.FileSizeInBytes += int64(len(chunk))
+		remoteFile.Chunks = append(remoteFile.Chunks, api.ChunkData{
+			File:            name,
+			FileSizeInBytes: int64(len(chunk)),
+			Checksum:        getMD5Hash([]byte(chunk)),
+		})
+	}
+	return remoteFile, files
+}
+
+func Test_chunkDownloader_Download(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "PodName", Namespace: "Namespace"}}
+
+	t.Run("should write the chunks at their offsets", func(t *testing.T) {
+		// Given
+		remoteFile, files := newChunkedRemoteFile("first-", "second-", "third-", "fourth")
+		exec := &chunkExecutor{files: files}
+		target := &config.TargetConfig{LocalPath: t.TempDir(), ExtraTargetOptions: config.ExtraTargetOptions{PoolSizeRetrieveChunks: 4}}
+		d := newChunkDownloader(&execFetcher{pod: pod, containerName: "ContainerName", exec: exec}, remoteFile, "my-pod", target)
+
+		// When
+		err := d.Download()
+
+		// Then
+		require.NoError(t, err)
+		content, err := os.ReadFile(d.partFileName)
+		require.NoError(t, err)
+		assert.Equal(t, "first-second-third-fourth", string(content))
+		assert.Len(t, exec.requests, 4)
+
+		d.CleanUp()
+		assert.NoFileExists(t, d.partFileName)
+		assert.NoFileExists(t, d.stateFileName)
+	})
+
+	t.Run("should download again only the failed chunks when resuming", func(t *testing.T) {
+		// Given
+		remoteFile, files := newChunkedRemoteFile("first-", "second-", "third-")
+		exec := &chunkExecutor{files: files, failing: map[string]bool{"/tmp/heapdump.hprof.gz.01": true}}
+		target := &config.TargetConfig{LocalPath: t.TempDir(), ExtraTargetOptions: config.ExtraTargetOptions{PoolSizeRetrieveChunks: 2}}
+		d := newChunkDownloader(&execFetcher{pod: pod, containerName: "ContainerName", exec: exec}, remoteFile, "my-pod", target)
+		err := d.Download()
+		require.Error(t, err)
+		data, err := os.ReadFile(d.stateFileName)
+		require.NoError(t, err)
+		var state chunkState
+		require.NoError(t, json.Unmarshal(data, &state))
+		assert.Len(t, state.Chunks, 2)
+
+		// When
+		exec = &chunkExecutor{files: files}
+		d = newChunkDownloader(&execFetcher{pod: pod, containerName: "ContainerName", exec: exec}, remoteFile, "my-pod", target)
+		err = d.Download()
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/tmp/heapdump.hprof.gz.01"}, exec.requests)
+		content, err := os.ReadFile(d.partFileName)
+		require.NoError(t, err)
+		assert.Equal(t, "first-second-third-", string(content))
+	})
+
+	t.Run("should discard the state of another remote file", func(t *testing.T) {
+		// Given
+		remoteFile, files := newChunkedRemoteFile("first-", "second-")
+		exec := &chunkExecutor{files: files}
+		target := &config.TargetConfig{LocalPath: t.TempDir(), ExtraTargetOptions: config.ExtraTargetOptions{PoolSizeRetrieveChunks: 1}}
+		d := newChunkDownloader(&execFetcher{pod: pod, containerName: "ContainerName", exec: exec}, remoteFile, "my-pod", target)
+		state, _ := json.Marshal(chunkState{File: "/tmp/other.gz", FileSizeInBytes: 13, Chunks: map[int]string{0: remoteFile.Chunks[0].Checksum}})
+		require.NoError(t, os.WriteFile(d.stateFileName, state, 0644))
+		require.NoError(t, os.WriteFile(d.partFileName, []byte("0123456789012"), 0644))
+
+		// When
+		err := d.Download()
+
+		// Then
+		require.NoError(t, err)
+		assert.Len(t, exec.requests, 2)
+		content, err := os.ReadFile(d.partFileName)
+		require.NoError(t, err)
+		assert.Equal(t, "first-second-", string(content))
+	})
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
 	"github.com/josepdcs/kubectl-prof/internal/cli/profiler/api"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -15,12 +17,20 @@ type PodApi interface {
 
 	WithReturnsError() PodApi
 	WithReturnsEmpty() PodApi
+	WithReturnsNotFound() PodApi
+	WithPodAnnotations(annotations map[string]string) PodApi
+	WithNamespaceAnnotations(annotations map[string]string) PodApi
+	WithGetNamespaceReturnsError() PodApi
 }
 
 // podApi implements PodApi for unit test purposes
 type podApi struct {
-	returnsError bool
-	returnsEmpty bool
+	returnsError             bool
+	returnsEmpty             bool
+	returnsNotFound          bool
+	podAnnotations           map[string]string
+	namespaceAnnotations     map[string]string
+	getNamespaceReturnsError bool
 }
 
 // NewPodApi returns new instance of PodApi for unit test purposes
@@ -38,16 +48,48 @@ func (p *podApi) WithReturnsEmpty() PodApi {
 	return p
 }
 
-func (p *podApi) GetPod(context.Context, string, string) (*v1.Pod, error) {
+func (p *podApi) WithReturnsNotFound() PodApi {
+	p.returnsNotFound = true
+	return p
+}
+
+func (p *podApi) WithPodAnnotations(annotations map[string]string) PodApi {
+	p.podAnnotations = annotations
+	return p
+}
+
+func (p *podApi) WithNamespaceAnnotations(annotations map[string]string) PodApi {
+	p.namespaceAnnotations = annotations
+	return p
+}
+
+func (p *podApi) WithGetNamespaceReturnsError() PodApi {
+	p.getNamespaceReturnsError = true
+	return p
+}
+
+func (p *podApi) GetNamespace(_ context.Context, name string) (*v1.Namespace, error) {
+	if p.getNamespaceReturnsError {
+		return nil, errors.New("error getting namespace")
+	}
+	return &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: p.namespaceAnnotations},
+	}, nil
+}
+
+func (p *podApi) GetPod(_ context.Context, podName string, _ string) (*v1.Pod, error) {
 	if p.returnsError {
 		return nil, errors.New("error getting pod")
 	}
+	if p.returnsNotFound {
+		return nil, apierrors.NewNotFound(v1.Resource("pods"), podName)
+	}
 	if p.returnsEmpty {
 		return nil, nil
 	}
 	return &v1.Pod{
 		TypeMeta:   metav1.TypeMeta{},
-		ObjectMeta: metav1.ObjectMeta{},
+		ObjectMeta: metav1.ObjectMeta{Annotations: p.podAnnotations},
 		Spec: v1.PodSpec{
 			Containers: []v1.Container{
 				{
@@ -76,7 +118,7 @@ func (p *podApi) GetPodsByLabelSelector(_ context.Context, _, _ string) ([]v1.Po
 	return []v1.Pod{
 		{
 			TypeMeta:   metav1.TypeMeta{},
-			ObjectMeta: metav1.ObjectMeta{},
+			ObjectMeta: metav1.ObjectMeta{Annotations: p.podAnnotations},
 			Spec: v1.PodSpec{
 				Containers: []v1.Container{
 					{
@@ -96,7 +138,7 @@ func (p *podApi) GetPodsByLabelSelector(_ context.Context, _, _ string) ([]v1.Po
 		},
 		{
 			TypeMeta:   metav1.TypeMeta{},
-			ObjectMeta: metav1.ObjectMeta{},
+			ObjectMeta: metav1.ObjectMeta{Annotations: p.podAnnotations},
 			Spec: v1.PodSpec{
 				Containers: []v1.Container{
 					{
@@ -116,3 +158,27 @@ func (p *podApi) GetPodsByLabelSelector(_ context.Context, _, _ string) ([]v1.Po
 		},
 	}, nil
 }
+
+func (p *podApi) GetPodsByWorkload(ctx context.Context, namespace string, _ config.TargetKind, _ string) ([]v1.Pod, *v1.PodTemplateSpec, error) {
+	pods, err := p.GetPodsByLabelSelector(ctx, namespace, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	return pods, &v1.PodTemplateSpec{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name: "ContainerName",
+				},
+			},
+		},
+	}, nil
+}
+
+func (p *podApi) GetPodsByService(ctx context.Context, namespace, _, _, _ string) ([]v1.Pod, error) {
+	return p.GetPodsByLabelSelector(ctx, namespace, "")
+}
+
+func (p *podApi) GetPodsByNode(ctx context.Context, _ string) ([]v1.Pod, error) {
+	return p.GetPodsByLabelSelector(ctx, "", "")
+}
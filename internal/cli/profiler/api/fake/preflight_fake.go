@@ -0,0 +1,45 @@
+package fake
+
+import (
+	"context"
+	"errors"
+
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/internal/cli/profiler/api"
+)
+
+// PreflightApi fakes api.PreflightApi for unit tests purposes
+type PreflightApi interface {
+	api.PreflightApi
+
+	WithProblems(problems ...string) PreflightApi
+	WithCheckReturnsError() PreflightApi
+}
+
+// preflightApi implements PreflightApi for unit test purposes
+type preflightApi struct {
+	problems          []string
+	checkReturnsError bool
+}
+
+// NewPreflightApi returns new instance of PreflightApi for unit test purposes
+func NewPreflightApi() PreflightApi {
+	return &preflightApi{}
+}
+
+func (p *preflightApi) WithProblems(problems ...string) PreflightApi {
+	p.problems = problems
+	return p
+}
+
+func (p *preflightApi) WithCheckReturnsError() PreflightApi {
+	p.checkReturnsError = true
+	return p
+}
+
+func (p *preflightApi) Check(context.Context, *config.ProfilerConfig) ([]string, error) {
+	if p.checkReturnsError {
+		return p.problems, errors.New("error checking")
+	}
+	return p.problems, nil
+}
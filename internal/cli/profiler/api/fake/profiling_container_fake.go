@@ -3,6 +3,7 @@ package fake
 import (
 	"context"
 	"errors"
+	"sync"
 	"time"
 
 	"github.com/josepdcs/kubectl-prof/internal/cli/config"
@@ -17,17 +18,33 @@ type ProfilingContainerApi interface {
 
 	WithHandleProfilingContainerLogsReturnsError() ProfilingContainerApi
 	WithGetRemoteFileReturnsError() ProfilingContainerApi
+	WithHandleProfilingContainerLogsNeverEnds() ProfilingContainerApi
+	WithStopProfilingReturnsError() ProfilingContainerApi
+	StopProfilingInvokedTimes() int
+	TerminateProfilingInvokedTimes() int
+	WithAcknowledgeResultReturnsError() ProfilingContainerApi
+	AcknowledgedResults() []string
 }
 
 // profilingContainerApi implements ProfilingContainerApi for unit test purposes
 type profilingContainerApi struct {
 	handleProfilingContainerLogsReturnsError bool
 	getRemoteFileReturnsError                bool
+	handleProfilingContainerLogsNeverEnds    bool
+	stopProfilingReturnsError                bool
+	stopProfilingInvokedTimes                int
+	terminateProfilingInvokedTimes           int
+	acknowledgeResultReturnsError            bool
+	// acknowledgedResults are the names of the acknowledged result files, which can be acknowledged concurrently
+	acknowledgedResults []string
+	mutex               sync.Mutex
+	// stopped is closed once the profiling is stopped early
+	stopped chan struct{}
 }
 
 // NewProfilingContainerApi returns new instance of ProfilingContainerApi for unit test purposes
 func NewProfilingContainerApi() ProfilingContainerApi {
-	return &profilingContainerApi{}
+	return &profilingContainerApi{stopped: make(chan struct{})}
 }
 
 // WithHandleProfilingContainerLogsReturnsError configures the method HandleProfilingContainerLogs for returning an error instead of expected channels
@@ -41,17 +58,69 @@ func (p *profilingContainerApi) WithGetRemoteFileReturnsError() ProfilingContain
 	return p
 }
 
+// WithHandleProfilingContainerLogsNeverEnds configures the method HandleProfilingContainerLogs for returning channels that never receive, as a profiling that is still running
+func (p *profilingContainerApi) WithHandleProfilingContainerLogsNeverEnds() ProfilingContainerApi {
+	p.handleProfilingContainerLogsNeverEnds = true
+	return p
+}
+
+// WithStopProfilingReturnsError configures the method StopProfiling for returning an error
+func (p *profilingContainerApi) WithStopProfilingReturnsError() ProfilingContainerApi {
+	p.stopProfilingReturnsError = true
+	return p
+}
+
+// StopProfilingInvokedTimes returns how many times the method StopProfiling was invoked
+func (p *profilingContainerApi) StopProfilingInvokedTimes() int {
+	return p.stopProfilingInvokedTimes
+}
+
+// TerminateProfilingInvokedTimes returns how many times the method TerminateProfiling was invoked
+func (p *profilingContainerApi) TerminateProfilingInvokedTimes() int {
+	return p.terminateProfilingInvokedTimes
+}
+
+// WithAcknowledgeResultReturnsError configures the method AcknowledgeResult for returning an error
+func (p *profilingContainerApi) WithAcknowledgeResultReturnsError() ProfilingContainerApi {
+	p.acknowledgeResultReturnsError = true
+	return p
+}
+
+// AcknowledgedResults returns the names of the result files acknowledged by the method AcknowledgeResult
+func (p *profilingContainerApi) AcknowledgedResults() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.acknowledgedResults
+}
+
 func (p *profilingContainerApi) HandleProfilingContainerLogs(*v1.Pod, string, api.EventHandler, context.Context) (chan bool, chan result.File, error) {
 	if p.handleProfilingContainerLogsReturnsError {
 		return nil, nil, errors.New("error handling profiling container logs")
 	}
-	done := make(chan bool, 1)
-	done <- true
-	resultFile := make(chan result.File, 1)
-	resultFile <- result.File{
-		FileName:  "filename",
-		Timestamp: time.Now(),
+	if p.handleProfilingContainerLogsNeverEnds {
+		// the profiling is still running, so neither the results nor the end are received unless it is stopped early
+		done := make(chan bool, 1)
+		resultFile := make(chan result.File)
+		go func() {
+			<-p.stopped
+			resultFile <- result.File{
+				FileName:  "partial-filename",
+				Timestamp: time.Now(),
+			}
+			done <- true
+		}()
+		return done, resultFile, nil
 	}
+	// the end is received once the result file is, as the events of the agent are handled in order
+	done := make(chan bool, 1)
+	resultFile := make(chan result.File)
+	go func() {
+		resultFile <- result.File{
+			FileName:  "filename",
+			Timestamp: time.Now(),
+		}
+		done <- true
+	}()
 	return done, resultFile, nil
 }
 
@@ -61,3 +130,27 @@ func (p *profilingContainerApi) GetRemoteFile(pod *v1.Pod, containerName string,
 	}
 	return "remote-file", nil
 }
+
+func (p *profilingContainerApi) StopProfiling(*v1.Pod, string) error {
+	p.stopProfilingInvokedTimes++
+	if p.stopProfilingReturnsError {
+		return errors.New("error stopping profiling")
+	}
+	close(p.stopped)
+	return nil
+}
+
+func (p *profilingContainerApi) TerminateProfiling(*v1.Pod, string) error {
+	p.terminateProfilingInvokedTimes++
+	return nil
+}
+
+func (p *profilingContainerApi) AcknowledgeResult(_ *v1.Pod, _ string, remoteFile result.File) error {
+	if p.acknowledgeResultReturnsError {
+		return errors.New("error acknowledging result")
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.acknowledgedResults = append(p.acknowledgedResults, remoteFile.FileName)
+	return nil
+}
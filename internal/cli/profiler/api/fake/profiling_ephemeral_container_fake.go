@@ -0,0 +1,77 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/internal/cli/profiler/api"
+	v1 "k8s.io/api/core/v1"
+)
+
+// ProfilingEphemeralContainerApi fakes api.ProfilingEphemeralContainerApi for unit tests purposes
+type ProfilingEphemeralContainerApi interface {
+	api.ProfilingEphemeralContainerApi
+
+	WithAddProfilingEphemeralContainerReturnsError() ProfilingEphemeralContainerApi
+	WithGetProfilingPodReturnsError() ProfilingEphemeralContainerApi
+	WithFindProfilingPodReturnsPod(pod *v1.Pod) ProfilingEphemeralContainerApi
+	WithFindProfilingPodReturnsError() ProfilingEphemeralContainerApi
+}
+
+// profilingEphemeralContainerApi implements ProfilingEphemeralContainerApi for unit test purposes
+type profilingEphemeralContainerApi struct {
+	addProfilingEphemeralContainerReturnsError bool
+	getProfilingPodReturnsError                bool
+	foundProfilingPod                          *v1.Pod
+	findProfilingPodReturnsError               bool
+}
+
+// NewProfilingEphemeralContainerApi returns new instance of ProfilingEphemeralContainerApi for unit test purposes
+func NewProfilingEphemeralContainerApi() ProfilingEphemeralContainerApi {
+	return &profilingEphemeralContainerApi{}
+}
+
+func (p *profilingEphemeralContainerApi) WithAddProfilingEphemeralContainerReturnsError() ProfilingEphemeralContainerApi {
+	p.addProfilingEphemeralContainerReturnsError = true
+	return p
+}
+
+func (p *profilingEphemeralContainerApi) WithGetProfilingPodReturnsError() ProfilingEphemeralContainerApi {
+	p.getProfilingPodReturnsError = true
+	return p
+}
+
+// WithFindProfilingPodReturnsPod configures the method FindProfilingPod for returning the given pod,
+// instead of no pod
+func (p *profilingEphemeralContainerApi) WithFindProfilingPodReturnsPod(pod *v1.Pod) ProfilingEphemeralContainerApi {
+	p.foundProfilingPod = pod
+	return p
+}
+
+func (p *profilingEphemeralContainerApi) WithFindProfilingPodReturnsError() ProfilingEphemeralContainerApi {
+	p.findProfilingPodReturnsError = true
+	return p
+}
+
+func (p *profilingEphemeralContainerApi) AddProfilingEphemeralContainer(*v1.Pod, *config.ProfilerConfig, context.Context) (string, string, error) {
+	if p.addProfilingEphemeralContainerReturnsError {
+		return "", "", errors.New("error adding profiling ephemeral container")
+	}
+	return "ID", "ProfilingContainerName", nil
+}
+
+func (p *profilingEphemeralContainerApi) GetProfilingPod(targetPod *v1.Pod, _ string, _ context.Context, _ time.Duration) (*v1.Pod, error) {
+	if p.getProfilingPodReturnsError {
+		return nil, errors.New("error getting profiling pod")
+	}
+	return targetPod, nil
+}
+
+func (p *profilingEphemeralContainerApi) FindProfilingPod(string, string, context.Context) (*v1.Pod, error) {
+	if p.findProfilingPodReturnsError {
+		return nil, errors.New("error finding profiling pod")
+	}
+	return p.foundProfilingPod, nil
+}
@@ -3,6 +3,7 @@ package fake
 import (
 	"context"
 	"errors"
+	"sync/atomic"
 	"time"
 
 	"github.com/josepdcs/kubectl-prof/internal/cli/config"
@@ -18,12 +19,18 @@ type ProfilingJobApi interface {
 
 	WithCreateProfilingJobReturnsError() ProfilingJobApi
 	WithGetProfilingPodReturnsError() ProfilingJobApi
+	WithProfilingJobs(jobs ...batchv1.Job) ProfilingJobApi
+	WithGetProfilingJobsReturnsError() ProfilingJobApi
+	DeletedJobs() int
 }
 
 // profilingJobApi implements ProfilingJobApi for unit test purposes
 type profilingJobApi struct {
 	createProfilingJobReturnsError bool
 	getProfilingPodReturnsError    bool
+	getProfilingJobsReturnsError   bool
+	profilingJobs                  []batchv1.Job
+	deletedJobs                    atomic.Int32
 }
 
 // NewProfilingJobApi returns new instance of ProfilingJobApi for unit test purposes
@@ -70,5 +77,36 @@ func (p *profilingJobApi) GetProfilingContainerName() string {
 }
 
 func (p *profilingJobApi) DeleteProfilingJob(job *batchv1.Job, ctx context.Context) error {
+	p.deletedJobs.Add(1)
 	return nil
 }
+
+// DeletedJobs returns the number of profiling jobs deleted so far
+func (p *profilingJobApi) DeletedJobs() int {
+	return int(p.deletedJobs.Load())
+}
+
+// WithProfilingJobs configures the profiling jobs returned by the method GetProfilingJobs
+func (p *profilingJobApi) WithProfilingJobs(jobs ...batchv1.Job) ProfilingJobApi {
+	p.profilingJobs = jobs
+	return p
+}
+
+// WithGetProfilingJobsReturnsError configures the method GetProfilingJobs for returning an error
+func (p *profilingJobApi) WithGetProfilingJobsReturnsError() ProfilingJobApi {
+	p.getProfilingJobsReturnsError = true
+	return p
+}
+
+func (p *profilingJobApi) GetProfilingJobs(_ context.Context, namespace string) ([]batchv1.Job, error) {
+	if p.getProfilingJobsReturnsError {
+		return nil, errors.New("error getting profiling jobs")
+	}
+	var jobs []batchv1.Job
+	for _, j := range p.profilingJobs {
+		if namespace == "" || j.Namespace == namespace {
+			jobs = append(jobs, j)
+		}
+	}
+	return jobs, nil
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	podexec "github.com/josepdcs/kubectl-prof/pkg/util/pod"
+	"github.com/josepdcs/kubectl-prof/pkg/util/s3"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+)
+
+// source locates a remote file: by its path in the agent container, by its URL path on the file server of the agent,
+// or by its location in the object storage where the agent uploaded it
+type source struct {
+	File   string
+	URL    string
+	Object string
+}
+
+// name returns the name of the remote file for logging
+func (s source) name() string {
+	if s.File != "" {
+		return s.File
+	}
+	return s.Object
+}
+
+// fetcher fetches the remote files of the agent
+type fetcher interface {
+	// Fetch writes the given remote file, starting at the given offset, to the given writer
+	Fetch(src source, offset int64, w io.Writer) error
+}
+
+// execFetcher fetches the remote files by executing tail in the agent container
+type execFetcher struct {
+	pod           *v1.Pod
+	containerName string
+	exec          podexec.Executor
+}
+
+func (e *execFetcher) Fetch(src source, offset int64, w io.Writer) error {
+	var errOut bytes.Buffer
+	// tail offsets start at 1
+	err := e.exec.Execute(e.pod.Namespace, e.pod.Name, e.containerName,
+		[]string{"sh", "-c", fmt.Sprintf("tail -c +%d %s", offset+1, src.File)}, w, &errOut)
+	if err != nil {
+		return errors.Wrapf(err, "%s", errOut.String())
+	}
+	return nil
+}
+
+// httpFetcher fetches the remote files from the file server of the agent, reached through a port-forward
+type httpFetcher struct {
+	baseURL string
+	client  *http.Client
+}
+
+// newHTTPFetcher returns a new httpFetcher for the file server listening at the given local port
+func newHTTPFetcher(localPort uint16) *httpFetcher {
+	return &httpFetcher{
+		baseURL: fmt.Sprintf("http://127.0.0.1:%d", localPort),
+		client: &http.Client{
+			Transport: &http.Transport{ResponseHeaderTimeout: 30 * time.Second},
+		},
+	}
+}
+
+func (h *httpFetcher) Fetch(src source, offset int64, w io.Writer) error {
+	if src.URL == "" {
+		return errors.New("the remote file is not served by the agent")
+	}
+	req, err := http.NewRequest(http.MethodGet, h.baseURL+src.URL, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusOK && offset == 0, resp.StatusCode == http.StatusPartialContent:
+	default:
+		return errors.Errorf("unexpected response status %s", resp.Status)
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// Acknowledge tells the file server of the agent that the given remote file was retrieved
+func (h *httpFetcher) Acknowledge(src source) error {
+	if src.URL == "" {
+		return errors.New("the remote file is not served by the agent")
+	}
+	resp, err := h.client.Post(h.baseURL+src.URL, "", nil)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return errors.Errorf("unexpected response status %s", resp.Status)
+	}
+	return nil
+}
+
+// objectStorageFetcher fetches the remote files from the object storage where the agent uploaded them
+type objectStorageFetcher struct {
+	client *s3.Client
+}
+
+func (o *objectStorageFetcher) Fetch(src source, offset int64, w io.Writer) error {
+	location, err := s3.ParseLocation(src.Object)
+	if err != nil {
+		return err
+	}
+	return o.client.Get(location, offset, w)
+}
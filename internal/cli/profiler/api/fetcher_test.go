@@ -0,0 +1,303 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes"
+	"github.com/josepdcs/kubectl-prof/internal/cli/result"
+	"github.com/josepdcs/kubectl-prof/pkg/util/compressor"
+	podexec "github.com/josepdcs/kubectl-prof/pkg/util/pod"
+	"github.com/josepdcs/kubectl-prof/pkg/util/s3"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+// newFileServer returns a server serving the given content at /files/flamegraph.svg.gz and acknowledging it,
+// as the agent does
+func newFileServer(content string) (*httptest.Server, uint16) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/files/flamegraph.svg.gz" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(content))
+	}))
+	u, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(u.Port())
+	return server, uint16(port)
+}
+
+func Test_httpFetcher_Fetch(t *testing.T) {
+	server, port := newFileServer("the content")
+	defer server.Close()
+	f := newHTTPFetcher(port)
+
+	tests := []struct {
+		name    string
+		urlPath string
+		offset  int64
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "should fetch the whole file",
+			urlPath: "/files/flamegraph.svg.gz",
+			want:    "the content",
+		},
+		{
+			name:    "should fetch the file from the given offset",
+			urlPath: "/files/flamegraph.svg.gz",
+			offset:  4,
+			want:    "content",
+		},
+		{
+			name:    "should fail when the file is not found",
+			urlPath: "/files/other.gz",
+			wantErr: "unexpected response status 404 Not Found",
+		},
+		{
+			name:    "should fail when the file is not served",
+			wantErr: "the remote file is not served by the agent",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			err := f.Fetch(source{File: "/tmp/flamegraph.svg.gz", URL: tt.urlPath}, tt.offset, &out)
+
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func Test_httpFetcher_Acknowledge(t *testing.T) {
+	server, port := newFileServer("the content")
+	defer server.Close()
+	f := newHTTPFetcher(port)
+
+	assert.NoError(t, f.Acknowledge(source{URL: "/files/flamegraph.svg.gz"}))
+	assert.EqualError(t, f.Acknowledge(source{URL: "/files/other.gz"}), "unexpected response status 404 Not Found")
+	assert.EqualError(t, f.Acknowledge(source{File: "/tmp/flamegraph.svg.gz"}), "the remote file is not served by the agent")
+}
+
+func Test_profilingContainerApi_AcknowledgeResult_ToFileServer(t *testing.T) {
+	server, port := newFileServer("the content")
+	defer server.Close()
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "PodName", Namespace: "Namespace"},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{Name: "ContainerName", Args: []string{"--lang", "go", "--file-server-port", "8095"}},
+			},
+		},
+	}
+	remoteFile := result.File{FileName: "/tmp/flamegraph.svg.gz", URL: "/files/flamegraph.svg.gz"}
+
+	tests := []struct {
+		name          string
+		portForwarder podexec.PortForwarder
+		wantCommands  [][]string
+	}{
+		{
+			name:          "should acknowledge the file through the port-forward",
+			portForwarder: podexec.NewPortForwardFake(port, nil),
+		},
+		{
+			name:          "should fall back to exec when the port-forward fails",
+			portForwarder: podexec.NewPortForwardFake(0, errors.New("port-forward failed")),
+			wantCommands: [][]string{
+				{"touch", "/tmp/flamegraph.svg.gz.ack"},
+				{"cat", "/tmp/kubectl-prof-agent.pid"},
+				{"kill", "-USR2", "4242"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			executor := &commandsExecutor{pid: "4242"}
+			p := &profilingContainerApi{executor: executor, portForwarder: tt.portForwarder}
+
+			// When
+			err := p.AcknowledgeResult(pod, "ContainerName", remoteFile)
+
+			// Then
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCommands, executor.commands)
+		})
+	}
+}
+
+func Test_profilingContainerApi_GetRemoteFile_FromFileServer(t *testing.T) {
+	content := "test"
+	server, port := newFileServer(content)
+	defer server.Close()
+	timestamp, _ := time.Parse(time.RFC3339, "2023-02-28T11:44:12.678378359Z")
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "PodName", Namespace: "Namespace"},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{Name: "ContainerName", Args: []string{"--lang", "go", "--file-server-port", "8095"}},
+			},
+		},
+	}
+	remoteFile := result.File{
+		FileName:        "/tmp/flamegraph.svg.gz",
+		URL:             "/files/flamegraph.svg.gz",
+		Checksum:        getMD5Hash([]byte(content)),
+		Timestamp:       timestamp,
+		FileSizeInBytes: int64(len(content)),
+	}
+
+	tests := []struct {
+		name          string
+		executor      podexec.Executor
+		portForwarder podexec.PortForwarder
+	}{
+		{
+			name:          "should download the file through the port-forward",
+			executor:      podexec.NewExecFake(nil, nil, errors.New("exec must not be used")),
+			portForwarder: podexec.NewPortForwardFake(port, nil),
+		},
+		{
+			name:          "should fall back to exec when the port-forward fails",
+			executor:      podexec.NewExecFake(bytes.NewBufferString(content), nil, nil),
+			portForwarder: podexec.NewPortForwardFake(0, errors.New("port-forward failed")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			p := &profilingContainerApi{executor: tt.executor, portForwarder: tt.portForwarder}
+			target := &config.TargetConfig{LocalPath: t.TempDir(), Compressor: compressor.None}
+
+			// When
+			fileName, err := p.GetRemoteFile(pod, "ContainerName", remoteFile, "pod-name", target)
+
+			// Then
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(target.LocalPath, "pod-name-flamegraph-2023-02-28T11_44_12Z.svg"), fileName)
+			assert.FileExists(t, fileName)
+		})
+	}
+}
+
+func Test_profilingContainerApi_GetRemoteFile_FromObjectStorage(t *testing.T) {
+	content := "test"
+	fake := s3.NewFakeServer()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	fake.PutObject(s3.Location{Bucket: "bucket", Key: "profiling/ID/flamegraph.svg.gz"}, []byte(content))
+	timestamp, _ := time.Parse(time.RFC3339, "2023-02-28T11:44:12.678378359Z")
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "PodName", Namespace: "Namespace"},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{Name: "ContainerName", Env: []v1.EnvVar{{
+					Name: s3.EnvAccessKeyID,
+					ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+						LocalObjectReference: v1.LocalObjectReference{Name: "minio"},
+						Key:                  s3.SecretAccessKeyID,
+					}},
+				}}},
+			},
+		},
+	}
+	remoteFile := result.File{
+		FileName:        "s3://bucket/profiling/ID/flamegraph.svg.gz",
+		Object:          "s3://bucket/profiling/ID/flamegraph.svg.gz",
+		Checksum:        getMD5Hash([]byte(content)),
+		Timestamp:       timestamp,
+		FileSizeInBytes: int64(len(content)),
+	}
+
+	tests := []struct {
+		name    string
+		secrets []v1.Secret
+		wantErr string
+	}{
+		{
+			name: "should download the file from the object storage",
+			secrets: []v1.Secret{{
+				ObjectMeta: metav1.ObjectMeta{Name: "minio", Namespace: "Namespace"},
+				Data: map[string][]byte{
+					s3.SecretEndpoint:        []byte(server.URL),
+					s3.SecretAccessKeyID:     []byte("key"),
+					s3.SecretSecretAccessKey: []byte("secret"),
+				},
+			}},
+		},
+		{
+			name:    "should fail when the secret is not found",
+			wantErr: "could not read the configuration of the object storage",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			clientSet := testclient.NewSimpleClientset()
+			for _, secret := range tt.secrets {
+				_, err := clientSet.CoreV1().Secrets(secret.Namespace).Create(t.Context(), &secret, metav1.CreateOptions{})
+				require.NoError(t, err)
+			}
+			p := &profilingContainerApi{
+				connectionInfo: kubernetes.ConnectionInfo{ClientSet: clientSet},
+				executor:       podexec.NewExecFake(nil, nil, errors.New("exec must not be used")),
+			}
+			target := &config.TargetConfig{LocalPath: t.TempDir(), Compressor: compressor.None}
+
+			// When
+			fileName, err := p.GetRemoteFile(pod, "ContainerName", remoteFile, "pod-name", target)
+
+			// Then
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(target.LocalPath, "pod-name-flamegraph-2023-02-28T11_44_12Z.svg"), fileName)
+			assert.FileExists(t, fileName)
+		})
+	}
+}
+
+func Test_fileServerPort(t *testing.T) {
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{Name: "job-agent", Args: []string{"--file-server-port", "8095"}},
+			},
+			EphemeralContainers: []v1.EphemeralContainer{
+				{EphemeralContainerCommon: v1.EphemeralContainerCommon{Name: "ephemeral-agent", Args: []string{"--file-server-port", "9000"}}},
+				{EphemeralContainerCommon: v1.EphemeralContainerCommon{Name: "old-agent", Args: []string{"--lang", "go"}}},
+			},
+		},
+	}
+
+	assert.Equal(t, 8095, fileServerPort(pod, "job-agent"))
+	assert.Equal(t, 9000, fileServerPort(pod, "ephemeral-agent"))
+	assert.Equal(t, 0, fileServerPort(pod, "old-agent"))
+}
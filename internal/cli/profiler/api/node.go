@@ -0,0 +1,27 @@
+package api
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+func (p *podApi) GetPodsByNode(ctx context.Context, nodeName string) ([]v1.Pod, error) {
+	// be sure that the node exists in order to give a meaningful error otherwise
+	_, err := p.connectionInfo.ClientSet.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	podList, err := p.connectionInfo.ClientSet.
+		CoreV1().
+		Pods(metav1.NamespaceAll).
+		List(ctx, metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String()})
+	if err != nil {
+		return nil, err
+	}
+
+	return podList.Items, nil
+}
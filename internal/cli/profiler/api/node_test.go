@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_podApi_GetPodsByNode(t *testing.T) {
+	tests := []struct {
+		name  string
+		given func() PodApi
+		then  func(t *testing.T, pods []v1.Pod, err error)
+	}{
+		{
+			name: "should get the pods of all namespaces scheduled on the node",
+			given: func() PodApi {
+				node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}}
+				podA := &v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "pod-a", Namespace: "team-a"},
+					Spec:       v1.PodSpec{NodeName: "worker-1"},
+				}
+				podB := &v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "pod-b", Namespace: "team-b"},
+					Spec:       v1.PodSpec{NodeName: "worker-1"},
+				}
+				return newWorkloadPodApi(node, podA, podB)
+			},
+			then: func(t *testing.T, pods []v1.Pod, err error) {
+				require.NoError(t, err)
+				assert.ElementsMatch(t, []string{"pod-a", "pod-b"}, podNames(pods))
+			},
+		},
+		{
+			name: "should fail when node does not exist",
+			given: func() PodApi {
+				return newWorkloadPodApi()
+			},
+			then: func(t *testing.T, pods []v1.Pod, err error) {
+				require.Error(t, err)
+				assert.Nil(t, pods)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			podApi := tt.given()
+
+			// When
+			pods, err := podApi.GetPodsByNode(context.TODO(), "worker-1")
+
+			// Then
+			tt.then(t, pods, err)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
 	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -14,6 +15,16 @@ type PodApi interface {
 	GetPod(ctx context.Context, podName, namespace string) (*v1.Pod, error)
 	// GetPodsByLabelSelector returns the pods filtered by a label selector
 	GetPodsByLabelSelector(ctx context.Context, namespace, labelSelector string) ([]v1.Pod, error)
+	// GetPodsByWorkload returns the pods of the current revision of a workload (deployment, statefulset, daemonset,
+	// job or cronjob) together with the pod template used to create them
+	GetPodsByWorkload(ctx context.Context, namespace string, kind config.TargetKind, name string) ([]v1.Pod, *v1.PodTemplateSpec, error)
+	// GetPodsByService returns the ready pods currently receiving traffic from the EndpointSlices of a service,
+	// optionally filtered by the endpoint zone and the port (name or number)
+	GetPodsByService(ctx context.Context, namespace, serviceName, zone, port string) ([]v1.Pod, error)
+	// GetPodsByNode returns the pods of all namespaces scheduled on a node
+	GetPodsByNode(ctx context.Context, nodeName string) ([]v1.Pod, error)
+	// GetNamespace returns the namespace from its name
+	GetNamespace(ctx context.Context, name string) (*v1.Namespace, error)
 }
 
 // podApi implements PodApi and wraps kubernetes.ConnectionInfo
@@ -40,6 +51,13 @@ func (p *podApi) GetPod(ctx context.Context, podName, namespace string) (*v1.Pod
 	return podObject, nil
 }
 
+func (p *podApi) GetNamespace(ctx context.Context, name string) (*v1.Namespace, error) {
+	return p.connectionInfo.ClientSet.
+		CoreV1().
+		Namespaces().
+		Get(ctx, name, metav1.GetOptions{})
+}
+
 func (p *podApi) GetPodsByLabelSelector(ctx context.Context, namespace, labelSelector string) ([]v1.Pod, error) {
 	podList, err := p.connectionInfo.ClientSet.
 		CoreV1().
@@ -258,3 +258,20 @@ func Test_podAdapter_GetPodsByLabelSelector(t *testing.T) {
 	}
 
 }
+
+func Test_podApi_GetNamespace(t *testing.T) {
+	podApi := NewPodApi(kubernetes.ConnectionInfo{
+		ClientSet: testclient.NewSimpleClientset(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        "payments",
+			Annotations: map[string]string{"kubectl-prof.io/profiling": "disabled"},
+		}}),
+		RestConfig: &rest.Config{},
+	})
+
+	ns, err := podApi.GetNamespace(context.TODO(), "payments")
+	require.NoError(t, err)
+	assert.Equal(t, "disabled", ns.Annotations["kubectl-prof.io/profiling"])
+
+	_, err = podApi.GetNamespace(context.TODO(), "other")
+	assert.Error(t, err)
+}
@@ -0,0 +1,232 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes"
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes/job"
+	"github.com/pkg/errors"
+	authv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PreflightApi defines the checks run before launching the profiling agent
+type PreflightApi interface {
+	// Check returns every problem, along with its remediation, which would make the profiling fail once launched.
+	// The returned error tells the checks which could not be run, the problems found by the others are returned anyway.
+	Check(context.Context, *config.ProfilerConfig) ([]string, error)
+}
+
+// preflightApi implements PreflightApi and wraps kubernetes.ConnectionInfo
+type preflightApi struct {
+	connectionInfo kubernetes.ConnectionInfo
+}
+
+// NewPreflightApi returns new instance of PreflightApi
+func NewPreflightApi(connectionInfo kubernetes.ConnectionInfo) PreflightApi {
+	return &preflightApi{
+		connectionInfo: connectionInfo,
+	}
+}
+
+// access is a permission needed by the profiling session, cluster-wide if no namespace is given
+type access struct {
+	namespace   string
+	verb        string
+	group       string
+	resource    string
+	subresource string
+}
+
+func (a access) String() string {
+	resource := a.resource
+	if a.group != "" {
+		resource = fmt.Sprintf("%s.%s", resource, a.group)
+	}
+	if a.subresource != "" {
+		resource = fmt.Sprintf("%s/%s", resource, a.subresource)
+	}
+	if a.namespace == "" {
+		return fmt.Sprintf("%s %s cluster-wide", a.verb, resource)
+	}
+	return fmt.Sprintf("%s %s in namespace %s", a.verb, resource, a.namespace)
+}
+
+// remediation returns how to grant the access
+func (a access) remediation() string {
+	if a.namespace == "" {
+		return "grant it with a ClusterRole bound to your user"
+	}
+	return "grant it with a Role bound to your user in that namespace"
+}
+
+func (p *preflightApi) Check(ctx context.Context, cfg *config.ProfilerConfig) ([]string, error) {
+	var problems, unchecked []string
+	for _, a := range neededAccesses(cfg) {
+		allowed, err := p.isAllowed(ctx, a)
+		if err != nil {
+			// the remaining accesses would not be reviewed either
+			unchecked = append(unchecked, err.Error())
+			break
+		}
+		if !allowed {
+			problems = append(problems, fmt.Sprintf("not allowed to %s: %s", a, a.remediation()))
+		}
+	}
+
+	problem, err := p.checkPodSecurity(ctx, cfg)
+	if err != nil {
+		unchecked = append(unchecked, err.Error())
+	}
+	if problem != "" {
+		problems = append(problems, problem)
+	}
+
+	if len(unchecked) > 0 {
+		return problems, errors.New(strings.Join(unchecked, "; "))
+	}
+	return problems, nil
+}
+
+// neededAccesses returns the permissions needed by the profiling session according to its launch mode and target
+func neededAccesses(cfg *config.ProfilerConfig) []access {
+	namespace := cfg.Target.Namespace
+	var accesses []access
+	switch cfg.Target.Kind {
+	case config.Node:
+		// the pods running on the node are listed in every namespace, and so are read their namespaces for their policy
+		accesses = append(accesses,
+			access{verb: "get", resource: "nodes"},
+			access{verb: "list", resource: "pods"},
+		)
+		if !cfg.Target.OverridePolicy {
+			accesses = append(accesses, access{verb: "get", resource: "namespaces"})
+		}
+	case config.Deployment:
+		accesses = append(accesses,
+			access{namespace: namespace, verb: "get", group: "apps", resource: "deployments"},
+			access{namespace: namespace, verb: "list", group: "apps", resource: "replicasets"},
+		)
+	case config.StatefulSet:
+		accesses = append(accesses, access{namespace: namespace, verb: "get", group: "apps", resource: "statefulsets"})
+	case config.DaemonSet:
+		accesses = append(accesses,
+			access{namespace: namespace, verb: "get", group: "apps", resource: "daemonsets"},
+			access{namespace: namespace, verb: "list", group: "apps", resource: "controllerrevisions"},
+		)
+	case config.Job:
+		accesses = append(accesses, access{namespace: namespace, verb: "get", group: "batch", resource: "jobs"})
+	case config.CronJob:
+		accesses = append(accesses,
+			access{namespace: namespace, verb: "get", group: "batch", resource: "cronjobs"},
+			access{namespace: namespace, verb: "get", group: "batch", resource: "jobs"},
+		)
+	case config.Service:
+		accesses = append(accesses,
+			access{namespace: namespace, verb: "get", resource: "services"},
+			access{namespace: namespace, verb: "list", group: "discovery.k8s.io", resource: "endpointslices"},
+		)
+	}
+	if cfg.Target.Kind != config.Node {
+		accesses = append(accesses,
+			access{namespace: namespace, verb: "get", resource: "pods"},
+			access{namespace: namespace, verb: "list", resource: "pods"},
+		)
+		if !cfg.Target.OverridePolicy {
+			// the namespace is read for its profiling policy
+			accesses = append(accesses, access{namespace: namespace, verb: "get", resource: "namespaces"})
+		}
+	}
+
+	// the agent runs in the target pod with the ephemeral launch mode, else in the pod of the profiling job
+	agentNamespace := cfg.Job.Namespace
+	if cfg.LaunchMode == config.EphemeralLaunchMode {
+		agentNamespace = cfg.Target.Namespace
+		accesses = append(accesses,
+			access{namespace: agentNamespace, verb: "patch", resource: "pods", subresource: "ephemeralcontainers"},
+		)
+	} else {
+		accesses = append(accesses,
+			access{namespace: agentNamespace, verb: "create", group: "batch", resource: "jobs"},
+			access{namespace: agentNamespace, verb: "delete", group: "batch", resource: "jobs"},
+			access{namespace: agentNamespace, verb: "list", resource: "pods"},
+		)
+	}
+	return append(accesses,
+		access{namespace: agentNamespace, verb: "get", resource: "pods", subresource: "log"},
+		access{namespace: agentNamespace, verb: "create", resource: "pods", subresource: "exec"},
+	)
+}
+
+// isAllowed returns whether the user is granted the given permission
+func (p *preflightApi) isAllowed(ctx context.Context, a access) (bool, error) {
+	review, err := p.connectionInfo.ClientSet.
+		AuthorizationV1().
+		SelfSubjectAccessReviews().
+		Create(ctx, &authv1.SelfSubjectAccessReview{
+			Spec: authv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authv1.ResourceAttributes{
+					Namespace:   a.namespace,
+					Verb:        a.verb,
+					Group:       a.group,
+					Resource:    a.resource,
+					Subresource: a.subresource,
+				},
+			},
+		}, metav1.CreateOptions{})
+	if err != nil {
+		return false, errors.Wrapf(err, "could not review whether it is allowed to %s", a)
+	}
+	return review.Status.Allowed, nil
+}
+
+// checkPodSecurity returns why the Pod Security Admission of the namespace where the agent runs rejects it, if so.
+// A namespace without the enforce label is assumed to be privileged, which is returned as an error since the default
+// level given by the AdmissionConfiguration of the cluster cannot be read.
+func (p *preflightApi) checkPodSecurity(ctx context.Context, cfg *config.ProfilerConfig) (string, error) {
+	namespace := cfg.Job.Namespace
+	if cfg.LaunchMode == config.EphemeralLaunchMode {
+		namespace = cfg.Target.Namespace
+	}
+	ns, err := p.connectionInfo.ClientSet.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "could not check the Pod Security level of namespace %s", namespace)
+	}
+	level, labeled := ns.Labels[kubernetes.PodSecurityEnforceLabel]
+	if !labeled {
+		return "", errors.Errorf("namespace %s has no %s label, it is assumed to enforce the privileged level "+
+			"since the default level of the AdmissionConfiguration of the cluster cannot be checked", namespace, kubernetes.PodSecurityEnforceLabel)
+	}
+
+	creator, err := job.NewCreator(cfg.Target.Language, cfg.Target.ProfilingTool)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to create the job creator")
+	}
+	_, profilingJob, err := creator.Create(&v1.Pod{}, cfg)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to create job")
+	}
+	profilingJob, err = job.ApplyTemplate(profilingJob, cfg.Job.Template)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to apply the job template")
+	}
+
+	spec := &profilingJob.Spec.Template.Spec
+	remediation := "launch it from a namespace enforcing the privileged level with --namespace, " +
+		"giving the target pod with --target-namespace"
+	if cfg.LaunchMode == config.EphemeralLaunchMode {
+		// only the agent container is added to the target pod
+		spec = &v1.PodSpec{Containers: spec.Containers[:1]}
+		remediation = "use the job launch mode from a namespace enforcing the privileged level"
+	}
+	violations := kubernetes.PodSecurityViolations(level, spec)
+	if len(violations) == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("namespace %s enforces the %s Pod Security level, which rejects the agent (%s): %s",
+		namespace, level, strings.Join(violations, ", "), remediation), nil
+}
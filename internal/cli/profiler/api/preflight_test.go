@@ -0,0 +1,204 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	kubetesting "k8s.io/client-go/testing"
+)
+
+func Test_preflightApi_Check(t *testing.T) {
+	namespace := func(name, level string) *v1.Namespace {
+		return &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{kubernetes.PodSecurityEnforceLabel: level},
+		}}
+	}
+	tests := []struct {
+		name       string
+		namespaces []runtime.Object
+		denied     []string
+		reviewErr  error
+		launchMode config.LaunchMode
+		target     config.TargetConfig
+		then       func(t *testing.T, problems []string, err error)
+	}{
+		{
+			name:       "should find no problem",
+			namespaces: []runtime.Object{namespace("profiler", kubernetes.PrivilegedPodSecurity)},
+			then: func(t *testing.T, problems []string, err error) {
+				require.NoError(t, err)
+				assert.Empty(t, problems)
+			},
+		},
+		{
+			name:       "should report every denied access",
+			namespaces: []runtime.Object{namespace("profiler", kubernetes.PrivilegedPodSecurity)},
+			denied:     []string{"create jobs", "create pods/exec"},
+			then: func(t *testing.T, problems []string, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, []string{
+					"not allowed to create jobs.batch in namespace profiler: grant it with a Role bound to your user in that namespace",
+					"not allowed to create pods/exec in namespace profiler: grant it with a Role bound to your user in that namespace",
+				}, problems)
+			},
+		},
+		{
+			name:       "should report the rejection by the pod security admission",
+			namespaces: []runtime.Object{namespace("profiler", kubernetes.BaselinePodSecurity)},
+			then: func(t *testing.T, problems []string, err error) {
+				require.NoError(t, err)
+				require.Len(t, problems, 1)
+				assert.Contains(t, problems[0], "namespace profiler enforces the baseline Pod Security level")
+				assert.Contains(t, problems[0], "privileged container kubectl-prof")
+				assert.Contains(t, problems[0], "--target-namespace")
+			},
+		},
+		{
+			name:       "should check the target namespace with the ephemeral launch mode",
+			namespaces: []runtime.Object{namespace("default", kubernetes.BaselinePodSecurity)},
+			denied:     []string{"patch pods/ephemeralcontainers", "create jobs"},
+			launchMode: config.EphemeralLaunchMode,
+			then: func(t *testing.T, problems []string, err error) {
+				require.NoError(t, err)
+				require.Len(t, problems, 2)
+				assert.Equal(t, "not allowed to patch pods/ephemeralcontainers in namespace default: grant it with a Role bound to your user in that namespace", problems[0])
+				assert.Contains(t, problems[1], "namespace default enforces the baseline Pod Security level")
+				assert.NotContains(t, problems[1], "host PID namespace")
+			},
+		},
+		{
+			name:       "should check the accesses needed by a node target cluster-wide",
+			namespaces: []runtime.Object{namespace("profiler", kubernetes.PrivilegedPodSecurity)},
+			denied:     []string{"get nodes", "list pods", "get namespaces"},
+			target:     config.TargetConfig{Kind: config.Node, NodeName: "worker-1"},
+			then: func(t *testing.T, problems []string, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, []string{
+					"not allowed to get nodes cluster-wide: grant it with a ClusterRole bound to your user",
+					"not allowed to list pods cluster-wide: grant it with a ClusterRole bound to your user",
+					"not allowed to get namespaces cluster-wide: grant it with a ClusterRole bound to your user",
+					"not allowed to list pods in namespace profiler: grant it with a Role bound to your user in that namespace",
+				}, problems)
+			},
+		},
+		{
+			name:       "should check the accesses needed by a service target",
+			namespaces: []runtime.Object{namespace("profiler", kubernetes.PrivilegedPodSecurity)},
+			denied:     []string{"get services", "list endpointslices"},
+			target:     config.TargetConfig{Kind: config.Service, ServiceName: "my-service"},
+			then: func(t *testing.T, problems []string, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, []string{
+					"not allowed to get services in namespace default: grant it with a Role bound to your user in that namespace",
+					"not allowed to list endpointslices.discovery.k8s.io in namespace default: grant it with a Role bound to your user in that namespace",
+				}, problems)
+			},
+		},
+		{
+			name:       "should check the accesses needed by a deployment target",
+			namespaces: []runtime.Object{namespace("profiler", kubernetes.PrivilegedPodSecurity)},
+			denied:     []string{"get deployments", "list replicasets"},
+			target:     config.TargetConfig{Kind: config.Deployment, WorkloadName: "my-deployment"},
+			then: func(t *testing.T, problems []string, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, []string{
+					"not allowed to get deployments.apps in namespace default: grant it with a Role bound to your user in that namespace",
+					"not allowed to list replicasets.apps in namespace default: grant it with a Role bound to your user in that namespace",
+				}, problems)
+			},
+		},
+		{
+			name:       "should not check the access to the namespace when the policy is overridden",
+			namespaces: []runtime.Object{namespace("profiler", kubernetes.PrivilegedPodSecurity)},
+			denied:     []string{"get namespaces"},
+			target:     config.TargetConfig{ExtraTargetOptions: config.ExtraTargetOptions{OverridePolicy: true}},
+			then: func(t *testing.T, problems []string, err error) {
+				require.NoError(t, err)
+				assert.Empty(t, problems)
+			},
+		},
+		{
+			name:       "should assume a namespace without enforce label to be privileged",
+			namespaces: []runtime.Object{&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "profiler"}}},
+			then: func(t *testing.T, problems []string, err error) {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "namespace profiler has no pod-security.kubernetes.io/enforce label, it is assumed to enforce the privileged level")
+				assert.Empty(t, problems)
+			},
+		},
+		{
+			name:   "should report the pod security check which cannot be run when the namespace cannot be read",
+			denied: []string{"create pods/exec"},
+			then: func(t *testing.T, problems []string, err error) {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "could not check the Pod Security level of namespace profiler")
+				assert.Equal(t, []string{
+					"not allowed to create pods/exec in namespace profiler: grant it with a Role bound to your user in that namespace",
+				}, problems)
+			},
+		},
+		{
+			name:       "should report the accesses which cannot be reviewed and still check the pod security",
+			namespaces: []runtime.Object{namespace("profiler", kubernetes.BaselinePodSecurity)},
+			reviewErr:  errors.New("error reviewing"),
+			then: func(t *testing.T, problems []string, err error) {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "error reviewing")
+				require.Len(t, problems, 1)
+				assert.Contains(t, problems[0], "namespace profiler enforces the baseline Pod Security level")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			clientSet := testclient.NewSimpleClientset(tt.namespaces...)
+			clientSet.PrependReactor("create", "selfsubjectaccessreviews", func(action kubetesting.Action) (bool, runtime.Object, error) {
+				if tt.reviewErr != nil {
+					return true, nil, tt.reviewErr
+				}
+				review := action.(kubetesting.CreateAction).GetObject().(*authv1.SelfSubjectAccessReview)
+				attributes := review.Spec.ResourceAttributes
+				resource := attributes.Resource
+				if attributes.Subresource != "" {
+					resource += "/" + attributes.Subresource
+				}
+				review.Status.Allowed = true
+				for _, d := range tt.denied {
+					if d == attributes.Verb+" "+resource {
+						review.Status.Allowed = false
+					}
+				}
+				return true, review, nil
+			})
+			preflightApi := NewPreflightApi(kubernetes.ConnectionInfo{ClientSet: clientSet, RestConfig: &rest.Config{}})
+			tt.target.Namespace = "default"
+			tt.target.Language = api.Go
+			tt.target.ProfilingTool = api.Bpf
+			cfg := &config.ProfilerConfig{
+				Target:     &tt.target,
+				Job:        &config.JobConfig{Namespace: "profiler", ContainerConfig: config.ContainerConfig{Privileged: true}},
+				LaunchMode: tt.launchMode,
+			}
+
+			// When
+			problems, err := preflightApi.Check(context.Background(), cfg)
+
+			// Then
+			tt.then(t, problems, err)
+		})
+	}
+}
@@ -10,22 +10,24 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/alitto/pond"
-	"github.com/josepdcs/kubectl-prof/api"
 	"github.com/josepdcs/kubectl-prof/internal/cli/config"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
-	"golang.org/x/exp/slices"
 
 	"github.com/agrison/go-commons-lang/stringUtils"
+	"github.com/josepdcs/kubectl-prof/api"
 	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes"
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes/job"
 	"github.com/josepdcs/kubectl-prof/internal/cli/result"
 	"github.com/josepdcs/kubectl-prof/pkg/util/compressor"
 	podexec "github.com/josepdcs/kubectl-prof/pkg/util/pod"
+	"github.com/josepdcs/kubectl-prof/pkg/util/s3"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type EventHandler interface {
@@ -39,12 +41,21 @@ type ProfilingContainerApi interface {
 	HandleProfilingContainerLogs(pod *v1.Pod, containerName string, handler EventHandler, ctx context.Context) (chan bool, chan result.File, error)
 	// GetRemoteFile returns the remote file from the pod's container
 	GetRemoteFile(pod *v1.Pod, containerName string, remoteFile result.File, targetPodName string, target *config.TargetConfig) (string, error)
+	// StopProfiling asks the agent of the profiling container to finish its current capture early and publish the partial results
+	StopProfiling(pod *v1.Pod, containerName string) error
+	// TerminateProfiling asks the agent of the profiling container to end at once, without waiting for its results
+	// to be retrieved
+	TerminateProfiling(pod *v1.Pod, containerName string) error
+	// AcknowledgeResult tells the agent of the profiling container that the given result file was retrieved,
+	// so that the agent can end as soon as every result file is retrieved
+	AcknowledgeResult(pod *v1.Pod, containerName string, remoteFile result.File) error
 }
 
 // profilingContainerApi implements ProfilingContainerApi and wraps kubernetes.ConnectionInfo
 type profilingContainerApi struct {
 	connectionInfo kubernetes.ConnectionInfo
 	executor       podexec.Executor
+	portForwarder  podexec.PortForwarder
 }
 
 // NewProfilingContainerApi returns new instance of ProfilingContainerApi
@@ -52,6 +63,7 @@ func NewProfilingContainerApi(connectionInfo kubernetes.ConnectionInfo) Profilin
 	return &profilingContainerApi{
 		connectionInfo: connectionInfo,
 		executor:       podexec.NewExec(connectionInfo.RestConfig, connectionInfo.ClientSet),
+		portForwarder:  podexec.NewPortForward(connectionInfo.RestConfig, connectionInfo.ClientSet),
 	}
 }
 
@@ -99,197 +111,304 @@ func (p *profilingContainerApi) HandleProfilingContainerLogs(pod *v1.Pod, contai
 }
 
 func (p *profilingContainerApi) GetRemoteFile(pod *v1.Pod, containerName string, remoteFile result.File, targetPodName string, target *config.TargetConfig) (string, error) {
-	var fileBuff []byte
-
-	if remoteFile.Chunks != nil && len(remoteFile.Chunks) > 0 {
-		chunks, err := retrieveChunks(pod, containerName, remoteFile, p.executor, target)
+	if remoteFile.Object != "" {
+		f, err := p.newObjectStorageFetcher(pod, containerName)
 		if err != nil {
 			return "", err
 		}
+		return getRemoteFile(f, remoteFile, targetPodName, target)
+	}
 
-		fileBuff, err = readChunks(chunks, remoteFile.FileSizeInBytes)
-		if err != nil {
-			return "", err
+	if remoteFile.URL != "" {
+		fileName, err := p.getRemoteFileFromFileServer(pod, containerName, remoteFile, targetPodName, target)
+		if err == nil {
+			return fileName, nil
 		}
-	} else {
-		var err error
-		fileBuff, err = retrieveFileOrRetry(pod, containerName, p.executor, remoteFile, target)
-		if err != nil {
-			return "", err
+		log.Debugf("could not download file %s from the file server of the agent, falling back to exec: %v", remoteFile.FileName, err)
+	}
+
+	return getRemoteFile(&execFetcher{pod: pod, containerName: containerName, exec: p.executor}, remoteFile, targetPodName, target)
+}
+
+// agentPIDCommand reads the PID recorded by the agent
+var agentPIDCommand = []string{"cat", api.AgentPIDFile}
+
+// signalAgent sends the given signal (e.g. USR1) to the agent running in the given container, by its recorded PID
+func (p *profilingContainerApi) signalAgent(pod *v1.Pod, containerName string, signal string) error {
+	var out, errOut bytes.Buffer
+	if err := p.executor.Execute(pod.Namespace, pod.Name, containerName, agentPIDCommand, &out, &errOut); err != nil {
+		return errors.Wrapf(err, "could not read the PID of the agent: %s", errOut.String())
+	}
+	pid := strings.TrimSpace(out.String())
+	if _, err := strconv.Atoi(pid); err != nil {
+		return errors.Errorf("invalid PID of the agent %q", pid)
+	}
+
+	errOut.Reset()
+	if err := p.executor.Execute(pod.Namespace, pod.Name, containerName, []string{"kill", "-" + signal, pid}, io.Discard, &errOut); err != nil {
+		return errors.Wrapf(err, "could not send SIG%s to the agent: %s", signal, errOut.String())
+	}
+	return nil
+}
+
+// StopProfiling sends SIGUSR1 to the agent, which stops the profiling early while keeping its partial results
+func (p *profilingContainerApi) StopProfiling(pod *v1.Pod, containerName string) error {
+	return errors.Wrap(p.signalAgent(pod, containerName, "USR1"), "could not stop the profiling early")
+}
+
+// TerminateProfiling sends SIGTERM to the agent, which terminates the running profiling tools and cleans up
+func (p *profilingContainerApi) TerminateProfiling(pod *v1.Pod, containerName string) error {
+	return errors.Wrap(p.signalAgent(pod, containerName, "TERM"), "could not terminate the profiling")
+}
+
+// AcknowledgeResult tells the file server of the agent that the given result file was retrieved. If the file server
+// cannot be reached, it writes the acknowledgement marker next to the result file and sends SIGUSR2 to the agent,
+// which checks the markers of its pending result files.
+func (p *profilingContainerApi) AcknowledgeResult(pod *v1.Pod, containerName string, remoteFile result.File) error {
+	if remoteFile.URL != "" {
+		err := p.acknowledgeResultToFileServer(pod, containerName, remoteFile)
+		if err == nil {
+			return nil
 		}
+		log.Debugf("could not acknowledge file %s to the file server of the agent, falling back to exec: %v", remoteFile.FileName, err)
 	}
 
-	fileName := filepath.Join(target.LocalPath, renameResultFileName(targetPodName, remoteFile.FileName, remoteFile.Timestamp))
-	decompressedFile, err := os.Create(fileName)
-	if err != nil {
-		return "", errors.Wrap(err, "could not create result file")
+	var errOut bytes.Buffer
+	marker := remoteFile.FileName + api.AcknowledgementSuffix
+	if err := p.executor.Execute(pod.Namespace, pod.Name, containerName, []string{"touch", marker}, io.Discard, &errOut); err != nil {
+		return errors.Wrapf(err, "could not acknowledge the result file %s: %s", remoteFile.FileName, errOut.String())
 	}
+	return errors.Wrapf(p.signalAgent(pod, containerName, "USR2"), "could not acknowledge the result file %s", remoteFile.FileName)
+}
 
-	comp, err := compressor.Get(target.Compressor)
-	if err != nil {
-		return "", errors.Wrap(err, "could not get compressor")
+// acknowledgeResultToFileServer acknowledges the given result file to the file server of the agent through a port-forward
+func (p *profilingContainerApi) acknowledgeResultToFileServer(pod *v1.Pod, containerName string, remoteFile result.File) error {
+	port := fileServerPort(pod, containerName)
+	if port <= 0 {
+		return errors.New("the file server of the agent is not enabled")
 	}
 
-	err = comp.Decode(decompressedFile, bytes.NewReader(fileBuff))
+	localPort, stop, err := p.portForwarder.Forward(pod.Namespace, pod.Name, port)
 	if err != nil {
-		return "", errors.Wrap(err, "could not decompress remote file")
+		return err
 	}
+	defer stop()
 
-	return fileName, nil
+	return newHTTPFetcher(localPort).Acknowledge(source{File: remoteFile.FileName, URL: remoteFile.URL})
 }
 
-// retrieveChunks retrieves the chunks of the remote file from the pod's container
-func retrieveChunks(pod *v1.Pod, containerName string, remoteFile result.File, exec podexec.Executor, target *config.TargetConfig) ([]string, error) {
-	downloadChunks := make([]string, 0, len(remoteFile.Chunks))
+// getRemoteFileFromFileServer downloads the remote file from the file server of the agent through a port-forward
+func (p *profilingContainerApi) getRemoteFileFromFileServer(pod *v1.Pod, containerName string, remoteFile result.File,
+	targetPodName string, target *config.TargetConfig) (string, error) {
+	port := fileServerPort(pod, containerName)
+	if port <= 0 {
+		return "", errors.New("the file server of the agent is not enabled")
+	}
 
-	pool := pond.New(target.PoolSizeRetrieveChunks, 0, pond.MinWorkers(target.PoolSizeRetrieveChunks))
-	defer pool.StopAndWait()
+	localPort, stop, err := p.portForwarder.Forward(pod.Namespace, pod.Name, port)
+	if err != nil {
+		return "", err
+	}
+	defer stop()
 
-	// create a task group associated to a context
-	group, _ := pool.GroupContext(context.Background())
+	return getRemoteFile(newHTTPFetcher(localPort), remoteFile, targetPodName, target)
+}
 
-	// submit tasks to download chunks
-	for _, chunk := range remoteFile.Chunks {
-		chunk := chunk
-		group.Submit(func() error {
-			fileName, err := retrieveChunkOrRetry(chunk, pod, containerName, exec, target, remoteFile.Timestamp)
-			if err == nil {
-				downloadChunks = append(downloadChunks, fileName)
-			}
-			return err
-		})
+// newObjectStorageFetcher returns a fetcher for the object storage where the given agent container uploads the result files.
+// The configuration of the object storage is read from the same Secret as the agent does.
+func (p *profilingContainerApi) newObjectStorageFetcher(pod *v1.Pod, containerName string) (fetcher, error) {
+	secretName := job.UploadSecretName(agentContainer(pod, containerName))
+	if secretName == "" {
+		return nil, errors.New("the configuration of the object storage is unknown")
+	}
+	secret, err := p.connectionInfo.ClientSet.CoreV1().Secrets(pod.Namespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the configuration of the object storage")
+	}
+	client, err := s3.NewClient(s3.ConfigFromSecret(secret.Data))
+	if err != nil {
+		return nil, err
+	}
+	return &objectStorageFetcher{client: client}, nil
+}
+
+// getRemoteFile downloads the remote file with the given fetcher and decompresses it into the local path
+func getRemoteFile(f fetcher, remoteFile result.File, targetPodName string, target *config.TargetConfig) (string, error) {
+	var chunks *chunkDownloader
+	if len(remoteFile.Chunks) > 0 {
+		chunks = newChunkDownloader(f, remoteFile, targetPodName, target)
+		if err := chunks.Download(); err != nil {
+			return "", err
+		}
+	}
+
+	fileName := filepath.Join(target.LocalPath, renameResultFileName(targetPodName, remoteFile.FileName, remoteFile.Timestamp))
+	decompressedFile, err := os.Create(fileName)
+	if err != nil {
+		return "", errors.Wrap(err, "could not create result file")
 	}
+	defer func() { _ = decompressedFile.Close() }()
 
-	// wait for all tasks to finish
-	err := group.Wait()
+	err = decodeRemoteFile(f, remoteFile, chunks, target, decompressedFile)
+	if err != nil {
+		// do not leave an incomplete result file
+		_ = decompressedFile.Close()
+		_ = os.Remove(fileName)
+		return "", err
+	}
+	if chunks != nil {
+		chunks.CleanUp()
+	}
 
-	return downloadChunks, err
+	return fileName, nil
 }
 
-func retrieveFileOrRetry(pod *v1.Pod, containerName string, exec podexec.Executor, remoteFile result.File, target *config.TargetConfig) ([]byte, error) {
-	fileBuff := make([]byte, 0, remoteFile.FileSizeInBytes)
-	for i := 0; i <= target.RetrieveFileRetries; i++ {
-		offset := 0
-		n := 0
-		var err error
-		for (remoteFile.FileSizeInBytes - int64(n)) > 0 {
-			var out, errOut *bytes.Buffer
-			_, out, errOut, err = exec.Execute(pod.Namespace, pod.Name, containerName, []string{"sh", "-c", fmt.Sprintf("tail -c+%d %s", offset, remoteFile.FileName)})
-			if err != nil {
-				log.Errorf("could not download profiler result file from pod: %s, error: %v", errOut.String(), err)
-				break
-			}
-			n += out.Len()
-			offset += out.Len() + 1
-			fileBuff = append(fileBuff, out.Bytes()...)
+// decodeRemoteFile decompresses the remote file into the given destination. The remote file is read from the
+// partial file of the downloaded chunks, or it is downloaded into a local temporary file when it is not split in chunks.
+func decodeRemoteFile(f fetcher, remoteFile result.File, chunks *chunkDownloader, target *config.TargetConfig, dst io.Writer) error {
+	var src io.Reader
+	if chunks != nil {
+		partFile, err := os.Open(chunks.partFileName)
+		if err != nil {
+			return errors.Wrap(err, "could not open chunk file")
 		}
-
+		defer closeFiles([]*os.File{partFile})
+		src = partFile
+	} else {
+		compressedFile, err := os.CreateTemp(localDir(target), "."+stringUtils.SubstringAfterLast(remoteFile.FileName, "/")+"-*")
 		if err != nil {
-			fileBuff = make([]byte, 0, remoteFile.FileSizeInBytes)
-			continue
+			return errors.Wrap(err, "could not create temporary file")
 		}
+		defer removeFiles([]string{compressedFile.Name()})
+		defer closeFiles([]*os.File{compressedFile})
 
-		// check the checksum of the downloaded file
-		checksum := getMD5Hash(fileBuff)
-		log.Debugf("File %s downloaded (local: %s (%d bytes) | remote: %s (%d bytes))", remoteFile.FileName, checksum, len(fileBuff), remoteFile.Checksum, remoteFile.FileSizeInBytes)
-
-		if checksum == remoteFile.Checksum {
-			return fileBuff, nil
+		err = retrieveFileOrRetry(f, remoteFile, target, compressedFile)
+		if err != nil {
+			return err
 		}
-		log.Debugf("Checksum does not match, retrying: %s...", remoteFile.FileName)
+		if _, err = compressedFile.Seek(0, io.SeekStart); err != nil {
+			return errors.Wrap(err, "could not read temporary file")
+		}
+		src = compressedFile
+	}
 
-		fileBuff = make([]byte, 0, remoteFile.FileSizeInBytes)
+	comp, err := compressor.Get(target.Compressor)
+	if err != nil {
+		return errors.Wrap(err, "could not get compressor")
+	}
+
+	err = comp.Decode(dst, bufio.NewReader(src))
+	if err != nil {
+		return errors.Wrap(err, "could not decompress remote file")
 	}
 
-	// if the checksum does not match after the last retry, return an error
-	return nil, errors.Errorf("checksum does not match for file %s", remoteFile.FileName)
+	return nil
 }
 
-func retrieveChunkOrRetry(chunk api.ChunkData, pod *v1.Pod, containerName string, exec podexec.Executor, target *config.TargetConfig, timestamp time.Time) (string, error) {
-	for i := 0; i <= target.RetrieveFileRetries; i++ {
-		// download the chunk file
-		fileBuff, err := retrieveChunk(chunk, pod, containerName, exec)
+// retrieveFileOrRetry streams the remote file into the given local file
+func retrieveFileOrRetry(f fetcher, remoteFile result.File, target *config.TargetConfig, dst *os.File) error {
+	err := download(f, source{File: remoteFile.FileName, URL: remoteFile.URL, Object: remoteFile.Object}, remoteFile.FileSizeInBytes, remoteFile.Checksum, target.RetrieveFileRetries, dst, 0)
+	if errors.Is(err, errChecksumMismatch) {
+		return errors.Errorf("checksum does not match for file %s", remoteFile.FileName)
+	}
+	return err
+}
+
+// errChecksumMismatch is returned when a remote file could not be downloaded with the expected checksum
+var errChecksumMismatch = errors.New("checksum does not match")
+
+// download streams the remote file into dst, starting at the given offset, while its MD5 checksum is computed incrementally,
+// so that the remote file is never held in memory. If the transfer is interrupted, it is resumed from the last byte received.
+// If the checksum does not match once the whole file is received, the download starts over.
+// It is retried up to the given number of times.
+func download(f fetcher, src source, size int64, checksum string, retries int, dst io.WriterAt, offset int64) error {
+	hash := md5.New()
+	var received int64
+	for i := 0; i <= retries; i++ {
+		w := &countingWriter{w: io.MultiWriter(hash, io.NewOffsetWriter(dst, offset+received))}
+		err := f.Fetch(src, received, w)
+		received += w.n
 		if err != nil {
-			log.Errorf("could not download profiler chunk file from pod: %v", err)
+			log.Errorf("could not download profiler file %s from pod (%d of %d bytes received), error: %v",
+				src.name(), received, size, err)
+			continue
+		}
+		if received < size {
+			log.Debugf("File %s partially downloaded (%d of %d bytes), resuming...", src.name(), received, size)
 			continue
 		}
 
-		// check the checksum of the downloaded chunk
-		checksum := getMD5Hash(fileBuff)
-		log.Debugf("Chunk file %s downloaded (local: %s (%d bytes) | remote: %s (%d bytes))", chunk.File, checksum, len(fileBuff), chunk.Checksum, chunk.FileSizeInBytes)
-
-		// if the checksum matches, write the chunk file to the local filesystem
-		// and return the file name
-		if checksum == chunk.Checksum {
-			fileName := filepath.Join(target.LocalPath, renameChunkFileName(chunk.File, timestamp))
-			err = os.WriteFile(fileName, fileBuff, 0644)
-			if err != nil {
-				return "", errors.Wrap(err, "could not write chunk file")
-			}
-
-			return fileName, nil
+		localChecksum := hex.EncodeToString(hash.Sum(nil))
+		log.Debugf("File %s downloaded (local: %s (%d bytes) | remote: %s (%d bytes))", src.name(), localChecksum, received, checksum, size)
+		if localChecksum == checksum {
+			return nil
 		}
-		log.Debugf("Checksum does not match, retrying: %s...", chunk.File)
+		log.Debugf("Checksum does not match, retrying: %s...", src.name())
+
+		// start over
+		hash.Reset()
+		received = 0
 	}
 
-	// if the checksum does not match after the last retry, return an error
-	return "", errors.Errorf("checksum does not match for chunk file %s", chunk.File)
+	return errChecksumMismatch
 }
 
-// retrieveChunk retrieves the chunk of the remote file from the pod's container
-func retrieveChunk(chunk api.ChunkData, pod *v1.Pod, containerName string, exec podexec.Executor) ([]byte, error) {
-	fileBuff := make([]byte, 0, chunk.FileSizeInBytes)
-	offset := 0
-	n := 0
-	log.Debugf("Downloading chunk file %s ...", chunk.File)
-	var err error
-	for (chunk.FileSizeInBytes - int64(n)) > 0 {
-		var out, errOut *bytes.Buffer
-		_, out, errOut, err = exec.Execute(pod.Namespace, pod.Name, containerName, []string{"sh", "-c", fmt.Sprintf("tail -c+%d %s", offset, chunk.File)})
-		if err != nil {
-			log.Errorf("could not download profiler chunk file from pod: %s, error: %v", errOut.String(), err)
-			break
-		}
-		n += out.Len()
-		offset += out.Len() + 1
-		fileBuff = append(fileBuff, out.Bytes()...)
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// closeFiles closes the given files
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		_ = f.Close()
 	}
+}
 
-	if err != nil {
-		return nil, errors.Wrap(err, "could not download profiler chunk file")
+// removeFiles removes the given files
+func removeFiles(fileNames []string) {
+	for _, fileName := range fileNames {
+		if err := os.Remove(fileName); err != nil && !os.IsNotExist(err) {
+			log.Debugf("could not remove file %s: %v", fileName, err)
+		}
 	}
+}
 
-	return fileBuff, nil
+// fileServerPort returns the port of the file server of the given agent container, or 0 if it is not enabled
+func fileServerPort(pod *v1.Pod, containerName string) int {
+	port, _ := strconv.Atoi(kubernetes.ArgumentValue(agentContainer(pod, containerName).Args, "--file-server-port"))
+	return port
 }
 
-// readChunks reads the download chunks of the remote file from the pod's container.
-// Each chunk is appended to the fileBuff which is returned
-func readChunks(downloadChunks []string, fileBuffSize int64) ([]byte, error) {
-	fileBuff := make([]byte, 0, fileBuffSize)
-	// be sure that the chunks are sorted
-	slices.Sort(downloadChunks)
-	for _, downloadChunk := range downloadChunks {
-		chunkFile, err := os.Open(downloadChunk)
-		if err != nil {
-			return nil, errors.Wrap(err, "could not open chunk file")
+// agentContainer returns the given agent container of the pod, which is either a container of a profiling job
+// or an ephemeral container
+func agentContainer(pod *v1.Pod, containerName string) v1.Container {
+	for _, c := range pod.Spec.Containers {
+		if c.Name == containerName {
+			return c
 		}
-
-		reader := bufio.NewReader(chunkFile)
-		content, err := io.ReadAll(reader)
-		if err != nil {
-			return nil, errors.Wrap(err, "could not read chunk file")
+	}
+	for _, c := range pod.Spec.EphemeralContainers {
+		if c.Name == containerName {
+			return v1.Container(c.EphemeralContainerCommon)
 		}
+	}
+	return v1.Container{}
+}
 
-		fileBuff = append(fileBuff, content...)
-
-		_ = chunkFile.Close()
-
-		err = os.Remove(downloadChunk)
-		if err != nil {
-			return nil, errors.Wrapf(err, "could not remove chunk file")
-		}
+// localDir returns the local directory where the result files are saved
+func localDir(target *config.TargetConfig) string {
+	if target.LocalPath == "" {
+		return "."
 	}
-	return fileBuff, nil
+	return target.LocalPath
 }
 
 // renameResultFileName renames the result file
@@ -304,9 +423,3 @@ func renameChunkFileName(fileName string, t time.Time) string {
 	f := stringUtils.RemoveEnd(stringUtils.SubstringAfterLast(fileName, "/"), ending)
 	return stringUtils.SubstringBefore(f, ".") + "-" + strings.ReplaceAll(t.Format(time.RFC3339), ":", "_") + "." + stringUtils.SubstringAfter(f, ".") + ending
 }
-
-// getMD5Hash returns the MD5 hash of the given text
-func getMD5Hash(text []byte) string {
-	hash := md5.Sum(text)
-	return hex.EncodeToString(hash[:])
-}
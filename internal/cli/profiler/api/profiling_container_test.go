@@ -3,6 +3,10 @@ package api
 import (
 	"bytes"
 	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
@@ -35,9 +39,83 @@ type mockExecutor struct {
 	calls      int
 }
 
-func (e *mockExecutor) Execute(string, string, string, []string) (*bytes.Buffer, *bytes.Buffer, *bytes.Buffer, error) {
+func (e *mockExecutor) Execute(_ string, _ string, _ string, _ []string, stdout, stderr io.Writer) error {
 	e.calls++
-	return nil, e.outFake, e.errOutFake, e.fakeError
+	if e.outFake != nil {
+		_, _ = stdout.Write(e.outFake.Bytes())
+	}
+	if e.errOutFake != nil {
+		_, _ = stderr.Write(e.errOutFake.Bytes())
+	}
+	return e.fakeError
+}
+
+// getMD5Hash returns the MD5 hash of the given text
+func getMD5Hash(text []byte) string {
+	hash := md5.Sum(text)
+	return hex.EncodeToString(hash[:])
+}
+
+// resumableExecutor serves the content of a remote file as "tail -c +<offset> <file>" does,
+// failing once after sending the given number of bytes
+type resumableExecutor struct {
+	content   []byte
+	failAfter int
+	failed    bool
+	commands  []string
+}
+
+func (e *resumableExecutor) Execute(_ string, _ string, _ string, command []string, stdout, _ io.Writer) error {
+	e.commands = append(e.commands, command[2])
+	var offset int
+	var file string
+	_, _ = fmt.Sscanf(command[2], "tail -c +%d %s", &offset, &file)
+	content := e.content[offset-1:]
+	if !e.failed && e.failAfter < len(content) {
+		e.failed = true
+		_, _ = stdout.Write(content[:e.failAfter])
+		return errors.New("connection reset")
+	}
+	_, _ = stdout.Write(content)
+	return nil
+}
+
+func Test_download(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "PodName", Namespace: "Namespace"}}
+	content := []byte("the content of the remote profiling result file")
+
+	t.Run("should resume the download from the last byte received", func(t *testing.T) {
+		// Given
+		exec := &resumableExecutor{content: content, failAfter: 10}
+		dst, err := os.CreateTemp(t.TempDir(), "download")
+		require.NoError(t, err)
+		defer func() { _ = dst.Close() }()
+
+		// When
+		err = download(&execFetcher{pod: pod, containerName: "ContainerName", exec: exec}, source{File: "/tmp/result.gz"}, int64(len(content)), getMD5Hash(content), 1, dst, 0)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"tail -c +1 /tmp/result.gz", "tail -c +11 /tmp/result.gz"}, exec.commands)
+		downloaded, err := os.ReadFile(dst.Name())
+		require.NoError(t, err)
+		assert.Equal(t, content, downloaded)
+	})
+
+	t.Run("should start over when the checksum does not match", func(t *testing.T) {
+		// Given
+		exec := &resumableExecutor{content: content, failAfter: len(content)}
+		dst, err := os.CreateTemp(t.TempDir(), "download")
+		require.NoError(t, err)
+		defer func() { _ = dst.Close() }()
+
+		// When
+		err = download(&execFetcher{pod: pod, containerName: "ContainerName", exec: exec}, source{File: "/tmp/result.gz"}, int64(len(content)), getMD5Hash([]byte("other")), 2, dst, 0)
+
+		// Then
+		require.ErrorIs(t, err, errChecksumMismatch)
+		assert.Equal(t, []string{"tail -c +1 /tmp/result.gz", "tail -c +1 /tmp/result.gz", "tail -c +1 /tmp/result.gz"}, exec.commands)
+	})
 }
 
 func Test_profilingContainerAdapter_HandleProfilingContainerLogs(t *testing.T) {
@@ -559,6 +637,9 @@ func Test_profilingContainerAdapter_GetRemoteFile(t *testing.T) {
 				// 1 initial try + 2 retries = 3 calls
 				assert.Equal(t, 3, f.ProfilingContainerApi.(*profilingContainerApi).executor.(*mockExecutor).calls)
 			},
+			afterEach: func() {
+				_ = os.Remove(filepath.Join(common.TmpDir(), ".-flamegraph-2023-02-28T11_44_12Z.svg.gz.part"))
+			},
 		},
 		{
 			name: "should get remote file fail when compressor is unknown",
@@ -888,6 +969,9 @@ func Test_profilingContainerAdapter_GetRemoteFile(t *testing.T) {
 				require.Error(t, r.err)
 				assert.EqualError(t, r.err, "checksum does not match for chunk file "+filepath.Join(common.TmpDir(), "flamegraph.svg.gz.00"))
 			},
+			afterEach: func() {
+				_ = os.Remove(filepath.Join(common.TmpDir(), ".-flamegraph-2023-02-28T11_44_12Z.svg.gz.part"))
+			},
 		},
 		{
 			name: "should get remote file fail when checksum chunk is not equal",
@@ -960,6 +1044,9 @@ func Test_profilingContainerAdapter_GetRemoteFile(t *testing.T) {
 				require.Error(t, r.err)
 				assert.EqualError(t, r.err, "checksum does not match for chunk file "+filepath.Join(common.TmpDir(), "flamegraph.svg.gz.00"))
 			},
+			afterEach: func() {
+				_ = os.Remove(filepath.Join(common.TmpDir(), ".-flamegraph-2023-02-28T11_44_12Z.svg.gz.part"))
+			},
 		},
 		{
 			name: "should get remote file fail when chunks are not written to disk",
@@ -1015,6 +1102,7 @@ func Test_profilingContainerAdapter_GetRemoteFile(t *testing.T) {
 						target: &config.TargetConfig{
 							LocalPath:  "/other",
 							Compressor: compressor.None,
+							PodName:    "pod-name",
 						},
 					}
 			},
@@ -1027,7 +1115,7 @@ func Test_profilingContainerAdapter_GetRemoteFile(t *testing.T) {
 			},
 			then: func(t *testing.T, r result, f fields) {
 				require.Error(t, r.err)
-				assert.EqualError(t, r.err, "could not write chunk file: open /other/flamegraph-2023-02-28T11_44_12Z.svg.gz.00: no such file or directory")
+				assert.EqualError(t, r.err, "could not write chunk file: open /other/.pod-name-flamegraph-2023-02-28T11_44_12Z.svg.gz.part: no such file or directory")
 			},
 		},
 	}
@@ -1048,3 +1136,67 @@ func Test_profilingContainerAdapter_GetRemoteFile(t *testing.T) {
 		})
 	}
 }
+
+// commandsExecutor records the executed commands, answering the given PID to the reading of the PID of the agent
+type commandsExecutor struct {
+	pid      string
+	commands [][]string
+}
+
+func (e *commandsExecutor) Execute(_ string, _ string, _ string, command []string, stdout, _ io.Writer) error {
+	e.commands = append(e.commands, command)
+	if command[0] == "cat" {
+		_, _ = stdout.Write([]byte(e.pid + "\n"))
+	}
+	return nil
+}
+
+func Test_profilingContainerApi_StopProfiling(t *testing.T) {
+	executor := &commandsExecutor{pid: "4242"}
+	p := &profilingContainerApi{executor: executor}
+
+	err := p.StopProfiling(&v1.Pod{}, "ContainerName")
+
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"cat", api.AgentPIDFile},
+		{"kill", "-USR1", "4242"},
+	}, executor.commands)
+}
+
+func Test_profilingContainerApi_TerminateProfiling(t *testing.T) {
+	executor := &commandsExecutor{pid: "4242"}
+	p := &profilingContainerApi{executor: executor}
+
+	err := p.TerminateProfiling(&v1.Pod{}, "ContainerName")
+
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"cat", api.AgentPIDFile},
+		{"kill", "-TERM", "4242"},
+	}, executor.commands)
+}
+
+func Test_profilingContainerApi_AcknowledgeResult(t *testing.T) {
+	executor := &commandsExecutor{pid: "4242"}
+	p := &profilingContainerApi{executor: executor}
+
+	err := p.AcknowledgeResult(&v1.Pod{}, "ContainerName", resultfile.File{FileName: "/tmp/flamegraph.svg.gz"})
+
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"touch", "/tmp/flamegraph.svg.gz" + api.AcknowledgementSuffix},
+		{"cat", api.AgentPIDFile},
+		{"kill", "-USR2", "4242"},
+	}, executor.commands)
+}
+
+func Test_profilingContainerApi_StopProfiling_withInvalidPID(t *testing.T) {
+	executor := &commandsExecutor{pid: "1 2"}
+	p := &profilingContainerApi{executor: executor}
+
+	err := p.StopProfiling(&v1.Pod{}, "ContainerName")
+
+	require.Error(t, err)
+	assert.Len(t, executor.commands, 1)
+}
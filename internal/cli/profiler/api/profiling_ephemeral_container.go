@@ -0,0 +1,143 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/internal/cli"
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes"
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes/job"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// ProfilingEphemeralContainerApi defines all methods related to the profiling agent launched as an ephemeral container
+// into the target pod
+type ProfilingEphemeralContainerApi interface {
+	// AddProfilingEphemeralContainer injects the profiling agent as an ephemeral container into the target pod
+	// and returns the profiling ID and the name of the added container
+	AddProfilingEphemeralContainer(*v1.Pod, *config.ProfilerConfig, context.Context) (string, string, error)
+	// GetProfilingPod returns the target pod once its profiling ephemeral container has been started
+	GetProfilingPod(*v1.Pod, string, context.Context, time.Duration) (*v1.Pod, error)
+	// FindProfilingPod returns the pod of the given namespace holding the profiling ephemeral container of the given
+	// profiling ID, or nil if there is none
+	FindProfilingPod(string, string, context.Context) (*v1.Pod, error)
+}
+
+// profilingEphemeralContainerApi implements ProfilingEphemeralContainerApi and wraps kubernetes.ConnectionInfo
+type profilingEphemeralContainerApi struct {
+	connectionInfo kubernetes.ConnectionInfo
+}
+
+// NewProfilingEphemeralContainerApi returns new instance of ProfilingEphemeralContainerApi
+func NewProfilingEphemeralContainerApi(connectionInfo kubernetes.ConnectionInfo) ProfilingEphemeralContainerApi {
+	return &profilingEphemeralContainerApi{
+		connectionInfo: connectionInfo,
+	}
+}
+
+func (p *profilingEphemeralContainerApi) AddProfilingEphemeralContainer(targetPod *v1.Pod, cfg *config.ProfilerConfig, ctx context.Context) (string, string, error) {
+	id, container, err := job.NewEphemeralContainer(targetPod, cfg)
+	if err != nil {
+		return "", "", err
+	}
+
+	pod := targetPod.DeepCopy()
+	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, *container)
+
+	if cfg.Target.DryRun {
+		return "", "", printPod(pod)
+	}
+
+	_, err = p.connectionInfo.ClientSet.
+		CoreV1().
+		Pods(pod.Namespace).
+		UpdateEphemeralContainers(ctx, pod.Name, pod, metav1.UpdateOptions{})
+	if err != nil {
+		return "", "", errors.Wrap(err, "unable to add the profiling ephemeral container")
+	}
+
+	return id, container.Name, nil
+}
+
+func printPod(pod *v1.Pod) error {
+	encoder := json.NewSerializerWithOptions(json.DefaultMetaFactory, nil, nil, json.SerializerOptions{
+		Yaml: true,
+	})
+
+	return encoder.Encode(pod, cli.Out())
+}
+
+func (p *profilingEphemeralContainerApi) GetProfilingPod(targetPod *v1.Pod, containerName string, ctx context.Context, timeout time.Duration) (*v1.Pod, error) {
+	var pod *v1.Pod
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := wait.PollUntilContextTimeout(ctx, 1*time.Second, timeout, true,
+		func(ctx context.Context) (bool, error) {
+			var err error
+			pod, err = p.connectionInfo.ClientSet.
+				CoreV1().
+				Pods(targetPod.Namespace).
+				Get(ctx, targetPod.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+
+			for _, status := range pod.Status.EphemeralContainerStatuses {
+				if status.Name != containerName {
+					continue
+				}
+				switch {
+				case status.State.Running != nil, status.State.Terminated != nil:
+					return true, nil
+				case status.State.Waiting != nil && isFailedWaitingReason(status.State.Waiting.Reason):
+					return false, errors.Errorf("profiling ephemeral container failed: %s", status.State.Waiting.Message)
+				}
+			}
+
+			return false, nil
+		})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pod, nil
+}
+
+func (p *profilingEphemeralContainerApi) FindProfilingPod(namespace string, id string, ctx context.Context) (*v1.Pod, error) {
+	pods, err := p.connectionInfo.ClientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list the pods for the profiling ephemeral container")
+	}
+
+	containerName := job.EphemeralContainerName(id)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		for _, status := range pod.Status.EphemeralContainerStatuses {
+			if status.Name != containerName {
+				continue
+			}
+			// an ephemeral container cannot be restarted, so its results cannot be retrieved once it ended
+			if status.State.Running == nil {
+				return nil, errors.Errorf("the profiling ephemeral container %s of pod %s is not running", containerName, pod.Name)
+			}
+			return pod, nil
+		}
+	}
+	return nil, nil
+}
+
+// isFailedWaitingReason returns true if the waiting reason of a container means that it will not be started
+func isFailedWaitingReason(reason string) bool {
+	switch reason {
+	case "ErrImagePull", "ImagePullBackOff", "InvalidImageName", "CreateContainerConfigError", "CreateContainerError":
+		return true
+	}
+	return false
+}
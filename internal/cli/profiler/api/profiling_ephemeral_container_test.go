@@ -0,0 +1,211 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func newProfilingEphemeralContainerApi(objects ...runtime.Object) (ProfilingEphemeralContainerApi, *testclient.Clientset) {
+	clientSet := testclient.NewSimpleClientset(objects...)
+	return NewProfilingEphemeralContainerApi(kubernetes.ConnectionInfo{
+		ClientSet:  clientSet,
+		RestConfig: &rest.Config{},
+		Namespace:  "Namespace",
+	}), clientSet
+}
+
+func ephemeralTargetPod(statuses ...v1.ContainerStatus) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "PodName", Namespace: "Namespace", UID: "UID"},
+		Spec: v1.PodSpec{
+			NodeName:   "NodeName",
+			Containers: []v1.Container{{Name: "app"}},
+		},
+		Status: v1.PodStatus{
+			Phase:                      v1.PodRunning,
+			EphemeralContainerStatuses: statuses,
+		},
+	}
+}
+
+func Test_profilingEphemeralContainerApi_AddProfilingEphemeralContainer(t *testing.T) {
+	cfg := func(dryRun bool) *config.ProfilerConfig {
+		return &config.ProfilerConfig{
+			Target: &config.TargetConfig{
+				ContainerName:    "app",
+				ContainerID:      "containerd://1234",
+				ContainerRuntime: api.Containerd,
+				Language:         api.Go,
+				ProfilingTool:    api.Perf,
+				OutputType:       api.FlameGraph,
+				DryRun:           dryRun,
+			},
+			Job: &config.JobConfig{},
+		}
+	}
+
+	tests := []struct {
+		name string
+		cfg  *config.ProfilerConfig
+		then func(t *testing.T, id, containerName string, err error, clientSet *testclient.Clientset)
+	}{
+		{
+			name: "should add the profiling ephemeral container to the target pod",
+			cfg:  cfg(false),
+			then: func(t *testing.T, id, containerName string, err error, clientSet *testclient.Clientset) {
+				require.NoError(t, err)
+				assert.NotEmpty(t, id)
+				assert.Equal(t, "kubectl-prof-"+id, containerName)
+
+				pod, err := clientSet.CoreV1().Pods("Namespace").Get(context.TODO(), "PodName", metav1.GetOptions{})
+				require.NoError(t, err)
+				require.Len(t, pod.Spec.EphemeralContainers, 1)
+				assert.Equal(t, containerName, pod.Spec.EphemeralContainers[0].Name)
+				assert.Equal(t, "app", pod.Spec.EphemeralContainers[0].TargetContainerName)
+			},
+		},
+		{
+			name: "should not add the profiling ephemeral container when dry run",
+			cfg:  cfg(true),
+			then: func(t *testing.T, id, containerName string, err error, clientSet *testclient.Clientset) {
+				require.NoError(t, err)
+				assert.Empty(t, containerName)
+
+				pod, err := clientSet.CoreV1().Pods("Namespace").Get(context.TODO(), "PodName", metav1.GetOptions{})
+				require.NoError(t, err)
+				assert.Empty(t, pod.Spec.EphemeralContainers)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			targetPod := ephemeralTargetPod()
+			ephemeralApi, clientSet := newProfilingEphemeralContainerApi(targetPod)
+
+			// When
+			id, containerName, err := ephemeralApi.AddProfilingEphemeralContainer(targetPod, tt.cfg, context.TODO())
+
+			// Then
+			tt.then(t, id, containerName, err, clientSet)
+		})
+	}
+}
+
+func Test_profilingEphemeralContainerApi_GetProfilingPod(t *testing.T) {
+	tests := []struct {
+		name      string
+		targetPod *v1.Pod
+		then      func(t *testing.T, pod *v1.Pod, err error)
+	}{
+		{
+			name: "should get the target pod when the profiling ephemeral container is running",
+			targetPod: ephemeralTargetPod(v1.ContainerStatus{
+				Name:  "kubectl-prof-ID",
+				State: v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+			}),
+			then: func(t *testing.T, pod *v1.Pod, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, "PodName", pod.Name)
+			},
+		},
+		{
+			name: "should fail when the image of the profiling ephemeral container cannot be pulled",
+			targetPod: ephemeralTargetPod(v1.ContainerStatus{
+				Name:  "kubectl-prof-ID",
+				State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ErrImagePull", Message: "image not found"}},
+			}),
+			then: func(t *testing.T, pod *v1.Pod, err error) {
+				require.Error(t, err)
+				assert.EqualError(t, err, "profiling ephemeral container failed: image not found")
+			},
+		},
+		{
+			name:      "should fail when the profiling ephemeral container is not started in time",
+			targetPod: ephemeralTargetPod(),
+			then: func(t *testing.T, pod *v1.Pod, err error) {
+				require.Error(t, err)
+				assert.Nil(t, pod)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			ephemeralApi, _ := newProfilingEphemeralContainerApi(tt.targetPod)
+
+			// When
+			pod, err := ephemeralApi.GetProfilingPod(tt.targetPod, "kubectl-prof-ID", context.TODO(), 10*time.Millisecond)
+
+			// Then
+			tt.then(t, pod, err)
+		})
+	}
+}
+
+func Test_profilingEphemeralContainerApi_FindProfilingPod(t *testing.T) {
+	tests := []struct {
+		name      string
+		targetPod *v1.Pod
+		then      func(t *testing.T, pod *v1.Pod, err error)
+	}{
+		{
+			name: "should find the pod holding the running profiling ephemeral container",
+			targetPod: ephemeralTargetPod(v1.ContainerStatus{
+				Name:  "kubectl-prof-ID",
+				State: v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+			}),
+			then: func(t *testing.T, pod *v1.Pod, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, pod)
+				assert.Equal(t, "PodName", pod.Name)
+			},
+		},
+		{
+			name: "should fail when the profiling ephemeral container has ended",
+			targetPod: ephemeralTargetPod(v1.ContainerStatus{
+				Name:  "kubectl-prof-ID",
+				State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{}},
+			}),
+			then: func(t *testing.T, pod *v1.Pod, err error) {
+				require.EqualError(t, err, "the profiling ephemeral container kubectl-prof-ID of pod PodName is not running")
+				assert.Nil(t, pod)
+			},
+		},
+		{
+			name: "should not find any pod when the profiling ephemeral container is of another session",
+			targetPod: ephemeralTargetPod(v1.ContainerStatus{
+				Name:  "kubectl-prof-OTHER",
+				State: v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+			}),
+			then: func(t *testing.T, pod *v1.Pod, err error) {
+				require.NoError(t, err)
+				assert.Nil(t, pod)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			ephemeralApi, _ := newProfilingEphemeralContainerApi(tt.targetPod)
+
+			// When
+			pod, err := ephemeralApi.FindProfilingPod("Namespace", "ID", context.TODO())
+
+			// Then
+			tt.then(t, pod, err)
+		})
+	}
+}
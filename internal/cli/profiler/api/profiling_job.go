@@ -3,9 +3,9 @@ package api
 import (
 	"context"
 	"fmt"
-	"os"
 	"time"
 
+	"github.com/josepdcs/kubectl-prof/internal/cli"
 	"github.com/josepdcs/kubectl-prof/internal/cli/config"
 	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes"
 	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes/job"
@@ -27,6 +27,8 @@ type ProfilingJobApi interface {
 	GetProfilingContainerName() string
 	// DeleteProfilingJob deletes the previous created profiling job
 	DeleteProfilingJob(*batchv1.Job, context.Context) error
+	// GetProfilingJobs returns the profiling jobs of the given namespace, or of all namespaces if it is empty
+	GetProfilingJobs(context.Context, string) ([]batchv1.Job, error)
 }
 
 // profilingJobApi implements ProfilingJobApi and wraps kubernetes.ConnectionInfo
@@ -50,6 +52,10 @@ func (p *profilingJobApi) CreateProfilingJob(targetPod *v1.Pod, cfg *config.Prof
 	if err != nil {
 		return "", nil, errors.Wrap(err, "unable to create job")
 	}
+	profilingJob, err = job.ApplyTemplate(profilingJob, cfg.Job.Template)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "unable to apply the job template")
+	}
 
 	if cfg.Target.DryRun {
 		err = printJob(profilingJob)
@@ -71,12 +77,12 @@ func printJob(job *batchv1.Job) error {
 		Yaml: true,
 	})
 
-	return encoder.Encode(job, os.Stdout)
+	return encoder.Encode(job, cli.Out())
 }
 
 func (p *profilingJobApi) GetProfilingPod(cfg *config.ProfilerConfig, ctx context.Context, timeout time.Duration) (*v1.Pod, error) {
 	var pod *v1.Pod
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	err := wait.PollUntilContextTimeout(ctx, 1*time.Second, timeout, true,
@@ -128,3 +134,15 @@ func (p *profilingJobApi) DeleteProfilingJob(job *batchv1.Job, ctx context.Conte
 			PropagationPolicy: new(metav1.DeletePropagationForeground),
 		})
 }
+
+func (p *profilingJobApi) GetProfilingJobs(ctx context.Context, namespace string) ([]batchv1.Job, error) {
+	jobList, err := p.connectionInfo.ClientSet.
+		BatchV1().
+		Jobs(namespace).
+		List(ctx, metav1.ListOptions{LabelSelector: job.LabelID})
+	if err != nil {
+		return nil, err
+	}
+
+	return jobList.Items, nil
+}
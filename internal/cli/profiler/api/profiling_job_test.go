@@ -460,3 +460,45 @@ func Test_profilingJobAdapter_DeleteProfilingJob(t *testing.T) {
 
 	require.NoError(t, result)
 }
+
+func Test_profilingJobAdapter_GetProfilingJobs(t *testing.T) {
+	// Given
+	profilingJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kubectl-prof-perf-1",
+			Namespace: "Namespace",
+			Labels:    map[string]string{job.LabelID: "1"},
+		},
+	}
+	otherProfilingJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kubectl-prof-perf-2",
+			Namespace: "OtherNamespace",
+			Labels:    map[string]string{job.LabelID: "2"},
+		},
+	}
+	otherJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "other",
+			Namespace: "Namespace",
+		},
+	}
+	jobApi := NewProfilingJobApi(
+		kubernetes.ConnectionInfo{
+			ClientSet:  testclient.NewSimpleClientset(profilingJob, otherProfilingJob, otherJob),
+			RestConfig: &rest.Config{},
+			Namespace:  "Namespace",
+		},
+	)
+
+	// When
+	jobs, err := jobApi.GetProfilingJobs(context.TODO(), "Namespace")
+	allJobs, errAll := jobApi.GetProfilingJobs(context.TODO(), "")
+
+	// Then
+	require.NoError(t, err)
+	require.NoError(t, errAll)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "kubectl-prof-perf-1", jobs[0].Name)
+	assert.Len(t, allJobs, 2)
+}
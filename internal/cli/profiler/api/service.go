@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (p *podApi) GetPodsByService(ctx context.Context, namespace, serviceName, zone, port string) ([]v1.Pod, error) {
+	// be sure that the service exists in order to give a meaningful error otherwise
+	_, err := p.connectionInfo.ClientSet.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	endpointSlices, err := p.connectionInfo.ClientSet.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + serviceName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var pods []v1.Pod
+	seen := make(map[string]bool)
+	for _, slice := range endpointSlices.Items {
+		if !hasEndpointPort(slice, port) {
+			continue
+		}
+		for _, endpoint := range slice.Endpoints {
+			if !isServingEndpoint(endpoint, zone) || endpoint.TargetRef == nil || endpoint.TargetRef.Kind != "Pod" {
+				continue
+			}
+			if seen[endpoint.TargetRef.Name] {
+				continue
+			}
+			seen[endpoint.TargetRef.Name] = true
+
+			pod, err := p.GetPod(ctx, endpoint.TargetRef.Name, namespace)
+			if apierrors.IsNotFound(err) {
+				// the pod was deleted since the endpoint slice was listed
+				log.Warnf("Pod %s of service %s was not found, it is skipped", endpoint.TargetRef.Name, serviceName)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			pods = append(pods, *pod)
+		}
+	}
+
+	return pods, nil
+}
+
+// hasEndpointPort returns true if the endpoint slice exposes the given port, given either by its name or its number.
+// An empty port matches any endpoint slice.
+func hasEndpointPort(slice discoveryv1.EndpointSlice, port string) bool {
+	if port == "" {
+		return true
+	}
+
+	for _, p := range slice.Ports {
+		if p.Name != nil && *p.Name == port {
+			return true
+		}
+		if p.Port != nil && strconv.Itoa(int(*p.Port)) == port {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isServingEndpoint returns true if the endpoint is currently receiving traffic, that is, it is ready and not terminating.
+// When a zone is given, the endpoint must also be located in that zone.
+func isServingEndpoint(endpoint discoveryv1.Endpoint, zone string) bool {
+	// a nil ready condition is an unknown state that consumers should interpret as ready
+	if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+		return false
+	}
+	if endpoint.Conditions.Terminating != nil && *endpoint.Conditions.Terminating {
+		return false
+	}
+	if zone != "" && (endpoint.Zone == nil || *endpoint.Zone != zone) {
+		return false
+	}
+
+	return true
+}
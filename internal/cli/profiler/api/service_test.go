@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func serviceEndpoint(podName, zone string, ready, terminating bool) discoveryv1.Endpoint {
+	return discoveryv1.Endpoint{
+		Addresses: []string{"10.0.0.1"},
+		Conditions: discoveryv1.EndpointConditions{
+			Ready:       new(ready),
+			Terminating: new(terminating),
+		},
+		Zone:      new(zone),
+		TargetRef: &v1.ObjectReference{Kind: "Pod", Name: podName, Namespace: "Namespace"},
+	}
+}
+
+func Test_podApi_GetPodsByService(t *testing.T) {
+	service := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "checkout", Namespace: "Namespace"}}
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "checkout-abc",
+			Namespace: "Namespace",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "checkout"},
+		},
+		Ports: []discoveryv1.EndpointPort{{Name: new("http"), Port: new(int32(8080))}},
+		Endpoints: []discoveryv1.Endpoint{
+			serviceEndpoint("ready-a", "zone-a", true, false),
+			serviceEndpoint("ready-b", "zone-b", true, false),
+			serviceEndpoint("unready", "zone-a", false, false),
+			serviceEndpoint("terminating", "zone-a", true, true),
+			serviceEndpoint("deleted", "zone-a", true, false),
+		},
+	}
+	otherSlice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "other-abc",
+			Namespace: "Namespace",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "other"},
+		},
+		Endpoints: []discoveryv1.Endpoint{serviceEndpoint("other", "zone-a", true, false)},
+	}
+	objects := func() PodApi {
+		return newWorkloadPodApi(service, slice, otherSlice,
+			workloadPod("ready-a", nil, nil),
+			workloadPod("ready-b", nil, nil),
+			workloadPod("unready", nil, nil),
+			workloadPod("terminating", nil, nil),
+			workloadPod("other", nil, nil),
+		)
+	}
+
+	tests := []struct {
+		name    string
+		service string
+		zone    string
+		port    string
+		then    func(t *testing.T, pods []v1.Pod, err error)
+	}{
+		{
+			name:    "should get only the ready and not terminating pods, skipping the deleted ones",
+			service: "checkout",
+			then: func(t *testing.T, pods []v1.Pod, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, []string{"ready-a", "ready-b"}, podNames(pods))
+			},
+		},
+		{
+			name:    "should filter by zone",
+			service: "checkout",
+			zone:    "zone-b",
+			then: func(t *testing.T, pods []v1.Pod, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, []string{"ready-b"}, podNames(pods))
+			},
+		},
+		{
+			name:    "should filter by port name",
+			service: "checkout",
+			port:    "http",
+			then: func(t *testing.T, pods []v1.Pod, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, []string{"ready-a", "ready-b"}, podNames(pods))
+			},
+		},
+		{
+			name:    "should filter by port number",
+			service: "checkout",
+			port:    "9090",
+			then: func(t *testing.T, pods []v1.Pod, err error) {
+				require.NoError(t, err)
+				assert.Empty(t, pods)
+			},
+		},
+		{
+			name:    "should fail when service does not exist",
+			service: "unknown",
+			then: func(t *testing.T, pods []v1.Pod, err error) {
+				require.Error(t, err)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pods, err := objects().GetPodsByService(context.TODO(), "Namespace", tt.service, tt.zone, tt.port)
+			tt.then(t, pods, err)
+		})
+	}
+}
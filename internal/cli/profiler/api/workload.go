@@ -0,0 +1,256 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// revisionAnnotation is the annotation set by the deployment controller on each ReplicaSet with its revision number.
+	revisionAnnotation = "deployment.kubernetes.io/revision"
+	// controllerRevisionHashLabel is the label set by the statefulset and daemonset controllers on each pod
+	// (and ControllerRevision) with the hash of the revision it belongs to.
+	controllerRevisionHashLabel = appsv1.ControllerRevisionHashLabelKey
+)
+
+func (p *podApi) GetPodsByWorkload(ctx context.Context, namespace string, kind config.TargetKind, name string) ([]v1.Pod, *v1.PodTemplateSpec, error) {
+	switch kind {
+	case config.Deployment:
+		return p.getDeploymentPods(ctx, namespace, name)
+	case config.StatefulSet:
+		return p.getStatefulSetPods(ctx, namespace, name)
+	case config.DaemonSet:
+		return p.getDaemonSetPods(ctx, namespace, name)
+	case config.Job:
+		return p.getJobPods(ctx, namespace, name)
+	case config.CronJob:
+		return p.getCronJobPods(ctx, namespace, name)
+	default:
+		return nil, nil, errors.Errorf("unsupported workload kind %q", kind)
+	}
+}
+
+// getDeploymentPods returns the pods owned by the current ReplicaSet of the deployment.
+func (p *podApi) getDeploymentPods(ctx context.Context, namespace, name string) ([]v1.Pod, *v1.PodTemplateSpec, error) {
+	deployment, err := p.connectionInfo.ClientSet.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "invalid selector for deployment %s", name)
+	}
+
+	replicaSets, err := p.connectionInfo.ClientSet.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	replicaSet := currentReplicaSet(deployment, replicaSets.Items)
+	if replicaSet == nil {
+		return nil, nil, errors.New(fmt.Sprintf("No current ReplicaSet found for deployment %s in namespace %s", name, namespace))
+	}
+
+	pods, err := p.getControlledPods(ctx, namespace, selector.String(), replicaSet.UID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pods, &replicaSet.Spec.Template, nil
+}
+
+// getStatefulSetPods returns the pods of the statefulset belonging to its most recent revision.
+func (p *podApi) getStatefulSetPods(ctx context.Context, namespace, name string) ([]v1.Pod, *v1.PodTemplateSpec, error) {
+	statefulSet, err := p.connectionInfo.ClientSet.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(statefulSet.Spec.Selector)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "invalid selector for statefulset %s", name)
+	}
+
+	pods, err := p.getControlledPods(ctx, namespace, selector.String(), statefulSet.UID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	revision := statefulSet.Status.UpdateRevision
+	if revision == "" {
+		revision = statefulSet.Status.CurrentRevision
+	}
+
+	return filterByRevisionHash(pods, revision), &statefulSet.Spec.Template, nil
+}
+
+// getDaemonSetPods returns the pods of the daemonset belonging to its most recent ControllerRevision.
+func (p *podApi) getDaemonSetPods(ctx context.Context, namespace, name string) ([]v1.Pod, *v1.PodTemplateSpec, error) {
+	daemonSet, err := p.connectionInfo.ClientSet.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(daemonSet.Spec.Selector)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "invalid selector for daemonset %s", name)
+	}
+
+	revisions, err := p.connectionInfo.ClientSet.AppsV1().ControllerRevisions(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pods, err := p.getControlledPods(ctx, namespace, selector.String(), daemonSet.UID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var revisionHash string
+	if revision := latestControllerRevision(daemonSet.UID, revisions.Items); revision != nil {
+		revisionHash = revision.Labels[controllerRevisionHashLabel]
+	}
+
+	return filterByRevisionHash(pods, revisionHash), &daemonSet.Spec.Template, nil
+}
+
+// getJobPods returns the pods owned by the job.
+func (p *podApi) getJobPods(ctx context.Context, namespace, name string) ([]v1.Pod, *v1.PodTemplateSpec, error) {
+	job, err := p.connectionInfo.ClientSet.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pods, err := p.getJobControlledPods(ctx, job)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pods, &job.Spec.Template, nil
+}
+
+// getCronJobPods returns the pods owned by the active jobs of the cronjob.
+func (p *podApi) getCronJobPods(ctx context.Context, namespace, name string) ([]v1.Pod, *v1.PodTemplateSpec, error) {
+	cronJob, err := p.connectionInfo.ClientSet.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(cronJob.Status.Active) == 0 {
+		return nil, nil, errors.New(fmt.Sprintf("No active jobs found for cronjob %s in namespace %s", name, namespace))
+	}
+
+	var pods []v1.Pod
+	for _, ref := range cronJob.Status.Active {
+		job, err := p.connectionInfo.ClientSet.BatchV1().Jobs(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		jobPods, err := p.getJobControlledPods(ctx, job)
+		if err != nil {
+			return nil, nil, err
+		}
+		pods = append(pods, jobPods...)
+	}
+
+	return pods, &cronJob.Spec.JobTemplate.Spec.Template, nil
+}
+
+// getJobControlledPods returns the pods matching the job selector and controlled by the job.
+func (p *podApi) getJobControlledPods(ctx context.Context, job *batchv1.Job) ([]v1.Pod, error) {
+	selector, err := metav1.LabelSelectorAsSelector(job.Spec.Selector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid selector for job %s", job.Name)
+	}
+
+	return p.getControlledPods(ctx, job.Namespace, selector.String(), job.UID)
+}
+
+// getControlledPods returns the pods matching the label selector whose controller is the owner given by its UID.
+func (p *podApi) getControlledPods(ctx context.Context, namespace, labelSelector string, ownerUID types.UID) ([]v1.Pod, error) {
+	pods, err := p.GetPodsByLabelSelector(ctx, namespace, labelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	controlled := make([]v1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if owner := metav1.GetControllerOf(&pod); owner != nil && owner.UID == ownerUID {
+			controlled = append(controlled, pod)
+		}
+	}
+
+	return controlled, nil
+}
+
+// currentReplicaSet returns the ReplicaSet controlled by the deployment whose revision matches the deployment one.
+// If no revision matches, the ReplicaSet with the highest revision is returned.
+func currentReplicaSet(deployment *appsv1.Deployment, replicaSets []appsv1.ReplicaSet) *appsv1.ReplicaSet {
+	var current *appsv1.ReplicaSet
+	var currentRevision int64 = -1
+	for i := range replicaSets {
+		rs := &replicaSets[i]
+		if owner := metav1.GetControllerOf(rs); owner == nil || owner.UID != deployment.UID {
+			continue
+		}
+
+		if rs.Annotations[revisionAnnotation] != "" && rs.Annotations[revisionAnnotation] == deployment.Annotations[revisionAnnotation] {
+			return rs
+		}
+
+		revision, err := strconv.ParseInt(rs.Annotations[revisionAnnotation], 10, 64)
+		if err != nil {
+			revision = 0
+		}
+		if revision > currentRevision {
+			current = rs
+			currentRevision = revision
+		}
+	}
+
+	return current
+}
+
+// latestControllerRevision returns the ControllerRevision controlled by the owner given by its UID with the highest revision.
+func latestControllerRevision(ownerUID types.UID, revisions []appsv1.ControllerRevision) *appsv1.ControllerRevision {
+	var latest *appsv1.ControllerRevision
+	for i := range revisions {
+		revision := &revisions[i]
+		if owner := metav1.GetControllerOf(revision); owner == nil || owner.UID != ownerUID {
+			continue
+		}
+		if latest == nil || revision.Revision > latest.Revision {
+			latest = revision
+		}
+	}
+
+	return latest
+}
+
+// filterByRevisionHash returns the pods labeled with the given controller revision hash.
+// If the hash is empty, the pods are returned unfiltered.
+func filterByRevisionHash(pods []v1.Pod, revisionHash string) []v1.Pod {
+	if revisionHash == "" {
+		return pods
+	}
+
+	filtered := make([]v1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if pod.Labels[controllerRevisionHashLabel] == revisionHash {
+			filtered = append(filtered, pod)
+		}
+	}
+
+	return filtered
+}
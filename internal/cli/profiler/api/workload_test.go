@@ -0,0 +1,263 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func newWorkloadPodApi(objects ...runtime.Object) PodApi {
+	return NewPodApi(kubernetes.ConnectionInfo{
+		ClientSet:  testclient.NewSimpleClientset(objects...),
+		RestConfig: &rest.Config{},
+		Namespace:  "Namespace",
+	})
+}
+
+func controllerRef(kind, name string, uid types.UID) []metav1.OwnerReference {
+	return []metav1.OwnerReference{{Kind: kind, Name: name, UID: uid, Controller: new(true)}}
+}
+
+func workloadPod(name string, labels map[string]string, owners []metav1.OwnerReference) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "Namespace",
+			Labels:          labels,
+			OwnerReferences: owners,
+		},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}
+}
+
+func workloadTemplate(containers ...string) v1.PodTemplateSpec {
+	template := v1.PodTemplateSpec{}
+	for _, c := range containers {
+		template.Spec.Containers = append(template.Spec.Containers, v1.Container{Name: c})
+	}
+	return template
+}
+
+func podNames(pods []v1.Pod) []string {
+	names := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	return names
+}
+
+func Test_podApi_GetPodsByWorkload(t *testing.T) {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "checkout"}}
+	labels := map[string]string{"app": "checkout"}
+
+	tests := []struct {
+		name  string
+		given func() (PodApi, config.TargetKind, string)
+		then  func(t *testing.T, pods []v1.Pod, template *v1.PodTemplateSpec, err error)
+	}{
+		{
+			name: "should get the pods of the current replicaset of a deployment",
+			given: func() (PodApi, config.TargetKind, string) {
+				deployment := &appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "checkout", Namespace: "Namespace", UID: "deploy-uid",
+						Annotations: map[string]string{revisionAnnotation: "2"},
+					},
+					Spec: appsv1.DeploymentSpec{Selector: selector},
+				}
+				oldRs := &appsv1.ReplicaSet{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "checkout-old", Namespace: "Namespace", UID: "old-rs-uid", Labels: labels,
+						Annotations:     map[string]string{revisionAnnotation: "1"},
+						OwnerReferences: controllerRef("Deployment", "checkout", "deploy-uid"),
+					},
+					Spec: appsv1.ReplicaSetSpec{Template: workloadTemplate("old")},
+				}
+				newRs := &appsv1.ReplicaSet{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "checkout-new", Namespace: "Namespace", UID: "new-rs-uid", Labels: labels,
+						Annotations:     map[string]string{revisionAnnotation: "2"},
+						OwnerReferences: controllerRef("Deployment", "checkout", "deploy-uid"),
+					},
+					Spec: appsv1.ReplicaSetSpec{Template: workloadTemplate("app")},
+				}
+				return newWorkloadPodApi(deployment, oldRs, newRs,
+					workloadPod("old-pod", labels, controllerRef("ReplicaSet", "checkout-old", "old-rs-uid")),
+					workloadPod("new-pod", labels, controllerRef("ReplicaSet", "checkout-new", "new-rs-uid")),
+				), config.Deployment, "checkout"
+			},
+			then: func(t *testing.T, pods []v1.Pod, template *v1.PodTemplateSpec, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, []string{"new-pod"}, podNames(pods))
+				assert.Equal(t, "app", template.Spec.Containers[0].Name)
+			},
+		},
+		{
+			name: "should fail when deployment has no replicaset",
+			given: func() (PodApi, config.TargetKind, string) {
+				deployment := &appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "checkout", Namespace: "Namespace", UID: "deploy-uid"},
+					Spec:       appsv1.DeploymentSpec{Selector: selector},
+				}
+				return newWorkloadPodApi(deployment), config.Deployment, "checkout"
+			},
+			then: func(t *testing.T, pods []v1.Pod, template *v1.PodTemplateSpec, err error) {
+				require.Error(t, err)
+				assert.EqualError(t, err, "No current ReplicaSet found for deployment checkout in namespace Namespace")
+			},
+		},
+		{
+			name: "should fail when deployment does not exist",
+			given: func() (PodApi, config.TargetKind, string) {
+				return newWorkloadPodApi(), config.Deployment, "checkout"
+			},
+			then: func(t *testing.T, pods []v1.Pod, template *v1.PodTemplateSpec, err error) {
+				require.Error(t, err)
+			},
+		},
+		{
+			name: "should get the pods of the update revision of a statefulset",
+			given: func() (PodApi, config.TargetKind, string) {
+				statefulSet := &appsv1.StatefulSet{
+					ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "Namespace", UID: "sts-uid"},
+					Spec:       appsv1.StatefulSetSpec{Selector: selector, Template: workloadTemplate("db")},
+					Status:     appsv1.StatefulSetStatus{CurrentRevision: "db-1", UpdateRevision: "db-2"},
+				}
+				return newWorkloadPodApi(statefulSet,
+					workloadPod("db-0", map[string]string{"app": "checkout", controllerRevisionHashLabel: "db-2"}, controllerRef("StatefulSet", "db", "sts-uid")),
+					workloadPod("db-1", map[string]string{"app": "checkout", controllerRevisionHashLabel: "db-1"}, controllerRef("StatefulSet", "db", "sts-uid")),
+					workloadPod("other", labels, nil),
+				), config.StatefulSet, "db"
+			},
+			then: func(t *testing.T, pods []v1.Pod, template *v1.PodTemplateSpec, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, []string{"db-0"}, podNames(pods))
+				assert.Equal(t, "db", template.Spec.Containers[0].Name)
+			},
+		},
+		{
+			name: "should get the pods of the latest controller revision of a daemonset",
+			given: func() (PodApi, config.TargetKind, string) {
+				daemonSet := &appsv1.DaemonSet{
+					ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "Namespace", UID: "ds-uid"},
+					Spec:       appsv1.DaemonSetSpec{Selector: selector, Template: workloadTemplate("agent")},
+				}
+				oldRevision := &appsv1.ControllerRevision{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "agent-aaa", Namespace: "Namespace",
+						Labels:          map[string]string{"app": "checkout", controllerRevisionHashLabel: "aaa"},
+						OwnerReferences: controllerRef("DaemonSet", "agent", "ds-uid"),
+					},
+					Revision: 1,
+				}
+				newRevision := &appsv1.ControllerRevision{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "agent-bbb", Namespace: "Namespace",
+						Labels:          map[string]string{"app": "checkout", controllerRevisionHashLabel: "bbb"},
+						OwnerReferences: controllerRef("DaemonSet", "agent", "ds-uid"),
+					},
+					Revision: 2,
+				}
+				return newWorkloadPodApi(daemonSet, oldRevision, newRevision,
+					workloadPod("agent-x", map[string]string{"app": "checkout", controllerRevisionHashLabel: "aaa"}, controllerRef("DaemonSet", "agent", "ds-uid")),
+					workloadPod("agent-y", map[string]string{"app": "checkout", controllerRevisionHashLabel: "bbb"}, controllerRef("DaemonSet", "agent", "ds-uid")),
+				), config.DaemonSet, "agent"
+			},
+			then: func(t *testing.T, pods []v1.Pod, template *v1.PodTemplateSpec, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, []string{"agent-y"}, podNames(pods))
+				assert.Equal(t, "agent", template.Spec.Containers[0].Name)
+			},
+		},
+		{
+			name: "should get the pods of a job",
+			given: func() (PodApi, config.TargetKind, string) {
+				job := &batchv1.Job{
+					ObjectMeta: metav1.ObjectMeta{Name: "migration", Namespace: "Namespace", UID: "job-uid"},
+					Spec:       batchv1.JobSpec{Selector: selector, Template: workloadTemplate("migrate")},
+				}
+				return newWorkloadPodApi(job,
+					workloadPod("migration-abc", labels, controllerRef("Job", "migration", "job-uid")),
+				), config.Job, "migration"
+			},
+			then: func(t *testing.T, pods []v1.Pod, template *v1.PodTemplateSpec, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, []string{"migration-abc"}, podNames(pods))
+				assert.Equal(t, "migrate", template.Spec.Containers[0].Name)
+			},
+		},
+		{
+			name: "should get the pods of the active jobs of a cronjob",
+			given: func() (PodApi, config.TargetKind, string) {
+				cronJob := &batchv1.CronJob{
+					ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "Namespace", UID: "cj-uid"},
+					Spec: batchv1.CronJobSpec{
+						JobTemplate: batchv1.JobTemplateSpec{
+							Spec: batchv1.JobSpec{Template: workloadTemplate("report")},
+						},
+					},
+					Status: batchv1.CronJobStatus{Active: []v1.ObjectReference{{Name: "nightly-123", Namespace: "Namespace"}}},
+				}
+				job := &batchv1.Job{
+					ObjectMeta: metav1.ObjectMeta{Name: "nightly-123", Namespace: "Namespace", UID: "job-uid"},
+					Spec:       batchv1.JobSpec{Selector: selector},
+				}
+				return newWorkloadPodApi(cronJob, job,
+					workloadPod("nightly-123-xyz", labels, controllerRef("Job", "nightly-123", "job-uid")),
+				), config.CronJob, "nightly"
+			},
+			then: func(t *testing.T, pods []v1.Pod, template *v1.PodTemplateSpec, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, []string{"nightly-123-xyz"}, podNames(pods))
+				assert.Equal(t, "report", template.Spec.Containers[0].Name)
+			},
+		},
+		{
+			name: "should fail when cronjob has no active jobs",
+			given: func() (PodApi, config.TargetKind, string) {
+				cronJob := &batchv1.CronJob{
+					ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "Namespace"},
+				}
+				return newWorkloadPodApi(cronJob), config.CronJob, "nightly"
+			},
+			then: func(t *testing.T, pods []v1.Pod, template *v1.PodTemplateSpec, err error) {
+				require.Error(t, err)
+				assert.EqualError(t, err, "No active jobs found for cronjob nightly in namespace Namespace")
+			},
+		},
+		{
+			name: "should fail when kind is not a workload",
+			given: func() (PodApi, config.TargetKind, string) {
+				return newWorkloadPodApi(), config.Pod, "my-pod"
+			},
+			then: func(t *testing.T, pods []v1.Pod, template *v1.PodTemplateSpec, err error) {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "unsupported workload kind")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			podApi, kind, name := tt.given()
+
+			// When
+			pods, template, err := podApi.GetPodsByWorkload(context.TODO(), "Namespace", kind, name)
+
+			// Then
+			tt.then(t, pods, template, err)
+		})
+	}
+}
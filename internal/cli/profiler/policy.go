@@ -0,0 +1,57 @@
+package profiler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes"
+	"github.com/josepdcs/kubectl-prof/internal/cli/profiler/api"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+)
+
+// policy checks the profiling policy given by the annotations of the target pods and of their namespaces
+type policy struct {
+	podApi api.PodApi
+	target *config.TargetConfig
+	// namespaces are the annotations of the namespaces already read
+	namespaces map[string]map[string]string
+}
+
+// newPolicy returns the policy checking the targets of the given profiling configuration
+func (p *Profiler) newPolicy(cfg *config.ProfilerConfig) *policy {
+	return &policy{
+		podApi:     p.podApi,
+		target:     cfg.Target,
+		namespaces: map[string]map[string]string{},
+	}
+}
+
+// check returns why the profiling policy of the given pod, or of its namespace, does not allow profiling it, if so.
+// Nothing is checked when the policy is overridden. The pod is refused if the policy of its namespace cannot be read.
+func (c *policy) check(ctx context.Context, pod *v1.Pod) error {
+	if c.target.OverridePolicy || pod == nil {
+		return nil
+	}
+
+	annotations, ok := c.namespaces[pod.Namespace]
+	if !ok {
+		ns, err := c.podApi.GetNamespace(ctx, pod.Namespace)
+		if err != nil {
+			return errors.Errorf("could not read the profiling policy of namespace %s: %v, use --override-policy to profile it anyway",
+				pod.Namespace, err)
+		}
+		annotations = ns.Annotations
+		c.namespaces[pod.Namespace] = annotations
+	}
+
+	err := kubernetes.CheckProfilingPolicy(fmt.Sprintf("namespace %s", pod.Namespace), annotations, c.target.ProfilingTool)
+	if err == nil {
+		err = kubernetes.CheckProfilingPolicy(fmt.Sprintf("pod %s/%s", pod.Namespace, pod.Name), pod.Annotations, c.target.ProfilingTool)
+	}
+	if err != nil {
+		return errors.Errorf("%v, use --override-policy to profile it anyway", err)
+	}
+	return nil
+}
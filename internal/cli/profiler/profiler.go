@@ -3,86 +3,213 @@ package profiler
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/alitto/pond"
 	"github.com/josepdcs/kubectl-prof/internal/cli"
 	"github.com/josepdcs/kubectl-prof/internal/cli/config"
 	"github.com/josepdcs/kubectl-prof/internal/cli/handler"
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes"
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes/job"
 	"github.com/josepdcs/kubectl-prof/internal/cli/profiler/api"
+	"github.com/josepdcs/kubectl-prof/internal/cli/result"
+	"github.com/josepdcs/kubectl-prof/internal/cli/session"
+	"github.com/josepdcs/kubectl-prof/pkg/util/compressor"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// Profiler is a profiler job representation which wraps the api.PodApi, api.ProfilingJobApi
-// and api.ProfilingContainerApi
+// attachTimeout is the maximum time for finding the running agent of a profiling session
+const attachTimeout = 10 * time.Second
+
+// deleteInterruptedJobTimeout is the maximum time for deleting the profiling job of an interrupted profiling
+const deleteInterruptedJobTimeout = 30 * time.Second
+
+// progressTableInterval is the interval for rendering the progress table of the pods profiled in parallel
+const progressTableInterval = 10 * time.Second
+
+// Profiler is a profiler job representation which wraps the api.PodApi, api.ProfilingJobApi,
+// api.ProfilingContainerApi and api.ProfilingEphemeralContainerApi
 type Profiler struct {
-	podApi                api.PodApi
-	profilingJobApi       api.ProfilingJobApi
-	profilingContainerApi api.ProfilingContainerApi
+	podApi                         api.PodApi
+	profilingJobApi                api.ProfilingJobApi
+	profilingContainerApi          api.ProfilingContainerApi
+	profilingEphemeralContainerApi api.ProfilingEphemeralContainerApi
+	// recorder records the session, if requested
+	recorder *session.Recorder
+	// preflightApi checks the profiling can be launched, if given
+	preflightApi api.PreflightApi
+	// stopEarly is closed when the running profilings must finish their current capture early, keeping the partial results
+	stopEarly <-chan struct{}
 }
 
 // New returns a new Profiler
 func New(podApi api.PodApi, profilingJobApi api.ProfilingJobApi,
-	profilingContainerApi api.ProfilingContainerApi) *Profiler {
+	profilingContainerApi api.ProfilingContainerApi, profilingEphemeralContainerApi api.ProfilingEphemeralContainerApi) *Profiler {
 	return &Profiler{
-		podApi:                podApi,
-		profilingJobApi:       profilingJobApi,
-		profilingContainerApi: profilingContainerApi,
+		podApi:                         podApi,
+		profilingJobApi:                profilingJobApi,
+		profilingContainerApi:          profilingContainerApi,
+		profilingEphemeralContainerApi: profilingEphemeralContainerApi,
 	}
 }
 
-// Profile runs all the steps of the profiling from the job creation up to get the profiling result
-func (p *Profiler) Profile(cfg *config.ProfilerConfig) error {
+// WithRecorder sets the recorder of the profiling session, which records the launched jobs and the events of the agents
+func (p *Profiler) WithRecorder(recorder *session.Recorder) *Profiler {
+	p.recorder = recorder
+	return p
+}
+
+// WithPreflightApi sets the checks run before launching the profiling, so that every problem is reported at once
+func (p *Profiler) WithPreflightApi(preflightApi api.PreflightApi) *Profiler {
+	p.preflightApi = preflightApi
+	return p
+}
+
+// WithStopEarly sets the channel closed when the running profilings must finish their current capture early,
+// so that their partial results are still downloaded
+func (p *Profiler) WithStopEarly(stopEarly <-chan struct{}) *Profiler {
+	p.stopEarly = stopEarly
+	return p
+}
+
+// Profile runs all the steps of the profiling from the job creation up to get the profiling result.
+// When the given context is cancelled (e.g. the user interrupts the CLI), the running profiling is stopped
+// and every profiling job launched so far is deleted.
+func (p *Profiler) Profile(ctx context.Context, cfg *config.ProfilerConfig) error {
+	if err := p.preflight(ctx, cfg); err != nil {
+		return err
+	}
+
+	if cfg.Target.Kind.IsWorkload() {
+		pods, template, err := p.podApi.GetPodsByWorkload(ctx, cfg.Target.Namespace, cfg.Target.Kind, cfg.Target.WorkloadName)
+		if err != nil {
+			return targetError(err)
+		}
+
+		if len(pods) == 0 {
+			return cli.NewError(cli.ExitTargetNotFound, errors.New(fmt.Sprintf("No pods found in namespace %s for %s %s", cfg.Target.Namespace, cfg.Target.Kind, cfg.Target.WorkloadName)))
+		}
+
+		// pick the container from the pod template when it has not been given
+		if cfg.Target.ContainerName == "" {
+			cfg.Target.ContainerName = kubernetes.DefaultContainerName(template)
+		}
+
+		return p.profileTargets(ctx, pods, cfg)
+	}
+
+	if cfg.Target.Kind == config.Service {
+		pods, err := p.podApi.GetPodsByService(ctx, cfg.Target.Namespace, cfg.Target.ServiceName,
+			cfg.Target.EndpointZone, cfg.Target.EndpointPort)
+		if err != nil {
+			return targetError(err)
+		}
+
+		if len(pods) == 0 {
+			return cli.NewError(cli.ExitTargetNotFound, errors.New(fmt.Sprintf("No ready endpoints found in namespace %s for service %s", cfg.Target.Namespace, cfg.Target.ServiceName)))
+		}
+
+		return p.profileTargets(ctx, pods, cfg)
+	}
+
+	if cfg.Target.Kind == config.Node {
+		return p.profileNode(ctx, cfg)
+	}
+
 	if cfg.Target.PodName != "" {
-		ctx := context.Background()
 		printer := cli.NewPrinter(cfg.Target.DryRun)
 
 		pod, err := p.podApi.GetPod(ctx, cfg.Target.PodName, cfg.Target.Namespace)
 		if err != nil {
-			return err
+			return targetError(err)
+		}
+		if err := p.newPolicy(cfg).check(ctx, pod); err != nil {
+			return cli.NewError(cli.ExitInvalidArguments, err)
 		}
 
 		return p.profileTarget(ctx, pod, printer, cfg)
 	}
 
 	if cfg.Target.LabelSelector != "" {
-		pods, err := p.podApi.GetPodsByLabelSelector(context.Background(), cfg.Target.Namespace, cfg.Target.LabelSelector)
+		pods, err := p.podApi.GetPodsByLabelSelector(ctx, cfg.Target.Namespace, cfg.Target.LabelSelector)
 		if err != nil {
 			return err
 		}
 
 		if len(pods) == 0 {
-			return errors.New(fmt.Sprintf("No pods found in namespace %s with label selector %s", cfg.Target.Namespace, cfg.Target.LabelSelector))
+			return cli.NewError(cli.ExitTargetNotFound, errors.New(fmt.Sprintf("No pods found in namespace %s with label selector %s", cfg.Target.Namespace, cfg.Target.LabelSelector)))
 		}
 
-		poolSize := cfg.Target.PoolSizeLaunchProfilingJobs
-		if poolSize == 0 {
-			poolSize = len(pods)
-		}
-		pool := pond.New(poolSize, 0, pond.MinWorkers(poolSize))
-		defer pool.StopAndWait()
+		return p.profileTargets(ctx, pods, cfg)
+	}
 
-		// create a task group associated to a context
-		group, _ := pool.GroupContext(context.Background())
+	return cli.NewError(cli.ExitInvalidArguments, errors.New("no target specified"))
+}
 
-		for _, pod := range pods {
-			printer := cli.NewPrinterWithTargetPod(cfg.Target.DryRun, pod.Name)
-			if pod.Status.Phase != v1.PodRunning {
-				printer.Print(fmt.Sprintf("⚠️ Pod %s will be ignored because is not running, it is %s\n", pod.Name, pod.Status.Phase))
-				continue
-			}
-			profilerConfig := cfg.DeepCopy()
-			group.Submit(func() error {
-				return p.profileTarget(context.Background(), &pod, printer, profilerConfig)
-			})
+// preflight checks the profiling can be launched, reporting every problem found at once.
+// The checks which could not be run are noticed. Nothing is checked with a dry run, which launches nothing.
+func (p *Profiler) preflight(ctx context.Context, cfg *config.ProfilerConfig) error {
+	if p.preflightApi == nil || cfg.Target.DryRun {
+		return nil
+	}
+	problems, err := p.preflightApi.Check(ctx, cfg)
+	if err != nil {
+		msg := fmt.Sprintf("the pre-flight checks are incomplete: %v", err)
+		cli.NewPrinter(false).Report(fmt.Sprintf("⚠️ %s\n", msg), cli.Record{Stage: cli.Notice, Session: cfg.Target.Id, Message: msg})
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return cli.NewError(cli.ExitPreflightFailed, errors.Errorf("the profiling cannot be launched:\n- %s", strings.Join(problems, "\n- ")))
+}
+
+// profileTargets profiles in parallel the given pods by using a pool of workers.
+// Pods which are not running, or whose profiling policy does not allow profiling them, are ignored.
+func (p *Profiler) profileTargets(ctx context.Context, pods []v1.Pod, cfg *config.ProfilerConfig) error {
+	poolSize := cfg.Target.PoolSizeLaunchProfilingJobs
+	if poolSize == 0 {
+		poolSize = len(pods)
+	}
+	pool := pond.New(poolSize, 0, pond.MinWorkers(poolSize))
+	defer pool.StopAndWait()
+
+	// create a task group associated to a context
+	group, _ := pool.GroupContext(ctx)
+
+	// the progress of the pods is rendered as a table, since their status lines would overlap
+	table := cli.NewProgressTable(cli.Out())
+	tableCtx, stopTable := context.WithCancel(ctx)
+	defer stopTable()
+	go table.Run(tableCtx, progressTableInterval)
 
+	policy := p.newPolicy(cfg)
+	for _, pod := range pods {
+		printer := cli.NewPrinterWithProgressTable(cfg.Target.DryRun, pod.Name, table)
+		if pod.Status.Phase != v1.PodRunning {
+			printer.Report(fmt.Sprintf("⚠️ Pod %s will be ignored because is not running, it is %s\n", pod.Name, pod.Status.Phase),
+				cli.Record{Stage: cli.PodIgnored, Message: fmt.Sprintf("the pod is not running, it is %s", pod.Status.Phase)})
+			continue
 		}
+		if err := policy.check(ctx, &pod); err != nil {
+			printer.Report(fmt.Sprintf("⚠️ Pod %s will be ignored because %v\n", pod.Name, err),
+				cli.Record{Stage: cli.PodIgnored, Message: err.Error()})
+			continue
+		}
+		profilerConfig := cfg.DeepCopy()
+		group.Submit(func() error {
+			return p.profileTarget(ctx, &pod, printer, profilerConfig)
+		})
 
-		return group.Wait()
 	}
 
-	return errors.New("no target specified")
+	return group.Wait()
 }
 
 // profileTarget runs all the steps of the profiling from the job creation
@@ -90,58 +217,345 @@ func (p *Profiler) Profile(cfg *config.ProfilerConfig) error {
 func (p *Profiler) profileTarget(ctx context.Context, targetPod *v1.Pod, printer cli.Printer, cfg *config.ProfilerConfig) error {
 	err := validatePodAndRetrieveContainerInfo(targetPod, cfg)
 	if err != nil {
-		return err
+		return cli.NewError(cli.ExitTargetNotFound, err)
+	}
+	printer.Report("Verified target pod ... ✔\n", cli.Record{Stage: cli.PodVerified, Message: "container " + cfg.Target.ContainerName})
+
+	return p.launchProfiling(ctx, targetPod, printer, cfg)
+}
+
+// profileNode runs all the steps of the profiling from the job creation
+// up to get the profiling result for every container running on a node
+func (p *Profiler) profileNode(ctx context.Context, cfg *config.ProfilerConfig) error {
+	printer := cli.NewPrinterWithTargetPod(cfg.Target.DryRun, cfg.Target.NodeName)
+
+	pods, err := p.podApi.GetPodsByNode(ctx, cfg.Target.NodeName)
+	if err != nil {
+		return targetError(err)
+	}
+
+	// the whole node is profiled, so every pod running on it must allow it
+	policy := p.newPolicy(cfg)
+	var refusals []string
+	for _, pod := range pods {
+		if pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+		if err := policy.check(ctx, &pod); err != nil {
+			refusals = append(refusals, err.Error())
+		}
+	}
+	if len(refusals) > 0 {
+		return cli.NewError(cli.ExitInvalidArguments, errors.Errorf("node %s cannot be profiled:\n- %s", cfg.Target.NodeName, strings.Join(refusals, "\n- ")))
+	}
+
+	cfg.Target.NodeContainers = kubernetes.ToNodeContainers(pods)
+	if len(cfg.Target.NodeContainers) == 0 {
+		return cli.NewError(cli.ExitTargetNotFound, errors.New(fmt.Sprintf("No running containers found on node %s", cfg.Target.NodeName)))
+	}
+	printer.Report(fmt.Sprintf("Verified target node (%d containers) ... ✔\n", len(cfg.Target.NodeContainers)),
+		cli.Record{Stage: cli.NodeVerified, Message: fmt.Sprintf("%d containers", len(cfg.Target.NodeContainers))})
+
+	// the profiling job is pinned to the node through a target pod that only carries the node name
+	nodePod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: cfg.Target.NodeName},
+		Spec:       v1.PodSpec{NodeName: cfg.Target.NodeName},
+	}
+
+	return p.launchProfiling(ctx, nodePod, printer, cfg)
+}
+
+// Attach reattaches to the running profiling session given by cfg.Target.Id: the log stream of its agent is
+// replayed from the start and the result files not downloaded yet into the local path are downloaded.
+// The agent is either the container of a profiling job or an ephemeral container of the target pod.
+func (p *Profiler) Attach(ctx context.Context, cfg *config.ProfilerConfig) error {
+	printer := cli.NewPrinter(false)
+
+	profilingPod, containerName, targetPod, err := p.findProfilingSession(ctx, cfg)
+	if err != nil {
+		return cli.NewError(cli.ExitTargetNotFound, errors.Wrapf(err, "unable to find the agent of the profiling session %s", cfg.Target.Id))
+	}
+	printer.Report(fmt.Sprintf("Attached to the profiling session %s ... 🔗\n", cfg.Target.Id), cli.Record{Stage: cli.Attached, Session: cfg.Target.Id})
+
+	// the results are compressed by the agent with the compressor it was launched with
+	if c := kubernetes.ArgumentValue(agentArgs(profilingPod, containerName), "--compressor-type"); c != "" {
+		cfg.Target.Compressor = compressor.Type(c)
 	}
-	printer.Print("Verified target pod ... ✔\n")
 
+	return p.retrieveResults(ctx, profilingPod, containerName, targetPod, printer, cfg)
+}
+
+// findProfilingSession returns the pod and the container of the agent of the profiling session given by
+// cfg.Target.Id, along with the target pod which the result files are named after as in the original session.
+// The ephemeral container of the session is looked up in the pods of the namespace, and then its profiling job.
+func (p *Profiler) findProfilingSession(ctx context.Context, cfg *config.ProfilerConfig) (*v1.Pod, string, *v1.Pod, error) {
+	targetPod, err := p.profilingEphemeralContainerApi.FindProfilingPod(cfg.Job.Namespace, cfg.Target.Id, ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if targetPod != nil {
+		return targetPod, job.EphemeralContainerName(cfg.Target.Id), targetPod, nil
+	}
+
+	profilingPod, err := p.profilingJobApi.GetProfilingPod(cfg, ctx, attachTimeout)
+	if err != nil {
+		return nil, "", nil, errors.Wrapf(err, "neither its ephemeral container %s nor its profiling job was found in namespace %s",
+			job.EphemeralContainerName(cfg.Target.Id), cfg.Job.Namespace)
+	}
+	targetPod = &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: job.TargetName(profilingPod.Annotations)}}
+	return profilingPod, p.profilingJobApi.GetProfilingContainerName(), targetPod, nil
+}
+
+// agentArgs returns the arguments of the given agent container of the pod, which is either a container of
+// a profiling job or an ephemeral container
+func agentArgs(pod *v1.Pod, containerName string) []string {
+	for _, c := range pod.Spec.Containers {
+		if c.Name == containerName {
+			return c.Args
+		}
+	}
+	for _, c := range pod.Spec.EphemeralContainers {
+		if c.Name == containerName {
+			return c.Args
+		}
+	}
+	return nil
+}
+
+// launchProfiling launches the profiling agent for the target pod according to the launch mode
+func (p *Profiler) launchProfiling(ctx context.Context, targetPod *v1.Pod, printer cli.Printer, cfg *config.ProfilerConfig) error {
+	if cfg.LaunchMode == config.EphemeralLaunchMode {
+		return p.launchEphemeralContainerProfiling(ctx, targetPod, printer, cfg)
+	}
+	return p.launchJobProfiling(ctx, targetPod, printer, cfg)
+}
+
+// launchJobProfiling launches the profiling job for the target pod, waits for the profiling results,
+// downloads them and finally deletes the profiling job
+func (p *Profiler) launchJobProfiling(ctx context.Context, targetPod *v1.Pod, printer cli.Printer, cfg *config.ProfilerConfig) error {
 	profileId, job, err := p.profilingJobApi.CreateProfilingJob(targetPod, cfg, ctx)
 	if err != nil {
-		return err
+		return cli.NewError(cli.ExitAgentFailed, err)
+	}
+	printer.Report(fmt.Sprintf("Launched profiler (session %s) ... 🚀\n", profileId), cli.Record{Stage: cli.JobCreated, Session: profileId, Message: job.Name})
+	if p.recorder != nil {
+		p.recorder.RecordJob(targetPod.Name, job)
 	}
-	printer.Print("Launched profiler ... 🚀\n")
 
 	if cfg.Target.DryRun {
 		return nil
 	}
 
+	if cfg.Target.Detach {
+		// the job ends by itself once the results are persisted
+		printDetached(printer, profileId)
+		return nil
+	}
+
 	cfg.Target.Id = profileId
-	profilingPod, err := p.profilingJobApi.GetProfilingPod(cfg, ctx, 5*time.Minute)
+	err = p.retrieveJobResults(ctx, targetPod, printer, cfg)
+	if ctx.Err() != nil {
+		// the profiling has been interrupted, so the job is deleted right away instead of leaving
+		// the agent on the node until its grace period expires
+		p.deleteInterruptedProfilingJob(job, printer)
+		return ctx.Err()
+	}
 	if err != nil {
 		return err
 	}
 
+	// invoke delete profiling job
+	return p.profilingJobApi.DeleteProfilingJob(job, ctx)
+}
+
+// retrieveJobResults waits for the pod of the profiling job and downloads the profiling results
+func (p *Profiler) retrieveJobResults(ctx context.Context, targetPod *v1.Pod, printer cli.Printer, cfg *config.ProfilerConfig) error {
+	profilingPod, err := p.profilingJobApi.GetProfilingPod(cfg, ctx, 5*time.Minute)
+	if err != nil {
+		return waitError(err)
+	}
+
+	return p.retrieveResults(ctx, profilingPod, p.profilingJobApi.GetProfilingContainerName(), targetPod, printer, cfg)
+}
+
+// deleteInterruptedProfilingJob deletes the profiling job of an interrupted profiling.
+// Deleting the job terminates the agent, which stops the running profiler and cleans up the node.
+func (p *Profiler) deleteInterruptedProfilingJob(job *batchv1.Job, printer cli.Printer) {
+	printer.Report("Profiling interrupted, deleting the profiling job ... 🧹\n", cli.Record{Stage: cli.Interrupted, Message: "deleting the profiling job " + job.Name})
+
+	// the profiling context is already cancelled, so a new one is needed for deleting the job
+	ctx, cancel := context.WithTimeout(context.Background(), deleteInterruptedJobTimeout)
+	defer cancel()
+	if err := p.profilingJobApi.DeleteProfilingJob(job, ctx); err != nil {
+		printer.PrintError()
+		printer.Report(fmt.Sprintf("The profiling job %s could not be deleted: %s\n", job.Name, err),
+			cli.Record{Stage: cli.Failed, Message: fmt.Sprintf("the profiling job %s could not be deleted: %s", job.Name, err)})
+	}
+}
+
+// launchEphemeralContainerProfiling injects the profiling agent as an ephemeral container into the target pod,
+// waits for the profiling results and downloads them. The ephemeral container is not deleted (Kubernetes does
+// not allow it), it just ends when the profiling is done, or at once when the profiling is interrupted.
+func (p *Profiler) launchEphemeralContainerProfiling(ctx context.Context, targetPod *v1.Pod, printer cli.Printer, cfg *config.ProfilerConfig) error {
+	profileId, containerName, err := p.profilingEphemeralContainerApi.AddProfilingEphemeralContainer(targetPod, cfg, ctx)
+	if err != nil {
+		return cli.NewError(cli.ExitAgentFailed, err)
+	}
+	printer.Report("Launched profiler as ephemeral container ... 🚀\n",
+		cli.Record{Stage: cli.EphemeralContainerCreated, Session: profileId, Message: containerName})
+
+	if cfg.Target.DryRun {
+		return nil
+	}
+
+	if cfg.Target.Detach {
+		printDetached(printer, profileId)
+		return nil
+	}
+
+	cfg.Target.Id = profileId
+	profilingPod, err := p.profilingEphemeralContainerApi.GetProfilingPod(targetPod, containerName, ctx, 5*time.Minute)
+	if err == nil {
+		err = p.retrieveResults(ctx, profilingPod, containerName, targetPod, printer, cfg)
+	} else {
+		err = waitError(err)
+	}
+	if ctx.Err() != nil {
+		msg := fmt.Sprintf("the ephemeral container %s cannot be removed, its agent was terminated", containerName)
+		if err := p.profilingContainerApi.TerminateProfiling(targetPod, containerName); err != nil {
+			msg = fmt.Sprintf("the ephemeral container %s cannot be removed and will end by itself: %v", containerName, err)
+		}
+		printer.Report(fmt.Sprintf("⚠️ Profiling interrupted, %s\n", msg),
+			cli.Record{Stage: cli.Interrupted, Session: profileId, Message: msg})
+		return ctx.Err()
+	}
+
+	return err
+}
+
+// printDetached prints how to follow a detached profiling session
+func printDetached(printer cli.Printer, profileId string) {
+	printer.Report(fmt.Sprintf("Detached from the profiling session %s, the results are persisted by the agent. Run \"kubectl prof attach %s\" to follow it ... 🛰️\n", profileId, profileId),
+		cli.Record{Stage: cli.Detached, Session: profileId})
+}
+
+// retrieveResults handles the events of the profiling container and downloads the profiling results
+// until the profiling is done
+func (p *Profiler) retrieveResults(ctx context.Context, profilingPod *v1.Pod, containerName string, targetPod *v1.Pod,
+	printer cli.Printer, cfg *config.ProfilerConfig) error {
 	eventHandler := handler.NewEventHandler(cfg.Target, printer)
-	done, resultFile, err := p.profilingContainerApi.HandleProfilingContainerLogs(profilingPod,
-		p.profilingJobApi.GetProfilingContainerName(), eventHandler, ctx)
+	var h api.EventHandler = eventHandler
+	if p.recorder != nil {
+		h = p.recorder.Handler(targetPod.Name, eventHandler)
+	}
+	done, resultFile, err := p.profilingContainerApi.HandleProfilingContainerLogs(profilingPod, containerName, h, ctx)
 	if err != nil {
 		return err
 	}
 
+	ledger := result.NewLedger(cfg.Target.LocalPath)
 	profilingStart := time.Now()
+	// the failed transfers do not stop the profiling, but they are reported once it is done
+	var transferErr error
 	var end bool
+	stopEarly := p.stopEarly
 	for {
 		select {
 		case f := <-resultFile:
+			if fileName, ok := ledger.Downloaded(f); ok {
+				printer.Report(fmt.Sprintf("The profiling result file [%s] was already downloaded, skipped. ✔\n", fileName),
+					cli.Record{Stage: cli.ResultSkipped, Session: cfg.Target.Id, File: fileName})
+				p.acknowledgeResult(profilingPod, containerName, f)
+				continue
+			}
+			if f.Object != "" {
+				printer.Report(fmt.Sprintf("The profiling result file was uploaded to %s ☁️\n", f.Object),
+					cli.Record{Stage: cli.ResultUploaded, Session: cfg.Target.Id, Location: f.Object, FileSizeInBytes: f.FileSizeInBytes, Checksum: f.Checksum})
+				if !cfg.Target.UploadFetch {
+					continue
+				}
+			}
 			start := time.Now()
-			fileName, err := p.profilingContainerApi.GetRemoteFile(profilingPod, p.profilingJobApi.GetProfilingContainerName(), f, targetPod.Name, cfg.Target)
+			fileName, err := p.profilingContainerApi.GetRemoteFile(profilingPod, containerName, f, targetPod.Name, cfg.Target)
 			if err != nil {
+				transferErr = cli.NewError(cli.ExitTransferFailed, err)
 				printer.PrintError()
-				fmt.Println(err.Error())
+				printer.Report(err.Error()+"\n", cli.Record{Stage: cli.Failed, Session: cfg.Target.Id, Message: err.Error(), Code: cli.ExitTransferFailed.String()})
 			} else {
+				if err := ledger.Record(f, fileName); err != nil {
+					log.Warnf("%v", err)
+				}
 				// downloaded result file
 				elapsed := time.Since(start)
 				printer.Print(fmt.Sprintf("Remote profiling file downloaded in %f seconds. ✔\n", elapsed.Seconds()))
 
 				elapsed = time.Since(profilingStart)
-				printer.Print(fmt.Sprintf("The profiling result file [%s] was obtained in %f seconds. 🔥\n", fileName, elapsed.Seconds()))
+				printer.Report(fmt.Sprintf("The profiling result file [%s] was obtained in %f seconds. 🔥\n", fileName, elapsed.Seconds()),
+					cli.Record{Stage: cli.ResultDownloaded, Session: cfg.Target.Id, File: fileName, FileSizeInBytes: fileSize(fileName), Checksum: f.Checksum})
+				p.acknowledgeResult(profilingPod, containerName, f)
 			}
 		case end = <-done:
+		case <-stopEarly:
+			// the agent is asked only once
+			stopEarly = nil
+			p.stopProfiling(profilingPod, containerName, printer, cfg)
+		case <-ctx.Done():
+			return ctx.Err()
 		}
 		if end {
 			break
 		}
 	}
 
-	// invoke delete profiling job
-	return p.profilingJobApi.DeleteProfilingJob(job, ctx)
+	if err := eventHandler.Err(); err != nil {
+		return err
+	}
+	return transferErr
+}
+
+// stopProfiling asks the agent to finish its current capture early, so that the partial results are still downloaded
+func (p *Profiler) stopProfiling(profilingPod *v1.Pod, containerName string, printer cli.Printer, cfg *config.ProfilerConfig) {
+	printer.Report("Stopping the profiling early, waiting for the partial results (interrupt again to abort) ... ⏹️\n",
+		cli.Record{Stage: cli.Stopping, Session: cfg.Target.Id})
+	if err := p.profilingContainerApi.StopProfiling(profilingPod, containerName); err != nil {
+		printer.Report(fmt.Sprintf("⚠️ %s, waiting for the profiling to end\n", err),
+			cli.Record{Stage: cli.Notice, Session: cfg.Target.Id, Message: err.Error()})
+	}
+}
+
+// acknowledgeResult tells the agent that the given result file was retrieved, so that it ends without waiting for
+// its grace period. The results uploaded to the object storage are not waited for by the agent.
+// A failed acknowledgement only delays the ending of the agent, which is warned.
+func (p *Profiler) acknowledgeResult(profilingPod *v1.Pod, containerName string, f result.File) {
+	if f.Object != "" {
+		return
+	}
+	if err := p.profilingContainerApi.AcknowledgeResult(profilingPod, containerName, f); err != nil {
+		log.Warnf("%v, the agent will end once its grace period expires", err)
+	}
+}
+
+// fileSize returns the size of the given local file, or 0 if unknown
+func fileSize(fileName string) int64 {
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// targetError categorizes the given error getting the target: a target not found results in ExitTargetNotFound
+func targetError(err error) error {
+	if apierrors.IsNotFound(err) {
+		return cli.NewError(cli.ExitTargetNotFound, err)
+	}
+	return err
+}
+
+// waitError categorizes the given error waiting for the agent: the agent did either not start in time or fail
+func waitError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return cli.NewError(cli.ExitTimeout, errors.Wrap(err, "the agent did not start in time"))
+	}
+	return cli.NewError(cli.ExitAgentFailed, err)
 }
@@ -1,13 +1,20 @@
 package profiler
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/josepdcs/kubectl-prof/internal/cli"
 	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/internal/cli/kubernetes"
 	"github.com/josepdcs/kubectl-prof/internal/cli/profiler/api/fake"
+	"github.com/josepdcs/kubectl-prof/pkg/util/compressor"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestJobProfiler_Profile(t *testing.T) {
@@ -31,6 +38,7 @@ func TestJobProfiler_Profile(t *testing.T) {
 							fake.NewPodApi().WithReturnsEmpty(),
 							fake.NewProfilingJobApi(),
 							fake.NewProfilingContainerApi(),
+							fake.NewProfilingEphemeralContainerApi(),
 						),
 					},
 					args{
@@ -45,11 +53,12 @@ func TestJobProfiler_Profile(t *testing.T) {
 					}
 			},
 			when: func(f fields, args args) error {
-				return f.Profile(args.cfg)
+				return f.Profile(context.Background(), args.cfg)
 			},
 			then: func(t *testing.T, err error) {
 				require.Error(t, err)
 				assert.EqualError(t, err, "no target specified")
+				assert.Equal(t, cli.ExitInvalidArguments, cli.ExitCodeOf(err))
 			},
 		},
 		{
@@ -60,6 +69,7 @@ func TestJobProfiler_Profile(t *testing.T) {
 							fake.NewPodApi(),
 							fake.NewProfilingJobApi(),
 							fake.NewProfilingContainerApi(),
+							fake.NewProfilingEphemeralContainerApi(),
 						),
 					},
 					args{
@@ -75,7 +85,7 @@ func TestJobProfiler_Profile(t *testing.T) {
 					}
 			},
 			when: func(f fields, args args) error {
-				return f.Profile(args.cfg)
+				return f.Profile(context.Background(), args.cfg)
 			},
 			then: func(t *testing.T, err error) {
 				require.NoError(t, err)
@@ -89,6 +99,7 @@ func TestJobProfiler_Profile(t *testing.T) {
 							fake.NewPodApi(),
 							fake.NewProfilingJobApi(),
 							fake.NewProfilingContainerApi(),
+							fake.NewProfilingEphemeralContainerApi(),
 						),
 					},
 					args{
@@ -105,12 +116,220 @@ func TestJobProfiler_Profile(t *testing.T) {
 					}
 			},
 			when: func(f fields, args args) error {
-				return f.Profile(args.cfg)
+				return f.Profile(context.Background(), args.cfg)
 			},
 			then: func(t *testing.T, err error) {
 				require.NoError(t, err)
 			},
 		},
+		{
+			name: "should profile the pods of a workload",
+			given: func() (fields, args) {
+				return fields{
+						Profiler: New(
+							fake.NewPodApi(),
+							fake.NewProfilingJobApi(),
+							fake.NewProfilingContainerApi(),
+							fake.NewProfilingEphemeralContainerApi(),
+						),
+					},
+					args{
+						cfg: &config.ProfilerConfig{
+							Target: &config.TargetConfig{
+								Namespace:    "Namespace",
+								Kind:         config.Deployment,
+								WorkloadName: "checkout",
+								DryRun:       false,
+							},
+							Job:      &config.JobConfig{},
+							LogLevel: api.InfoLevel,
+						},
+					}
+			},
+			when: func(f fields, args args) error {
+				err := f.Profile(context.Background(), args.cfg)
+				assert.Equal(t, "ContainerName", args.cfg.Target.ContainerName)
+				return err
+			},
+			then: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "should fail when get the pods of a workload fail",
+			given: func() (fields, args) {
+				return fields{
+						Profiler: New(
+							fake.NewPodApi().WithReturnsError(),
+							fake.NewProfilingJobApi(),
+							fake.NewProfilingContainerApi(),
+							fake.NewProfilingEphemeralContainerApi(),
+						),
+					},
+					args{
+						cfg: &config.ProfilerConfig{
+							Target: &config.TargetConfig{
+								Namespace:    "Namespace",
+								Kind:         config.StatefulSet,
+								WorkloadName: "db",
+							},
+						},
+					}
+			},
+			when: func(f fields, args args) error {
+				return f.Profile(context.Background(), args.cfg)
+			},
+			then: func(t *testing.T, err error) {
+				require.Error(t, err)
+				assert.EqualError(t, err, "error getting pods")
+			},
+		},
+		{
+			name: "should fail when the workload has no pods",
+			given: func() (fields, args) {
+				return fields{
+						Profiler: New(
+							fake.NewPodApi().WithReturnsEmpty(),
+							fake.NewProfilingJobApi(),
+							fake.NewProfilingContainerApi(),
+							fake.NewProfilingEphemeralContainerApi(),
+						),
+					},
+					args{
+						cfg: &config.ProfilerConfig{
+							Target: &config.TargetConfig{
+								Namespace:    "Namespace",
+								Kind:         config.Job,
+								WorkloadName: "migration",
+							},
+						},
+					}
+			},
+			when: func(f fields, args args) error {
+				return f.Profile(context.Background(), args.cfg)
+			},
+			then: func(t *testing.T, err error) {
+				require.Error(t, err)
+				assert.EqualError(t, err, "No pods found in namespace Namespace for job migration")
+				assert.Equal(t, cli.ExitTargetNotFound, cli.ExitCodeOf(err))
+			},
+		},
+		{
+			name: "should profile the pods behind a service",
+			given: func() (fields, args) {
+				return fields{
+						Profiler: New(
+							fake.NewPodApi(),
+							fake.NewProfilingJobApi(),
+							fake.NewProfilingContainerApi(),
+							fake.NewProfilingEphemeralContainerApi(),
+						),
+					},
+					args{
+						cfg: &config.ProfilerConfig{
+							Target: &config.TargetConfig{
+								Namespace:   "Namespace",
+								Kind:        config.Service,
+								ServiceName: "checkout",
+							},
+							Job:      &config.JobConfig{},
+							LogLevel: api.InfoLevel,
+						},
+					}
+			},
+			when: func(f fields, args args) error {
+				return f.Profile(context.Background(), args.cfg)
+			},
+			then: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "should fail when the service has no ready endpoints",
+			given: func() (fields, args) {
+				return fields{
+						Profiler: New(
+							fake.NewPodApi().WithReturnsEmpty(),
+							fake.NewProfilingJobApi(),
+							fake.NewProfilingContainerApi(),
+							fake.NewProfilingEphemeralContainerApi(),
+						),
+					},
+					args{
+						cfg: &config.ProfilerConfig{
+							Target: &config.TargetConfig{
+								Namespace:   "Namespace",
+								Kind:        config.Service,
+								ServiceName: "checkout",
+							},
+						},
+					}
+			},
+			when: func(f fields, args args) error {
+				return f.Profile(context.Background(), args.cfg)
+			},
+			then: func(t *testing.T, err error) {
+				require.Error(t, err)
+				assert.EqualError(t, err, "No ready endpoints found in namespace Namespace for service checkout")
+			},
+		},
+		{
+			name: "should profile the containers of a node",
+			given: func() (fields, args) {
+				return fields{
+						Profiler: New(
+							fake.NewPodApi(),
+							fake.NewProfilingJobApi(),
+							fake.NewProfilingContainerApi(),
+							fake.NewProfilingEphemeralContainerApi(),
+						),
+					},
+					args{
+						cfg: &config.ProfilerConfig{
+							Target: &config.TargetConfig{
+								Kind:     config.Node,
+								NodeName: "worker-1",
+							},
+							Job:      &config.JobConfig{},
+							LogLevel: api.InfoLevel,
+						},
+					}
+			},
+			when: func(f fields, args args) error {
+				return f.Profile(context.Background(), args.cfg)
+			},
+			then: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "should fail when the node has no running containers",
+			given: func() (fields, args) {
+				return fields{
+						Profiler: New(
+							fake.NewPodApi().WithReturnsEmpty(),
+							fake.NewProfilingJobApi(),
+							fake.NewProfilingContainerApi(),
+							fake.NewProfilingEphemeralContainerApi(),
+						),
+					},
+					args{
+						cfg: &config.ProfilerConfig{
+							Target: &config.TargetConfig{
+								Kind:     config.Node,
+								NodeName: "worker-1",
+							},
+						},
+					}
+			},
+			when: func(f fields, args args) error {
+				return f.Profile(context.Background(), args.cfg)
+			},
+			then: func(t *testing.T, err error) {
+				require.Error(t, err)
+				assert.EqualError(t, err, "No running containers found on node worker-1")
+			},
+		},
 		{
 			name: "should skip when dry run",
 			given: func() (fields, args) {
@@ -119,6 +338,7 @@ func TestJobProfiler_Profile(t *testing.T) {
 							fake.NewPodApi(),
 							fake.NewProfilingJobApi(),
 							fake.NewProfilingContainerApi(),
+							fake.NewProfilingEphemeralContainerApi(),
 						),
 					},
 					args{
@@ -134,7 +354,40 @@ func TestJobProfiler_Profile(t *testing.T) {
 					}
 			},
 			when: func(f fields, args args) error {
-				return f.Profile(args.cfg)
+				return f.Profile(context.Background(), args.cfg)
+			},
+			then: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "should not wait for the results when detached",
+			given: func() (fields, args) {
+				return fields{
+						Profiler: New(
+							fake.NewPodApi(),
+							fake.NewProfilingJobApi().WithGetProfilingPodReturnsError(),
+							fake.NewProfilingContainerApi(),
+							fake.NewProfilingEphemeralContainerApi(),
+						),
+					},
+					args{
+						cfg: &config.ProfilerConfig{
+							Target: &config.TargetConfig{
+								Namespace:     "Namespace",
+								PodName:       "PodName",
+								ContainerName: "ContainerName",
+								ContainerID:   "ContainerID",
+								ExtraTargetOptions: config.ExtraTargetOptions{
+									OutputPVC: "results",
+									Detach:    true,
+								},
+							},
+						},
+					}
+			},
+			when: func(f fields, args args) error {
+				return f.Profile(context.Background(), args.cfg)
 			},
 			then: func(t *testing.T, err error) {
 				require.NoError(t, err)
@@ -148,6 +401,7 @@ func TestJobProfiler_Profile(t *testing.T) {
 							fake.NewPodApi().WithReturnsError(),
 							fake.NewProfilingJobApi(),
 							fake.NewProfilingContainerApi(),
+							fake.NewProfilingEphemeralContainerApi(),
 						),
 					},
 					args{
@@ -163,7 +417,7 @@ func TestJobProfiler_Profile(t *testing.T) {
 					}
 			},
 			when: func(f fields, args args) error {
-				return f.Profile(args.cfg)
+				return f.Profile(context.Background(), args.cfg)
 			},
 			then: func(t *testing.T, err error) {
 				require.Error(t, err)
@@ -178,6 +432,7 @@ func TestJobProfiler_Profile(t *testing.T) {
 							fake.NewPodApi().WithReturnsEmpty(),
 							fake.NewProfilingJobApi(),
 							fake.NewProfilingContainerApi(),
+							fake.NewProfilingEphemeralContainerApi(),
 						),
 					},
 					args{
@@ -193,7 +448,7 @@ func TestJobProfiler_Profile(t *testing.T) {
 					}
 			},
 			when: func(f fields, args args) error {
-				return f.Profile(args.cfg)
+				return f.Profile(context.Background(), args.cfg)
 			},
 			then: func(t *testing.T, err error) {
 				require.Error(t, err)
@@ -208,6 +463,7 @@ func TestJobProfiler_Profile(t *testing.T) {
 							fake.NewPodApi().WithReturnsError(),
 							fake.NewProfilingJobApi(),
 							fake.NewProfilingContainerApi(),
+							fake.NewProfilingEphemeralContainerApi(),
 						),
 					},
 					args{
@@ -223,7 +479,7 @@ func TestJobProfiler_Profile(t *testing.T) {
 					}
 			},
 			when: func(f fields, args args) error {
-				return f.Profile(args.cfg)
+				return f.Profile(context.Background(), args.cfg)
 			},
 			then: func(t *testing.T, err error) {
 				require.Error(t, err)
@@ -238,6 +494,7 @@ func TestJobProfiler_Profile(t *testing.T) {
 							fake.NewPodApi().WithReturnsEmpty(),
 							fake.NewProfilingJobApi(),
 							fake.NewProfilingContainerApi(),
+							fake.NewProfilingEphemeralContainerApi(),
 						),
 					},
 					args{
@@ -253,7 +510,7 @@ func TestJobProfiler_Profile(t *testing.T) {
 					}
 			},
 			when: func(f fields, args args) error {
-				return f.Profile(args.cfg)
+				return f.Profile(context.Background(), args.cfg)
 			},
 			then: func(t *testing.T, err error) {
 				require.Error(t, err)
@@ -268,6 +525,7 @@ func TestJobProfiler_Profile(t *testing.T) {
 							fake.NewPodApi(),
 							fake.NewProfilingJobApi().WithCreateProfilingJobReturnsError(),
 							fake.NewProfilingContainerApi(),
+							fake.NewProfilingEphemeralContainerApi(),
 						),
 					},
 					args{
@@ -283,11 +541,12 @@ func TestJobProfiler_Profile(t *testing.T) {
 					}
 			},
 			when: func(f fields, args args) error {
-				return f.Profile(args.cfg)
+				return f.Profile(context.Background(), args.cfg)
 			},
 			then: func(t *testing.T, err error) {
 				require.Error(t, err)
 				assert.EqualError(t, err, "error creating profiling job")
+				assert.Equal(t, cli.ExitAgentFailed, cli.ExitCodeOf(err))
 			},
 		},
 		{
@@ -298,6 +557,7 @@ func TestJobProfiler_Profile(t *testing.T) {
 							fake.NewPodApi(),
 							fake.NewProfilingJobApi().WithGetProfilingPodReturnsError(),
 							fake.NewProfilingContainerApi(),
+							fake.NewProfilingEphemeralContainerApi(),
 						),
 					},
 					args{
@@ -313,7 +573,7 @@ func TestJobProfiler_Profile(t *testing.T) {
 					}
 			},
 			when: func(f fields, args args) error {
-				return f.Profile(args.cfg)
+				return f.Profile(context.Background(), args.cfg)
 			},
 			then: func(t *testing.T, err error) {
 				require.Error(t, err)
@@ -328,6 +588,7 @@ func TestJobProfiler_Profile(t *testing.T) {
 							fake.NewPodApi(),
 							fake.NewProfilingJobApi(),
 							fake.NewProfilingContainerApi().WithHandleProfilingContainerLogsReturnsError(),
+							fake.NewProfilingEphemeralContainerApi(),
 						),
 					},
 					args{
@@ -343,7 +604,7 @@ func TestJobProfiler_Profile(t *testing.T) {
 					}
 			},
 			when: func(f fields, args args) error {
-				return f.Profile(args.cfg)
+				return f.Profile(context.Background(), args.cfg)
 			},
 			then: func(t *testing.T, err error) {
 				require.Error(t, err)
@@ -351,13 +612,106 @@ func TestJobProfiler_Profile(t *testing.T) {
 			},
 		},
 		{
-			name: "should terminate when get remote file fail",
+			name: "should profile one pod with an ephemeral container",
+			given: func() (fields, args) {
+				return fields{
+						Profiler: New(
+							fake.NewPodApi(),
+							fake.NewProfilingJobApi(),
+							fake.NewProfilingContainerApi(),
+							fake.NewProfilingEphemeralContainerApi(),
+						),
+					},
+					args{
+						cfg: &config.ProfilerConfig{
+							Target: &config.TargetConfig{
+								Namespace:     "Namespace",
+								PodName:       "PodName",
+								ContainerName: "ContainerName",
+								ContainerID:   "ContainerID",
+							},
+							LaunchMode: config.EphemeralLaunchMode,
+						},
+					}
+			},
+			when: func(f fields, args args) error {
+				return f.Profile(context.Background(), args.cfg)
+			},
+			then: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "should fail when add profiling ephemeral container fail",
+			given: func() (fields, args) {
+				return fields{
+						Profiler: New(
+							fake.NewPodApi(),
+							fake.NewProfilingJobApi(),
+							fake.NewProfilingContainerApi(),
+							fake.NewProfilingEphemeralContainerApi().WithAddProfilingEphemeralContainerReturnsError(),
+						),
+					},
+					args{
+						cfg: &config.ProfilerConfig{
+							Target: &config.TargetConfig{
+								Namespace:     "Namespace",
+								PodName:       "PodName",
+								ContainerName: "ContainerName",
+								ContainerID:   "ContainerID",
+							},
+							LaunchMode: config.EphemeralLaunchMode,
+						},
+					}
+			},
+			when: func(f fields, args args) error {
+				return f.Profile(context.Background(), args.cfg)
+			},
+			then: func(t *testing.T, err error) {
+				require.Error(t, err)
+				assert.EqualError(t, err, "error adding profiling ephemeral container")
+			},
+		},
+		{
+			name: "should fail when get profiling pod of the ephemeral container fail",
+			given: func() (fields, args) {
+				return fields{
+						Profiler: New(
+							fake.NewPodApi(),
+							fake.NewProfilingJobApi(),
+							fake.NewProfilingContainerApi(),
+							fake.NewProfilingEphemeralContainerApi().WithGetProfilingPodReturnsError(),
+						),
+					},
+					args{
+						cfg: &config.ProfilerConfig{
+							Target: &config.TargetConfig{
+								Namespace:     "Namespace",
+								PodName:       "PodName",
+								ContainerName: "ContainerName",
+								ContainerID:   "ContainerID",
+							},
+							LaunchMode: config.EphemeralLaunchMode,
+						},
+					}
+			},
+			when: func(f fields, args args) error {
+				return f.Profile(context.Background(), args.cfg)
+			},
+			then: func(t *testing.T, err error) {
+				require.Error(t, err)
+				assert.EqualError(t, err, "error getting profiling pod")
+			},
+		},
+		{
+			name: "should terminate with a transfer failure when get remote file fail",
 			given: func() (fields, args) {
 				return fields{
 						Profiler: New(
 							fake.NewPodApi(),
 							fake.NewProfilingJobApi(),
 							fake.NewProfilingContainerApi().WithGetRemoteFileReturnsError(),
+							fake.NewProfilingEphemeralContainerApi(),
 						),
 					},
 					args{
@@ -373,10 +727,11 @@ func TestJobProfiler_Profile(t *testing.T) {
 					}
 			},
 			when: func(f fields, args args) error {
-				return f.Profile(args.cfg)
+				return f.Profile(context.Background(), args.cfg)
 			},
 			then: func(t *testing.T, err error) {
-				require.NoError(t, err)
+				require.Error(t, err)
+				assert.Equal(t, cli.ExitTransferFailed, cli.ExitCodeOf(err))
 			},
 		},
 	}
@@ -393,3 +748,420 @@ func TestJobProfiler_Profile(t *testing.T) {
 		})
 	}
 }
+
+func TestJobProfiler_Profile_InterruptedEphemeralContainer(t *testing.T) {
+	// Given
+	profilingContainerApi := fake.NewProfilingContainerApi().WithHandleProfilingContainerLogsNeverEnds()
+	p := New(fake.NewPodApi(), fake.NewProfilingJobApi(), profilingContainerApi, fake.NewProfilingEphemeralContainerApi())
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// When
+	err := p.Profile(ctx, &config.ProfilerConfig{
+		Target:     &config.TargetConfig{Namespace: "Namespace", PodName: "PodName", ContainerName: "ContainerName", LocalPath: t.TempDir()},
+		Job:        &config.JobConfig{},
+		LaunchMode: config.EphemeralLaunchMode,
+	})
+
+	// Then
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, profilingContainerApi.TerminateProfilingInvokedTimes())
+}
+
+func TestJobProfiler_Profile_Interrupted(t *testing.T) {
+	tests := []struct {
+		name   string
+		target *config.TargetConfig
+		want   int
+	}{
+		{
+			name: "should delete the profiling job of one pod",
+			target: &config.TargetConfig{
+				Namespace:     "Namespace",
+				PodName:       "PodName",
+				ContainerName: "ContainerName",
+			},
+			want: 1,
+		},
+		{
+			name: "should delete the profiling jobs of a bunch of pods",
+			target: &config.TargetConfig{
+				Namespace:     "Namespace",
+				LabelSelector: "app=myapp",
+				ContainerName: "ContainerName",
+			},
+			want: 1,
+		},
+		{
+			name: "should delete the profiling jobs of a workload",
+			target: &config.TargetConfig{
+				Namespace:    "Namespace",
+				Kind:         config.Deployment,
+				WorkloadName: "checkout",
+			},
+			want: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			profilingJobApi := fake.NewProfilingJobApi()
+			p := New(
+				fake.NewPodApi(),
+				profilingJobApi,
+				fake.NewProfilingContainerApi().WithHandleProfilingContainerLogsNeverEnds(),
+				fake.NewProfilingEphemeralContainerApi(),
+			)
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			// When
+			err := p.Profile(ctx, &config.ProfilerConfig{Target: tt.target, Job: &config.JobConfig{}})
+
+			// Then
+			require.ErrorIs(t, err, context.DeadlineExceeded)
+			assert.Equal(t, tt.want, profilingJobApi.DeletedJobs())
+		})
+	}
+}
+
+func TestJobProfiler_Profile_StopEarly(t *testing.T) {
+	tests := []struct {
+		name  string
+		given func() fake.ProfilingContainerApi
+		then  func(t *testing.T, err error, profilingContainerApi fake.ProfilingContainerApi, profilingJobApi fake.ProfilingJobApi)
+	}{
+		{
+			name: "should download the partial results and delete the profiling job",
+			given: func() fake.ProfilingContainerApi {
+				return fake.NewProfilingContainerApi().WithHandleProfilingContainerLogsNeverEnds()
+			},
+			then: func(t *testing.T, err error, profilingContainerApi fake.ProfilingContainerApi, profilingJobApi fake.ProfilingJobApi) {
+				require.NoError(t, err)
+				assert.Equal(t, 1, profilingContainerApi.StopProfilingInvokedTimes())
+				assert.Equal(t, 1, profilingJobApi.DeletedJobs())
+			},
+		},
+		{
+			name: "should keep waiting for the profiling when the agent cannot be stopped",
+			given: func() fake.ProfilingContainerApi {
+				return fake.NewProfilingContainerApi().WithHandleProfilingContainerLogsNeverEnds().WithStopProfilingReturnsError()
+			},
+			then: func(t *testing.T, err error, profilingContainerApi fake.ProfilingContainerApi, profilingJobApi fake.ProfilingJobApi) {
+				require.ErrorIs(t, err, context.DeadlineExceeded)
+				assert.Equal(t, 1, profilingContainerApi.StopProfilingInvokedTimes())
+				assert.Equal(t, 1, profilingJobApi.DeletedJobs())
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			profilingJobApi := fake.NewProfilingJobApi()
+			profilingContainerApi := tt.given()
+			stopEarly := make(chan struct{})
+			close(stopEarly)
+			p := New(fake.NewPodApi(), profilingJobApi, profilingContainerApi, fake.NewProfilingEphemeralContainerApi()).
+				WithStopEarly(stopEarly)
+			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer cancel()
+
+			// When
+			err := p.Profile(ctx, &config.ProfilerConfig{
+				Target: &config.TargetConfig{Namespace: "Namespace", PodName: "PodName", ContainerName: "ContainerName", LocalPath: t.TempDir()},
+				Job:    &config.JobConfig{},
+			})
+
+			// Then
+			tt.then(t, err, profilingContainerApi, profilingJobApi)
+		})
+	}
+}
+
+func TestJobProfiler_Profile_AcknowledgeResults(t *testing.T) {
+	tests := []struct {
+		name  string
+		given func() fake.ProfilingContainerApi
+		then  func(t *testing.T, err error, profilingContainerApi fake.ProfilingContainerApi)
+	}{
+		{
+			name: "should acknowledge the downloaded result file",
+			given: func() fake.ProfilingContainerApi {
+				return fake.NewProfilingContainerApi()
+			},
+			then: func(t *testing.T, err error, profilingContainerApi fake.ProfilingContainerApi) {
+				require.NoError(t, err)
+				assert.Equal(t, []string{"filename"}, profilingContainerApi.AcknowledgedResults())
+			},
+		},
+		{
+			name: "should not acknowledge the result file that could not be downloaded",
+			given: func() fake.ProfilingContainerApi {
+				return fake.NewProfilingContainerApi().WithGetRemoteFileReturnsError()
+			},
+			then: func(t *testing.T, err error, profilingContainerApi fake.ProfilingContainerApi) {
+				require.Error(t, err)
+				assert.Equal(t, cli.ExitTransferFailed, cli.ExitCodeOf(err))
+				assert.Empty(t, profilingContainerApi.AcknowledgedResults())
+			},
+		},
+		{
+			name: "should not fail when the result file cannot be acknowledged",
+			given: func() fake.ProfilingContainerApi {
+				return fake.NewProfilingContainerApi().WithAcknowledgeResultReturnsError()
+			},
+			then: func(t *testing.T, err error, profilingContainerApi fake.ProfilingContainerApi) {
+				require.NoError(t, err)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			profilingContainerApi := tt.given()
+			p := New(fake.NewPodApi(), fake.NewProfilingJobApi(), profilingContainerApi, fake.NewProfilingEphemeralContainerApi())
+
+			// When
+			err := p.Profile(context.Background(), &config.ProfilerConfig{
+				Target: &config.TargetConfig{Namespace: "Namespace", PodName: "PodName", ContainerName: "ContainerName", LocalPath: t.TempDir()},
+				Job:    &config.JobConfig{},
+			})
+
+			// Then
+			tt.then(t, err, profilingContainerApi)
+		})
+	}
+}
+
+func TestJobProfiler_Profile_Preflight(t *testing.T) {
+	tests := []struct {
+		name         string
+		preflightApi fake.PreflightApi
+		dryRun       bool
+		then         func(t *testing.T, err error)
+	}{
+		{
+			name:         "should profile when no problem is found",
+			preflightApi: fake.NewPreflightApi(),
+			then: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:         "should report every problem found at once",
+			preflightApi: fake.NewPreflightApi().WithProblems("first problem", "second problem"),
+			then: func(t *testing.T, err error) {
+				require.Error(t, err)
+				assert.Equal(t, cli.ExitPreflightFailed, cli.ExitCodeOf(err))
+				assert.EqualError(t, err, "the profiling cannot be launched:\n- first problem\n- second problem")
+			},
+		},
+		{
+			name:         "should profile when the checks cannot be run",
+			preflightApi: fake.NewPreflightApi().WithCheckReturnsError(),
+			then: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:         "should refuse with the problems found when some checks cannot be run",
+			preflightApi: fake.NewPreflightApi().WithProblems("problem").WithCheckReturnsError(),
+			then: func(t *testing.T, err error) {
+				require.Error(t, err)
+				assert.Equal(t, cli.ExitPreflightFailed, cli.ExitCodeOf(err))
+				assert.EqualError(t, err, "the profiling cannot be launched:\n- problem")
+			},
+		},
+		{
+			name:         "should not check a dry run",
+			preflightApi: fake.NewPreflightApi().WithProblems("problem"),
+			dryRun:       true,
+			then: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			p := New(fake.NewPodApi(), fake.NewProfilingJobApi(), fake.NewProfilingContainerApi(), fake.NewProfilingEphemeralContainerApi()).
+				WithPreflightApi(tt.preflightApi)
+
+			// When
+			err := p.Profile(context.Background(), &config.ProfilerConfig{
+				Target: &config.TargetConfig{Namespace: "Namespace", PodName: "PodName", ContainerName: "ContainerName",
+					LocalPath: t.TempDir(), DryRun: tt.dryRun},
+				Job: &config.JobConfig{},
+			})
+
+			// Then
+			tt.then(t, err)
+		})
+	}
+}
+
+func TestJobProfiler_Profile_Policy(t *testing.T) {
+	disabled := map[string]string{kubernetes.ProfilingAnnotation: kubernetes.ProfilingDisabled}
+	tests := []struct {
+		name   string
+		podApi fake.PodApi
+		target *config.TargetConfig
+		then   func(t *testing.T, err error)
+	}{
+		{
+			name:   "should refuse the pod disabling the profiling",
+			podApi: fake.NewPodApi().WithPodAnnotations(disabled),
+			target: &config.TargetConfig{PodName: "PodName"},
+			then: func(t *testing.T, err error) {
+				require.Error(t, err)
+				assert.Equal(t, cli.ExitInvalidArguments, cli.ExitCodeOf(err))
+				assert.ErrorContains(t, err, "profiling is disabled for pod /")
+				assert.ErrorContains(t, err, "--override-policy")
+			},
+		},
+		{
+			name:   "should refuse the pod whose namespace does not allow the profiling tool",
+			podApi: fake.NewPodApi().WithNamespaceAnnotations(map[string]string{kubernetes.AllowedToolsAnnotation: "pprof"}),
+			target: &config.TargetConfig{PodName: "PodName", ProfilingTool: api.Jcmd},
+			then: func(t *testing.T, err error) {
+				require.Error(t, err)
+				assert.ErrorContains(t, err, "profiling tool jcmd is not allowed for namespace")
+			},
+		},
+		{
+			name:   "should profile the pod whose policy is overridden",
+			podApi: fake.NewPodApi().WithPodAnnotations(disabled),
+			target: &config.TargetConfig{PodName: "PodName", ExtraTargetOptions: config.ExtraTargetOptions{OverridePolicy: true}},
+			then: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:   "should refuse the pod whose namespace policy cannot be read",
+			podApi: fake.NewPodApi().WithGetNamespaceReturnsError(),
+			target: &config.TargetConfig{PodName: "PodName"},
+			then: func(t *testing.T, err error) {
+				require.Error(t, err)
+				assert.Equal(t, cli.ExitInvalidArguments, cli.ExitCodeOf(err))
+				assert.ErrorContains(t, err, "could not read the profiling policy of namespace : error getting namespace")
+				assert.ErrorContains(t, err, "--override-policy")
+			},
+		},
+		{
+			name:   "should profile the pod whose namespace policy cannot be read when the policy is overridden",
+			podApi: fake.NewPodApi().WithGetNamespaceReturnsError(),
+			target: &config.TargetConfig{PodName: "PodName", ExtraTargetOptions: config.ExtraTargetOptions{OverridePolicy: true}},
+			then: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:   "should skip the pods of the label selector disabling the profiling",
+			podApi: fake.NewPodApi().WithNamespaceAnnotations(disabled),
+			target: &config.TargetConfig{LabelSelector: "app=app"},
+			then: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:   "should refuse the node running a pod disabling the profiling",
+			podApi: fake.NewPodApi().WithPodAnnotations(disabled),
+			target: &config.TargetConfig{Kind: config.Node, NodeName: "worker-1"},
+			then: func(t *testing.T, err error) {
+				require.Error(t, err)
+				assert.ErrorContains(t, err, "node worker-1 cannot be profiled")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			p := New(tt.podApi, fake.NewProfilingJobApi(), fake.NewProfilingContainerApi(), fake.NewProfilingEphemeralContainerApi())
+			tt.target.ContainerName = "ContainerName"
+			tt.target.LocalPath = t.TempDir()
+
+			// When
+			err := p.Profile(context.Background(), &config.ProfilerConfig{Target: tt.target, Job: &config.JobConfig{}})
+
+			// Then
+			tt.then(t, err)
+		})
+	}
+}
+
+func TestJobProfiler_Attach(t *testing.T) {
+	ephemeralPod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "TargetPod", Namespace: "Namespace"},
+		Spec: v1.PodSpec{
+			EphemeralContainers: []v1.EphemeralContainer{{EphemeralContainerCommon: v1.EphemeralContainerCommon{
+				Name: "kubectl-prof-ID",
+				Args: []string{"--compressor-type", "none"},
+			}}},
+		},
+	}
+	tests := []struct {
+		name                           string
+		profilingJobApi                fake.ProfilingJobApi
+		profilingEphemeralContainerApi fake.ProfilingEphemeralContainerApi
+		then                           func(t *testing.T, err error, cfg *config.ProfilerConfig)
+	}{
+		{
+			name:                           "should attach to the profiling session",
+			profilingJobApi:                fake.NewProfilingJobApi(),
+			profilingEphemeralContainerApi: fake.NewProfilingEphemeralContainerApi(),
+			then: func(t *testing.T, err error, cfg *config.ProfilerConfig) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:                           "should attach to the profiling session of an ephemeral container",
+			profilingJobApi:                fake.NewProfilingJobApi().WithGetProfilingPodReturnsError(),
+			profilingEphemeralContainerApi: fake.NewProfilingEphemeralContainerApi().WithFindProfilingPodReturnsPod(ephemeralPod),
+			then: func(t *testing.T, err error, cfg *config.ProfilerConfig) {
+				require.NoError(t, err)
+				assert.Equal(t, compressor.None, cfg.Target.Compressor)
+			},
+		},
+		{
+			name:                           "should fail when the agent of the profiling session is not found",
+			profilingJobApi:                fake.NewProfilingJobApi().WithGetProfilingPodReturnsError(),
+			profilingEphemeralContainerApi: fake.NewProfilingEphemeralContainerApi(),
+			then: func(t *testing.T, err error, cfg *config.ProfilerConfig) {
+				require.Error(t, err)
+				assert.Equal(t, cli.ExitTargetNotFound, cli.ExitCodeOf(err))
+				assert.EqualError(t, err, "unable to find the agent of the profiling session ID: neither its ephemeral container "+
+					"kubectl-prof-ID nor its profiling job was found in namespace Namespace: error getting profiling pod")
+			},
+		},
+		{
+			name:                           "should fail when the ephemeral containers cannot be looked up",
+			profilingJobApi:                fake.NewProfilingJobApi(),
+			profilingEphemeralContainerApi: fake.NewProfilingEphemeralContainerApi().WithFindProfilingPodReturnsError(),
+			then: func(t *testing.T, err error, cfg *config.ProfilerConfig) {
+				require.Error(t, err)
+				assert.EqualError(t, err, "unable to find the agent of the profiling session ID: error finding profiling pod")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			p := New(
+				fake.NewPodApi(),
+				tt.profilingJobApi,
+				fake.NewProfilingContainerApi(),
+				tt.profilingEphemeralContainerApi,
+			)
+			cfg := &config.ProfilerConfig{
+				Target: &config.TargetConfig{Id: "ID", LocalPath: t.TempDir()},
+				Job:    &config.JobConfig{Namespace: "Namespace"},
+			}
+
+			// When
+			err := p.Attach(context.Background(), cfg)
+
+			// Then
+			tt.then(t, err, cfg)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// isTerminal tells whether the given writer is a terminal, where the status line and the progress table are
+// rewritten in place
+var isTerminal = func(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// FormatProgress returns the given progress as a status line,
+// e.g. "iteration 2/3, 45% (1m30s elapsed, 1m50s remaining), PIDs: 1234 running, 1240 done"
+func FormatProgress(data *api.ProgressData) string {
+	line := fmt.Sprintf("iteration %d/%d, %d%% (%s elapsed, %s remaining)",
+		data.Iteration, data.Iterations, data.Percent(), data.Elapsed, data.Remaining)
+	if pids := formatPIDs(data.PIDs); pids != "" {
+		line += ", PIDs: " + pids
+	}
+	return line
+}
+
+// formatPIDs returns the state of the given PIDs, e.g. "1234 running, 1240 done"
+func formatPIDs(pids []api.PIDStatus) string {
+	states := make([]string, 0, len(pids))
+	for _, p := range pids {
+		states = append(states, fmt.Sprintf("%s %s", p.PID, p.State))
+	}
+	return strings.Join(states, ", ")
+}
+
+// ProgressTable renders the progress of several targets profiled in parallel as a compact table.
+// On a terminal, the table is redrawn in place as long as nothing else was printed since it was last rendered.
+type ProgressTable struct {
+	mutex    sync.Mutex
+	w        io.Writer
+	inPlace  bool
+	targets  []string
+	progress map[string]*api.ProgressData
+	changed  bool
+	// lines is the number of lines of the last rendering, if it is still the end of the output
+	lines int
+}
+
+// NewProgressTable returns a new ProgressTable written to the given writer
+func NewProgressTable(w io.Writer) *ProgressTable {
+	return &ProgressTable{
+		w:        w,
+		inPlace:  isTerminal(w),
+		progress: make(map[string]*api.ProgressData),
+	}
+}
+
+// Update sets the progress of the given target
+func (t *ProgressTable) Update(target string, data *api.ProgressData) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if _, ok := t.progress[target]; !ok {
+		t.targets = append(t.targets, target)
+	}
+	t.progress[target] = data
+	t.changed = true
+}
+
+// Render writes the table if the progress changed since it was last written
+func (t *ProgressTable) Render() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if !t.changed {
+		return
+	}
+	t.changed = false
+
+	var rendered bytes.Buffer
+	w := printers.GetNewTabWriter(&rendered)
+	_, _ = fmt.Fprintln(w, "POD\tITERATION\tPROGRESS\tELAPSED\tREMAINING\tPIDS")
+	for _, target := range t.targets {
+		data := t.progress[target]
+		_, _ = fmt.Fprintf(w, "%s\t%d/%d\t%d%%\t%s\t%s\t%s\n", target, data.Iteration, data.Iterations, data.Percent(),
+			data.Elapsed, data.Remaining, formatPIDs(data.PIDs))
+	}
+	_ = w.Flush()
+
+	if t.lines > 0 {
+		// moves the cursor up to the last rendering and clears it
+		_, _ = fmt.Fprintf(t.w, "\033[%dA\033[J", t.lines)
+	}
+	_, _ = t.w.Write(rendered.Bytes())
+	if t.inPlace {
+		t.lines = bytes.Count(rendered.Bytes(), []byte("\n"))
+	}
+}
+
+// Print writes the given text after the table, which is then rendered again below the text instead of in place
+func (t *ProgressTable) Print(str string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	_, _ = fmt.Fprint(t.w, str)
+	t.lines = 0
+}
+
+// Run renders the table every given interval until the given context is done
+func (t *ProgressTable) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.Render()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
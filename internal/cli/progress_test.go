@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatProgress(t *testing.T) {
+	data := &api.ProgressData{
+		Iteration:  2,
+		Iterations: 4,
+		Elapsed:    90 * time.Second,
+		Remaining:  30 * time.Second,
+		PIDs:       []api.PIDStatus{{PID: "1234", State: api.PIDRunning}, {PID: "1240", State: api.PIDDone}},
+	}
+
+	assert.Equal(t, "iteration 2/4, 75% (1m30s elapsed, 30s remaining), PIDs: 1234 running, 1240 done", FormatProgress(data))
+}
+
+func TestProgressTable_Render(t *testing.T) {
+	// Given
+	var out bytes.Buffer
+	table := NewProgressTable(&out)
+	table.Update("pod-b", &api.ProgressData{Iteration: 1, Iterations: 2, Elapsed: time.Minute, Remaining: time.Minute,
+		PIDs: []api.PIDStatus{{PID: "1", State: api.PIDPublishing}}})
+	table.Update("pod-a", &api.ProgressData{Iteration: 2, Iterations: 2, Elapsed: 2 * time.Minute})
+
+	// When
+	table.Render()
+	rendered := out.String()
+	table.Render()
+
+	// Then
+	assert.Equal(t, "POD     ITERATION   PROGRESS   ELAPSED   REMAINING   PIDS\n"+
+		"pod-b   1/2         50%        1m0s      1m0s        1 publishing\n"+
+		"pod-a   2/2         100%       2m0s      0s          \n", rendered)
+	// not rendered again until it changes
+	assert.Equal(t, rendered, out.String())
+}
+
+func TestProgressTable_Render_InPlace(t *testing.T) {
+	// Given
+	var out bytes.Buffer
+	table := NewProgressTable(&out)
+	table.inPlace = true
+	table.Update("pod-a", &api.ProgressData{Iteration: 1, Iterations: 2})
+	table.Update("pod-b", &api.ProgressData{Iteration: 1, Iterations: 2})
+	table.Render()
+	out.Reset()
+
+	// When
+	table.Update("pod-a", &api.ProgressData{Iteration: 2, Iterations: 2})
+	table.Render()
+
+	// Then
+	// the previous rendering of three lines is cleared
+	assert.True(t, strings.HasPrefix(out.String(), "\033[3A\033[J"+"POD "), out.String())
+
+	// When
+	out.Reset()
+	table.Print("[pod-a] The profiling result file was obtained\n")
+	table.Update("pod-b", &api.ProgressData{Iteration: 2, Iterations: 2})
+	table.Render()
+
+	// Then
+	// the printed text is kept, the table is rendered below it
+	assert.True(t, strings.HasPrefix(out.String(), "[pod-a] The profiling result file was obtained\nPOD "), out.String())
+}
@@ -0,0 +1,131 @@
+package result
+
+import (
+	"bufio"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// LedgerFileName is the name of the file, saved into the local path, which records the downloaded result files.
+const LedgerFileName = ".kubectl-prof.sums"
+
+// ledgerMutex serializes the writes to the ledger since several pods may be profiled in parallel
+var ledgerMutex sync.Mutex
+
+// Ledger records the checksums of the result files already downloaded into a local path,
+// so that a result file is not downloaded again when reattaching to a profiling session.
+// Each line holds the checksum of the remote file, the checksum of the local file and the name of the local file,
+// separated as md5sum does.
+type Ledger struct {
+	path string
+}
+
+// NewLedger returns the Ledger of the given local path
+func NewLedger(localPath string) *Ledger {
+	return &Ledger{
+		path: filepath.Join(localPath, LedgerFileName),
+	}
+}
+
+// Downloaded returns the name of the local file if the given result file was already downloaded and the local file
+// is still as downloaded. Result files without checksum are never considered downloaded.
+func (l *Ledger) Downloaded(file File) (string, bool) {
+	key := ledgerKey(file)
+	if key == "" {
+		return "", false
+	}
+
+	ledgerMutex.Lock()
+	defer ledgerMutex.Unlock()
+
+	f, err := os.Open(l.path)
+	if err != nil {
+		return "", false
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), "  ", 3)
+		if len(fields) != 3 || fields[0] != key {
+			continue
+		}
+		// the local file may have been modified or truncated since it was downloaded
+		if local, err := localChecksum(fields[2]); err == nil && local == fields[1] {
+			return fields[2], true
+		}
+	}
+
+	return "", false
+}
+
+// Record records the given result file as downloaded into the given local file.
+// Result files without checksum are not recorded.
+func (l *Ledger) Record(file File, localFileName string) error {
+	key := ledgerKey(file)
+	if key == "" {
+		return nil
+	}
+
+	local, err := localChecksum(localFileName)
+	if err != nil {
+		return errors.Wrap(err, "could not record the downloaded file")
+	}
+
+	ledgerMutex.Lock()
+	defer ledgerMutex.Unlock()
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Wrap(err, "could not open the ledger of downloaded files")
+	}
+	defer func() { _ = f.Close() }()
+
+	_, err = fmt.Fprintf(f, "%s  %s  %s\n", key, local, localFileName)
+	if err != nil {
+		return errors.Wrap(err, "could not record the downloaded file")
+	}
+
+	return nil
+}
+
+// ledgerKey returns the checksum identifying the given result file in the ledger. A result file split into chunks
+// carries no checksum of its own, so it is identified by the checksum of the checksums of its chunks.
+// Empty string is returned if the result file cannot be identified.
+func ledgerKey(file File) string {
+	if file.Checksum != "" || len(file.Chunks) == 0 {
+		return file.Checksum
+	}
+
+	h := md5.New()
+	for _, chunk := range file.Chunks {
+		if chunk.Checksum == "" {
+			return ""
+		}
+		_, _ = fmt.Fprintln(h, chunk.Checksum)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// localChecksum returns the MD5 checksum of the given local file
+func localChecksum(fileName string) (string, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,100 @@
+package result
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedger(t *testing.T) {
+	localPath := t.TempDir()
+	localFileName := filepath.Join(localPath, "my-pod-flamegraph.svg")
+	require.NoError(t, os.WriteFile(localFileName, []byte("<svg/>"), 0644))
+	ledger := NewLedger(localPath)
+	file := File{FileName: "/tmp/flamegraph.svg.gz", Checksum: "checksum"}
+
+	// not recorded yet
+	_, ok := ledger.Downloaded(file)
+	assert.False(t, ok)
+
+	// recorded
+	require.NoError(t, ledger.Record(file, localFileName))
+	fileName, ok := ledger.Downloaded(file)
+	assert.True(t, ok)
+	assert.Equal(t, localFileName, fileName)
+
+	// other checksum
+	_, ok = ledger.Downloaded(File{FileName: "/tmp/flamegraph.svg.gz", Checksum: "other"})
+	assert.False(t, ok)
+
+	// the local file has been truncated
+	require.NoError(t, os.WriteFile(localFileName, []byte("<sv"), 0644))
+	_, ok = ledger.Downloaded(file)
+	assert.False(t, ok)
+
+	// the local file has been removed
+	require.NoError(t, os.Remove(localFileName))
+	_, ok = ledger.Downloaded(file)
+	assert.False(t, ok)
+}
+
+func TestLedger_RecordMissingLocalFile(t *testing.T) {
+	localPath := t.TempDir()
+	ledger := NewLedger(localPath)
+
+	err := ledger.Record(File{FileName: "/tmp/flamegraph.svg.gz", Checksum: "checksum"}, filepath.Join(localPath, "missing.svg"))
+
+	assert.Error(t, err)
+	assert.NoFileExists(t, filepath.Join(localPath, LedgerFileName))
+}
+
+func TestLedger_WithoutChecksum(t *testing.T) {
+	localPath := t.TempDir()
+	ledger := NewLedger(localPath)
+	file := File{FileName: "/tmp/flamegraph.svg.gz"}
+
+	require.NoError(t, ledger.Record(file, "my-pod-flamegraph.svg"))
+	_, ok := ledger.Downloaded(file)
+
+	assert.False(t, ok)
+	assert.NoFileExists(t, filepath.Join(localPath, LedgerFileName))
+}
+
+func TestLedger_Chunked(t *testing.T) {
+	localPath := t.TempDir()
+	localFileName := filepath.Join(localPath, "my-pod-heapdump.hprof")
+	require.NoError(t, os.WriteFile(localFileName, []byte("heap"), 0644))
+	ledger := NewLedger(localPath)
+	file := File{FileName: "/tmp/heapdump.hprof.gz", Chunks: []api.ChunkData{
+		{File: "/tmp/heapdump.hprof.gz.000", Checksum: "first"},
+		{File: "/tmp/heapdump.hprof.gz.001", Checksum: "second"},
+	}}
+
+	// not recorded yet
+	_, ok := ledger.Downloaded(file)
+	assert.False(t, ok)
+
+	// recorded
+	require.NoError(t, ledger.Record(file, localFileName))
+	fileName, ok := ledger.Downloaded(file)
+	assert.True(t, ok)
+	assert.Equal(t, localFileName, fileName)
+
+	// other chunks
+	_, ok = ledger.Downloaded(File{FileName: "/tmp/heapdump.hprof.gz", Chunks: []api.ChunkData{
+		{File: "/tmp/heapdump.hprof.gz.000", Checksum: "first"},
+		{File: "/tmp/heapdump.hprof.gz.001", Checksum: "other"},
+	}})
+	assert.False(t, ok)
+
+	// a chunk without checksum
+	_, ok = ledger.Downloaded(File{FileName: "/tmp/heapdump.hprof.gz", Chunks: []api.ChunkData{
+		{File: "/tmp/heapdump.hprof.gz.000", Checksum: "first"},
+		{File: "/tmp/heapdump.hprof.gz.001"},
+	}})
+	assert.False(t, ok)
+}
@@ -8,6 +8,8 @@ import (
 
 type File struct {
 	FileName        string
+	URL             string
+	Object          string
 	FileSizeInBytes int64
 	Checksum        string
 	Chunks          []api.ChunkData
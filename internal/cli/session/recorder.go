@@ -0,0 +1,108 @@
+// Package session records the event streams of the agents of a profiling session, along with the launched jobs and
+// the configuration of the CLI, and replays them offline.
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/internal/cli/result"
+	"github.com/josepdcs/kubectl-prof/internal/cli/version"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+// Version is the version of the format of the recorded sessions
+const Version = 1
+
+// Entry is a line of a recorded session. The first one is the header, holding the configuration of the CLI.
+// The next ones hold either the job launched for a target or a raw event line read from its agent.
+type Entry struct {
+	Time       time.Time              `json:"time"`
+	Version    int                    `json:"version,omitempty"`
+	CLIVersion string                 `json:"cli-version,omitempty"`
+	Config     *config.ProfilerConfig `json:"config,omitempty"`
+	Target     string                 `json:"target,omitempty"`
+	Job        *batchv1.Job           `json:"job,omitempty"`
+	Event      string                 `json:"event,omitempty"`
+}
+
+// EventHandler handles the events read from an agent
+type EventHandler interface {
+	Handle(events chan string, done chan bool, resultFile chan result.File)
+}
+
+// Recorder records a profiling session into a file, an entry per line
+type Recorder struct {
+	mutex   sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// NewRecorder creates the given file and records the header of the session with the given configuration
+func NewRecorder(fileName string, cfg *config.ProfilerConfig) (*Recorder, error) {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create the file recording the session")
+	}
+	r := &Recorder{file: f, encoder: json.NewEncoder(f)}
+	if err := r.encoder.Encode(Entry{Time: time.Now(), Version: Version, CLIVersion: version.GetCurrent(), Config: cfg}); err != nil {
+		_ = f.Close()
+		return nil, errors.Wrap(err, "could not record the session")
+	}
+	return r, nil
+}
+
+// RecordJob records the job launched for the given target
+func (r *Recorder) RecordJob(target string, job *batchv1.Job) {
+	r.record(Entry{Time: time.Now(), Target: target, Job: job})
+}
+
+// RecordEvent records the given raw event line read from the agent of the given target
+func (r *Recorder) RecordEvent(target, event string) {
+	r.record(Entry{Time: time.Now(), Target: target, Event: event})
+}
+
+func (r *Recorder) record(entry Entry) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if err := r.encoder.Encode(entry); err != nil {
+		log.Debugf("could not record the session: %v", err)
+	}
+}
+
+// Handler returns an EventHandler recording the events read from the agent of the given target
+// before handing them to the given EventHandler
+func (r *Recorder) Handler(target string, handler EventHandler) EventHandler {
+	return &recordingHandler{recorder: r, target: target, handler: handler}
+}
+
+// Close closes the file recording the session
+func (r *Recorder) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.file.Close()
+}
+
+// recordingHandler records the events before handing them to the wrapped EventHandler
+type recordingHandler struct {
+	recorder *Recorder
+	target   string
+	handler  EventHandler
+}
+
+func (h *recordingHandler) Handle(events chan string, done chan bool, resultFile chan result.File) {
+	recorded := make(chan string)
+	go func() {
+		defer close(recorded)
+		for event := range events {
+			h.recorder.RecordEvent(h.target, event)
+			recorded <- event
+		}
+	}()
+	h.handler.Handle(recorded, done, resultFile)
+}
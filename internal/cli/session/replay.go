@@ -0,0 +1,136 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/josepdcs/kubectl-prof/internal/cli"
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/internal/cli/handler"
+	"github.com/josepdcs/kubectl-prof/internal/cli/result"
+	"github.com/pkg/errors"
+)
+
+// maxEntrySize is the maximum size of an entry of a recorded session, which may hold a whole job manifest
+const maxEntrySize = 10 * 1024 * 1024
+
+// Replay replays the session recorded in the given reader, handing the events of each target to an
+// handler.EventHandler as while profiling. The result files are printed, but not downloaded, and the logs of the
+// agents are printed if they were while profiling or if requested. It returns the first failure reported by the agents, if any.
+func Replay(r io.Reader, printAgentLogs bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEntrySize)
+
+	header, err := readEntry(scanner)
+	if err != nil {
+		return errors.Wrap(err, "could not read the header of the recorded session")
+	}
+	if header.Version == 0 || header.Version > Version || header.Config == nil || header.Config.Target == nil {
+		return errors.Errorf("unsupported recorded session version %d, the CLI only understands up to %d", header.Version, Version)
+	}
+	if printAgentLogs {
+		header.Config.Target.PrintAgentLogs = true
+	}
+	printer := cli.NewPrinter(false)
+	printer.Print(fmt.Sprintf("Replaying the session recorded at %s by the CLI %s ... ⏪\n",
+		header.Time.Format(time.RFC3339), header.CLIVersion))
+
+	// the targets are kept in the order they were recorded, so that the first failure is reported
+	targets := make(map[string]*replayedTarget)
+	var order []*replayedTarget
+	for {
+		entry, err := readEntry(scanner)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "could not read the recorded session")
+		}
+		t, ok := targets[entry.Target]
+		if !ok {
+			t = newReplayedTarget(entry.Target, header.Config.Target)
+			targets[entry.Target] = t
+			order = append(order, t)
+		}
+		switch {
+		case entry.Job != nil:
+			t.printer.Print(fmt.Sprintf("Launched profiler (job %s) ... 🚀\n", entry.Job.Name))
+		case entry.Event != "":
+			t.events <- entry.Event
+		}
+	}
+
+	var replayErr error
+	for _, t := range order {
+		if err := t.wait(); err != nil && replayErr == nil {
+			replayErr = err
+		}
+	}
+	return replayErr
+}
+
+// readEntry reads the next entry of the recorded session, or io.EOF if none
+func readEntry(scanner *bufio.Scanner) (Entry, error) {
+	var entry Entry
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return entry, err
+		}
+		return entry, io.EOF
+	}
+	err := json.Unmarshal(scanner.Bytes(), &entry)
+	return entry, err
+}
+
+// replayedTarget replays the events of the agent of a target
+type replayedTarget struct {
+	printer  cli.Printer
+	handler  *handler.EventHandler
+	events   chan string
+	finished sync.WaitGroup
+}
+
+func newReplayedTarget(target string, cfg *config.TargetConfig) *replayedTarget {
+	printer := cli.NewPrinterWithTargetPod(false, target)
+	t := &replayedTarget{
+		printer: printer,
+		handler: handler.NewEventHandler(cfg, printer),
+		// the events are buffered so that the targets are replayed in parallel as while profiling
+		events: make(chan string, 1024),
+	}
+	done := make(chan bool)
+	resultFile := make(chan result.File)
+	handled := make(chan struct{})
+	go func() {
+		defer close(handled)
+		t.handler.Handle(t.events, done, resultFile)
+	}()
+
+	t.finished.Add(1)
+	go func() {
+		defer t.finished.Done()
+		for {
+			select {
+			case f := <-resultFile:
+				printer.Report(fmt.Sprintf("The profiling result file [%s] was produced (%d bytes, checksum %s) 📄\n", f.FileName, f.FileSizeInBytes, f.Checksum),
+					cli.Record{Stage: cli.ResultSkipped, File: f.FileName, FileSizeInBytes: f.FileSizeInBytes, Checksum: f.Checksum})
+			case <-done:
+				// every recorded event is replayed, even after the end
+			case <-handled:
+				return
+			}
+		}
+	}()
+	return t
+}
+
+// wait waits for the events to be handled, and returns the failure reported by the agent, if any
+func (t *replayedTarget) wait() error {
+	close(t.events)
+	t.finished.Wait()
+	return t.handler.Err()
+}
@@ -0,0 +1,150 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/josepdcs/kubectl-prof/api"
+	"github.com/josepdcs/kubectl-prof/internal/cli"
+	"github.com/josepdcs/kubectl-prof/internal/cli/config"
+	"github.com/josepdcs/kubectl-prof/internal/cli/result"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeHandler collects the events it handles
+type fakeHandler struct {
+	handled []string
+}
+
+func (f *fakeHandler) Handle(events chan string, _ chan bool, _ chan result.File) {
+	for event := range events {
+		f.handled = append(f.handled, event)
+	}
+}
+
+var (
+	helloEvent = fmt.Sprintf(`{"type":"hello","version":1,"data":{"protocol-version":%d}}`, api.ProtocolVersion)
+	startEvent = `{"type":"progress","version":1,"data":{"stage":"started"}}`
+	errorEvent = `{"type":"error","version":1,"data":{"reason":"no pid found","code":"pid-not-found"}}`
+	endEvent   = `{"type":"progress","version":1,"data":{"stage":"ended"}}`
+)
+
+func newConfig() *config.ProfilerConfig {
+	return &config.ProfilerConfig{Target: &config.TargetConfig{Namespace: "default", Language: api.Java}}
+}
+
+// record records a session with the given events of the given target into a file, whose name is returned
+func record(t *testing.T, target string, events ...string) string {
+	fileName := filepath.Join(t.TempDir(), "session.jsonl")
+	recorder, err := NewRecorder(fileName, newConfig())
+	require.NoError(t, err)
+
+	recorder.RecordJob(target, &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "kubectl-prof-job"}})
+	ch := make(chan string)
+	go func() {
+		for _, e := range events {
+			ch <- e
+		}
+		close(ch)
+	}()
+	handler := &fakeHandler{}
+	recorder.Handler(target, handler).Handle(ch, make(chan bool), make(chan result.File))
+	require.NoError(t, recorder.Close())
+	assert.Equal(t, events, handler.handled)
+	return fileName
+}
+
+func TestRecorder(t *testing.T) {
+	// Given
+	fileName := record(t, "my-pod", helloEvent, startEvent)
+
+	// When
+	f, err := os.Open(fileName)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+
+	// Then
+	require.Len(t, entries, 4)
+	assert.Equal(t, Version, entries[0].Version)
+	assert.Equal(t, api.Java, entries[0].Config.Target.Language)
+	assert.Equal(t, "my-pod", entries[1].Target)
+	assert.Equal(t, "kubectl-prof-job", entries[1].Job.Name)
+	assert.Equal(t, helloEvent, entries[2].Event)
+	assert.Equal(t, startEvent, entries[3].Event)
+}
+
+func TestReplay(t *testing.T) {
+	tests := []struct {
+		name     string
+		given    func() string
+		wantCode cli.ExitCode
+		wantErr  string
+	}{
+		{
+			name: "should replay a successful session",
+			given: func() string {
+				b, _ := os.ReadFile(record(t, "my-pod", helloEvent, startEvent, endEvent))
+				return string(b)
+			},
+			wantCode: cli.ExitOK,
+		},
+		{
+			name: "should replay the failure of the agent",
+			given: func() string {
+				b, _ := os.ReadFile(record(t, "my-pod", helloEvent, startEvent, errorEvent))
+				return string(b)
+			},
+			wantCode: cli.ExitTargetNotFound,
+			wantErr:  "no pid found",
+		},
+		{
+			name: "should fail when the version is not supported",
+			given: func() string {
+				return fmt.Sprintf(`{"time":"2026-01-01T00:00:00Z","version":%d,"config":{"Target":{}}}`, Version+1)
+			},
+			wantCode: cli.ExitFailure,
+			wantErr:  "unsupported recorded session version",
+		},
+		{
+			name: "should fail when the header is missing",
+			given: func() string {
+				return ""
+			},
+			wantCode: cli.ExitFailure,
+			wantErr:  "could not read the header",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			r := strings.NewReader(tt.given())
+
+			// When
+			err := Replay(r, false)
+
+			// Then
+			assert.Equal(t, tt.wantCode, cli.ExitCodeOf(err))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
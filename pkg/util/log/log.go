@@ -53,7 +53,7 @@ func (l *Logger) EventLn(eventType api.EventType, data interface{}) error {
 		return err
 	}
 
-	event := api.Event{Type: eventType, Data: new(jsoniter.RawMessage(eventData))}
+	event := api.Event{Type: eventType, Version: api.ProtocolVersion, Data: new(jsoniter.RawMessage(eventData))}
 	str, _ := jsoniter.MarshalToString(event)
 
 	// print on standard output if allowed
@@ -76,7 +76,7 @@ func EventLn(eventType api.EventType, data interface{}) error {
 }
 
 func (l *Logger) ErrorLn(err error) {
-	data := &api.ErrorData{Reason: err.Error()}
+	data := api.NewErrorData(err)
 	_ = l.EventLn(api.Error, data)
 }
 
@@ -2,6 +2,7 @@ package pod
 
 import (
 	"bytes"
+	"io"
 
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -15,7 +16,9 @@ import (
 
 // Executor interface for execute command on pod
 type Executor interface {
-	Execute(namespace, podName, containerName string, command []string) (*bytes.Buffer, *bytes.Buffer, *bytes.Buffer, error)
+	// Execute executes the command on the container of the pod, streaming its standard output and error
+	// to the given writers as they are received, so that large outputs are never held in memory
+	Execute(namespace, podName, containerName string, command []string, stdout, stderr io.Writer) error
 }
 
 // Exec struct for execute command on pod
@@ -36,8 +39,7 @@ func NewExec(config *rest.Config, client kubernetes.Interface) *Exec {
 }
 
 // Execute execute command on current podName and containerName
-func (p *Exec) Execute(namespace, podName, containerName string, command []string) (*bytes.Buffer, *bytes.Buffer, *bytes.Buffer, error) {
-	ioStreams, in, out, errOut := genericiooptions.NewTestIOStreams()
+func (p *Exec) Execute(namespace, podName, containerName string, command []string, stdout, stderr io.Writer) error {
 	options := &exec.ExecOptions{
 		StreamOptions: exec.StreamOptions{
 			Namespace:       namespace,
@@ -46,7 +48,7 @@ func (p *Exec) Execute(namespace, podName, containerName string, command []strin
 			TTY:             false,
 			Quiet:           false,
 			InterruptParent: nil,
-			IOStreams:       ioStreams,
+			IOStreams:       genericiooptions.IOStreams{In: &bytes.Buffer{}, Out: stdout, ErrOut: stderr},
 			ContainerName:   containerName,
 		},
 		Command:       command,
@@ -58,8 +60,8 @@ func (p *Exec) Execute(namespace, podName, containerName string, command []strin
 
 	err := options.Run()
 	if err != nil {
-		return in, out, errOut, errors.Wrap(err, "could not run exec operation")
+		return errors.Wrap(err, "could not run exec operation")
 	}
 
-	return in, out, errOut, nil
+	return nil
 }
@@ -1,6 +1,9 @@
 package pod
 
-import "bytes"
+import (
+	"bytes"
+	"io"
+)
 
 type ExecFake struct {
 	outFake    *bytes.Buffer
@@ -16,6 +19,13 @@ func NewExecFake(outFake *bytes.Buffer, errOutFake *bytes.Buffer, fakeError erro
 	}
 }
 
-func (e *ExecFake) Execute(string, string, string, []string) (*bytes.Buffer, *bytes.Buffer, *bytes.Buffer, error) {
-	return nil, e.outFake, e.errOutFake, e.fakeError
+// Execute writes the fake outputs to the given writers; they are not consumed, so every execution writes the same
+func (e *ExecFake) Execute(_ string, _ string, _ string, _ []string, stdout, stderr io.Writer) error {
+	if e.outFake != nil {
+		_, _ = stdout.Write(e.outFake.Bytes())
+	}
+	if e.errOutFake != nil {
+		_, _ = stderr.Write(e.errOutFake.Bytes())
+	}
+	return e.fakeError
 }
@@ -0,0 +1,79 @@
+package pod
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// PortForwarder interface for forward a local port to a pod
+type PortForwarder interface {
+	// Forward forwards a random local port to the given port of the pod and returns the local port.
+	// The forwarding lasts until the returned stop function is called.
+	Forward(namespace, podName string, port int) (uint16, func(), error)
+}
+
+// PortForward struct for forward a local port to a pod
+type PortForward struct {
+	RestConfig *rest.Config
+	ClientSet  kubernetes.Interface
+}
+
+// NewPortForward create new PortForward
+func NewPortForward(config *rest.Config, client kubernetes.Interface) *PortForward {
+	return &PortForward{
+		RestConfig: config,
+		ClientSet:  client,
+	}
+}
+
+// Forward forwards a random local port of the loopback interface to the given port of the pod through SPDY
+func (p *PortForward) Forward(namespace, podName string, port int) (uint16, func(), error) {
+	transport, upgrader, err := spdy.RoundTripperFor(p.RestConfig)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "could not create port-forward round tripper")
+	}
+
+	req := p.ClientSet.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(podName).
+		SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+
+	stopChan := make(chan struct{})
+	readyChan := make(chan struct{})
+	var errOut bytes.Buffer
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"}, []string{fmt.Sprintf("0:%d", port)},
+		stopChan, readyChan, io.Discard, &errOut)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "could not create port-forward")
+	}
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- forwarder.ForwardPorts() }()
+
+	select {
+	case err := <-errChan:
+		if err == nil {
+			err = errors.New(errOut.String())
+		}
+		return 0, nil, errors.Wrap(err, "could not run port-forward")
+	case <-readyChan:
+	}
+
+	ports, err := forwarder.GetPorts()
+	if err != nil || len(ports) == 0 {
+		close(stopChan)
+		return 0, nil, errors.Wrap(err, "could not get the forwarded port")
+	}
+
+	return ports[0].Local, func() { close(stopChan) }, nil
+}
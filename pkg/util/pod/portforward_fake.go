@@ -0,0 +1,21 @@
+package pod
+
+type PortForwardFake struct {
+	localPort uint16
+	fakeError error
+}
+
+func NewPortForwardFake(localPort uint16, fakeError error) *PortForwardFake {
+	return &PortForwardFake{
+		localPort: localPort,
+		fakeError: fakeError,
+	}
+}
+
+// Forward returns the fake local port without forwarding anything
+func (p *PortForwardFake) Forward(_ string, _ string, _ int) (uint16, func(), error) {
+	if p.fakeError != nil {
+		return 0, nil, p.fakeError
+	}
+	return p.localPort, func() {}, nil
+}
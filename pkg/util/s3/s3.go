@@ -0,0 +1,173 @@
+// Package s3 provides a client for S3-compatible object storages (AWS S3, MinIO, ...), enough to upload and download
+// the profiling result files. It is built on the MinIO client.
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/pkg/errors"
+)
+
+// Environment variables holding the configuration of the object storage, as read by the AWS tools
+const (
+	EnvEndpoint        = "AWS_ENDPOINT_URL_S3"
+	EnvRegion          = "AWS_REGION"
+	EnvAccessKeyID     = "AWS_ACCESS_KEY_ID"
+	EnvSecretAccessKey = "AWS_SECRET_ACCESS_KEY"
+	EnvSessionToken    = "AWS_SESSION_TOKEN"
+)
+
+// Keys of the Kubernetes Secret holding the configuration of the object storage
+const (
+	SecretEndpoint        = "endpoint"
+	SecretRegion          = "region"
+	SecretAccessKeyID     = "access-key-id"
+	SecretSecretAccessKey = "secret-access-key"
+	SecretSessionToken    = "session-token"
+)
+
+const (
+	scheme        = "s3://"
+	defaultRegion = "us-east-1"
+)
+
+// Location is a location in an object storage given as s3://bucket/key
+type Location struct {
+	Bucket string
+	Key    string
+}
+
+// ParseLocation parses the given s3://bucket/key location. The key may be empty.
+func ParseLocation(s string) (Location, error) {
+	if !strings.HasPrefix(s, scheme) {
+		return Location{}, errors.Errorf("invalid location %s, it must be given as s3://bucket/prefix", s)
+	}
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(s, scheme), "/")
+	if bucket == "" {
+		return Location{}, errors.Errorf("invalid location %s, the bucket is missing", s)
+	}
+	return Location{Bucket: bucket, Key: strings.Trim(key, "/")}, nil
+}
+
+// Join returns the location of the given name under this location
+func (l Location) Join(name ...string) Location {
+	return Location{Bucket: l.Bucket, Key: strings.TrimPrefix(path.Join(append([]string{l.Key}, name...)...), "/")}
+}
+
+func (l Location) String() string {
+	return scheme + l.Bucket + "/" + l.Key
+}
+
+// Config is the configuration of the object storage
+type Config struct {
+	// Endpoint is the URL of the object storage; AWS S3 of the region is used if empty
+	Endpoint        string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// ConfigFromEnv returns the configuration of the object storage given by the environment variables
+func ConfigFromEnv() Config {
+	return Config{
+		Endpoint:        os.Getenv(EnvEndpoint),
+		Region:          os.Getenv(EnvRegion),
+		AccessKeyID:     os.Getenv(EnvAccessKeyID),
+		SecretAccessKey: os.Getenv(EnvSecretAccessKey),
+		SessionToken:    os.Getenv(EnvSessionToken),
+	}
+}
+
+// ConfigFromSecret returns the configuration of the object storage given by the data of a Kubernetes Secret
+func ConfigFromSecret(data map[string][]byte) Config {
+	return Config{
+		Endpoint:        string(data[SecretEndpoint]),
+		Region:          string(data[SecretRegion]),
+		AccessKeyID:     string(data[SecretAccessKeyID]),
+		SecretAccessKey: string(data[SecretSecretAccessKey]),
+		SessionToken:    string(data[SecretSessionToken]),
+	}
+}
+
+// Client uploads and downloads objects of an object storage, addressing them in path-style
+type Client struct {
+	core minio.Core
+}
+
+// NewClient returns a new Client for the given configuration
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("the credentials of the object storage are missing")
+	}
+	region := cfg.Region
+	if region == "" {
+		region = defaultRegion
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.Errorf("invalid endpoint %s of the object storage, it must be given as http(s)://host[:port]", endpoint)
+	}
+
+	secure := u.Scheme == "https"
+	transport, err := minio.DefaultTransport(secure)
+	if err != nil {
+		return nil, err
+	}
+	transport.ResponseHeaderTimeout = 30 * time.Second
+	client, err := minio.New(u.Host, &minio.Options{
+		Creds:        credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, cfg.SessionToken),
+		Secure:       secure,
+		Transport:    transport,
+		Region:       region,
+		BucketLookup: minio.BucketLookupPath,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create the client of the object storage")
+	}
+	return &Client{core: minio.Core{Client: client}}, nil
+}
+
+// Put uploads the given content of the given size to the given location
+func (c *Client) Put(location Location, body io.Reader, size int64) error {
+	_, err := c.core.Client.PutObject(context.Background(), location.Bucket, location.Key, body, size, minio.PutObjectOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "could not upload %s", location)
+	}
+	return nil
+}
+
+// Get downloads the object of the given location, starting at the given offset, to the given writer
+func (c *Client) Get(location Location, offset int64, w io.Writer) error {
+	opts := minio.GetObjectOptions{}
+	if offset > 0 {
+		if err := opts.SetRange(offset, 0); err != nil {
+			return err
+		}
+	}
+
+	object, _, header, err := c.core.GetObject(context.Background(), location.Bucket, location.Key, opts)
+	if err != nil {
+		return errors.Wrapf(err, "could not download %s", location)
+	}
+	defer func() { _ = object.Close() }()
+	if offset > 0 && header.Get("Content-Range") == "" {
+		return errors.Errorf("could not download %s from offset %d: range not supported", location, offset)
+	}
+
+	_, err = io.Copy(w, object)
+	return err
+}
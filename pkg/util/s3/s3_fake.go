@@ -0,0 +1,114 @@
+package s3
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// streamingPayload is the payload hash of the uploads sent in signed chunks, as done over plain HTTP
+const streamingPayload = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+
+// lastModified is the modification time of every object, which the clients require
+var lastModified = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// FakeServer is an in-memory stand-in of an S3-compatible object storage, addressed in path-style.
+// It only checks that the requests are signed,
+// without verifying the signatures.
+type FakeServer struct {
+	mutex   sync.Mutex
+	objects map[string][]byte
+}
+
+func NewFakeServer() *FakeServer {
+	return &FakeServer{objects: map[string][]byte{}}
+}
+
+// Object returns the content of the object of the given location
+func (s *FakeServer) Object(location Location) ([]byte, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	content, ok := s.objects[location.Bucket+"/"+location.Key]
+	return content, ok
+}
+
+// PutObject stores the given content at the given location
+func (s *FakeServer) PutObject(location Location, content []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.objects[location.Bucket+"/"+location.Key] = content
+}
+
+func (s *FakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=") {
+		writeError(w, "AccessDenied", http.StatusForbidden)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/")
+
+	switch r.Method {
+	case http.MethodPut:
+		content, err := readBody(r)
+		if err != nil {
+			writeError(w, "IncompleteBody", http.StatusBadRequest)
+			return
+		}
+		s.mutex.Lock()
+		s.objects[name] = content
+		s.mutex.Unlock()
+	case http.MethodGet:
+		s.mutex.Lock()
+		content, ok := s.objects[name]
+		s.mutex.Unlock()
+		if !ok {
+			writeError(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		http.ServeContent(w, r, "", lastModified, bytes.NewReader(content))
+	default:
+		writeError(w, "MethodNotAllowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// readBody returns the uploaded content, decoding it if sent in signed chunks
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Header.Get("X-Amz-Content-Sha256") != streamingPayload {
+		return io.ReadAll(r.Body)
+	}
+
+	var content []byte
+	reader := bufio.NewReader(r.Body)
+	for {
+		// each chunk is given as <size in hex>;chunk-signature=<signature>\r\n<data>\r\n, the last one being empty
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		hexSize, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(hexSize, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		chunk := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, chunk); err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return content, nil
+		}
+		content = append(content, chunk[:size]...)
+	}
+}
+
+// writeError writes the given error code as an object storage does
+func writeError(w http.ResponseWriter, code string, status int) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+}
@@ -0,0 +1,110 @@
+package s3
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLocation(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Location
+		wantErr string
+	}{
+		{
+			name: "bucket and prefix",
+			s:    "s3://bucket/profiling/results/",
+			want: Location{Bucket: "bucket", Key: "profiling/results"},
+		},
+		{
+			name: "only bucket",
+			s:    "s3://bucket",
+			want: Location{Bucket: "bucket"},
+		},
+		{
+			name:    "other scheme",
+			s:       "gs://bucket/prefix",
+			wantErr: "invalid location gs://bucket/prefix, it must be given as s3://bucket/prefix",
+		},
+		{
+			name:    "missing bucket",
+			s:       "s3:///prefix",
+			wantErr: "invalid location s3:///prefix, the bucket is missing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLocation(tt.s)
+
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLocation_Join(t *testing.T) {
+	assert.Equal(t, "s3://bucket/prefix/ID/flamegraph.svg.gz", Location{Bucket: "bucket", Key: "prefix"}.Join("ID", "flamegraph.svg.gz").String())
+	assert.Equal(t, "s3://bucket/ID/flamegraph.svg.gz", Location{Bucket: "bucket"}.Join("ID", "flamegraph.svg.gz").String())
+}
+
+func TestClient_PutAndGet(t *testing.T) {
+	fake := NewFakeServer()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	c, err := NewClient(Config{Endpoint: server.URL, AccessKeyID: "key", SecretAccessKey: "secret"})
+	require.NoError(t, err)
+	location := Location{Bucket: "bucket", Key: "prefix/ID/flame graph.svg.gz"}
+
+	err = c.Put(location, strings.NewReader("the content"), int64(len("the content")))
+	require.NoError(t, err)
+	content, ok := fake.Object(location)
+	assert.True(t, ok)
+	assert.Equal(t, "the content", string(content))
+
+	var out bytes.Buffer
+	err = c.Get(location, 4, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "content", out.String())
+
+	err = c.Get(Location{Bucket: "bucket", Key: "other"}, 0, &out)
+	require.EqualError(t, err, "could not download s3://bucket/other: NoSuchKey")
+
+	// uploaded in several signed chunks
+	large := strings.Repeat("the content ", 20000)
+	err = c.Put(location, strings.NewReader(large), int64(len(large)))
+	require.NoError(t, err)
+	content, _ = fake.Object(location)
+	assert.Equal(t, large, string(content))
+}
+
+func TestNewClient(t *testing.T) {
+	_, err := NewClient(Config{Endpoint: "http://localhost:9000"})
+	require.EqualError(t, err, "the credentials of the object storage are missing")
+
+	_, err = NewClient(Config{Endpoint: "minio:9000", AccessKeyID: "key", SecretAccessKey: "secret"})
+	require.EqualError(t, err, "invalid endpoint minio:9000 of the object storage, it must be given as http(s)://host[:port]")
+
+	c, err := NewClient(Config{Region: "eu-west-1", AccessKeyID: "key", SecretAccessKey: "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://s3.eu-west-1.amazonaws.com", c.core.EndpointURL().String())
+}
+
+func TestConfigFromSecret(t *testing.T) {
+	cfg := ConfigFromSecret(map[string][]byte{
+		SecretEndpoint:        []byte("http://minio:9000"),
+		SecretAccessKeyID:     []byte("key"),
+		SecretSecretAccessKey: []byte("secret"),
+	})
+
+	assert.Equal(t, Config{Endpoint: "http://minio:9000", AccessKeyID: "key", SecretAccessKey: "secret"}, cfg)
+}